@@ -48,6 +48,13 @@ func Init() error {
 	sigolo.Info("Registered routes for API %s:", version)
 	printRoutes(router_v2_9)
 
+	router_v2_10, version := Init_v2_10(router)
+	sigolo.Info("Registered routes for API %s:", version)
+	printRoutes(router_v2_10)
+
+	go startDueDateWatcher()
+	go startAttachmentCleanup()
+
 	router.Methods(http.MethodOptions).HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Headers", "Authorization")
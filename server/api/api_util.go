@@ -6,6 +6,7 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/hauke96/sigolo"
 	"github.com/pkg/errors"
+	"mime"
 	"net/http"
 	"runtime/debug"
 	"stm/oauth2"
@@ -46,6 +47,24 @@ func EmptyResponse() *ApiResponse {
 	}
 }
 
+// FileResponse returns the raw data as file download instead of encoding it as JSON.
+func FileResponse(data []byte, contentType string, fileName string) *ApiResponse {
+	return &ApiResponse{
+		statusCode: http.StatusOK,
+		data: &fileContent{
+			data:        data,
+			contentType: contentType,
+			fileName:    fileName,
+		},
+	}
+}
+
+type fileContent struct {
+	data        []byte
+	contentType string
+	fileName    string
+}
+
 func printRoutes(router *mux.Router) {
 	router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
 		path, _ := route.GetPathTemplate()
@@ -71,6 +90,14 @@ func authenticatedTransactionHandler(handler func(r *http.Request, context *Cont
 	}
 }
 
+func publicTransactionHandler(handler func(r *http.Request, context *Context) *ApiResponse) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		handlePublicRequest(w, r, handler)
+	}
+}
+
 func authenticatedWebsocket(handler func(w http.ResponseWriter, r *http.Request, token *oauth2.Token, websocketSender *websocket.Sender)) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		logger := util.NewLogger()
@@ -142,10 +169,7 @@ func handleRequest(w http.ResponseWriter, r *http.Request, handler func(r *http.
 		panic(response.data.(error))
 	}
 
-	if response.data != nil {
-		encoder := json.NewEncoder(w)
-		encoder.Encode(response.data)
-	}
+	writeResponseData(w, response.data)
 }
 
 // handleAuthenticatedRequest gets and verifies the token from the request, creates the context, starts a transaction, manages
@@ -176,6 +200,33 @@ func handleAuthenticatedRequest(w http.ResponseWriter, r *http.Request, handler
 
 	context.Log("Call from '%s' (%s) to %s %s", token.User, token.UID, r.Method, r.URL.Path)
 
+	handleTransaction(w, r, context, handler)
+}
+
+// handlePublicRequest creates a context without token, starts a transaction, manages commit/rollback, calls the
+// handler and also does error handling. This is used for endpoints that don't require a login, so the handler must not
+// access the token of the context.
+func handlePublicRequest(w http.ResponseWriter, r *http.Request, handler func(r *http.Request, context *Context) *ApiResponse) {
+	// temporary logger before there's a context
+	logger := util.NewLogger()
+
+	// Create context with a new transaction and new service instances
+	context, err := createContext(nil, logger)
+	if err != nil {
+		logger.Err("Unable to create context for public call to %s %s: %s", r.Method, r.URL.Path, err)
+		logger.Stack(err)
+		util.ResponseInternalError(w, logger, errors.New("Unable to create context"))
+		return
+	}
+
+	context.Log("Public call to %s %s", r.Method, r.URL.Path)
+
+	handleTransaction(w, r, context, handler)
+}
+
+// handleTransaction calls the handler with the given context and commits the transaction of the context afterwards. On
+// errors, a rollback is performed.
+func handleTransaction(w http.ResponseWriter, r *http.Request, context *Context, handler func(r *http.Request, context *Context) *ApiResponse) {
 	// Recover from panic and perform rollback on transaction
 	defer func() {
 		if r := recover(); r != nil {
@@ -196,7 +247,7 @@ func handleAuthenticatedRequest(w http.ResponseWriter, r *http.Request, handler
 			context.Log("Try to perform rollback")
 			rollbackErr := context.Transaction.Rollback()
 			if rollbackErr != nil {
-				logger.Stack(errors.Wrap(rollbackErr, "error performing rollback"))
+				context.Stack(errors.Wrap(rollbackErr, "error performing rollback"))
 			}
 		}
 	}()
@@ -211,15 +262,30 @@ func handleAuthenticatedRequest(w http.ResponseWriter, r *http.Request, handler
 	}
 
 	// Commit transaction
-	err = context.Transaction.Commit()
+	err := context.Transaction.Commit()
 	if err != nil {
 		context.Err("Unable to commit transaction: %s", err.Error())
 		panic(err)
 	}
 	context.Debug("Committed transaction")
 
-	if response.data != nil {
-		encoder := json.NewEncoder(w)
-		encoder.Encode(response.data)
+	writeResponseData(w, response.data)
+}
+
+func writeResponseData(w http.ResponseWriter, data interface{}) {
+	if data == nil {
+		return
 	}
+
+	if file, ok := data.(*fileContent); ok {
+		w.Header().Set("Content-Type", file.contentType)
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": file.fileName}))
+		// Prevent browsers from interpreting the file as something else (e.g. as HTML)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Write(file.data)
+		return
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(data)
 }
@@ -0,0 +1,1896 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"io"
+	"net/http"
+	"stm/campaign"
+	"stm/comment"
+	"stm/config"
+	"stm/export"
+	"stm/invitation"
+	"stm/leaderboard"
+	"stm/project"
+	"stm/team"
+	"stm/util"
+	"stm/websocket"
+	"strings"
+	"time"
+)
+
+func Init_v2_10(router *mux.Router) (*mux.Router, string) {
+	r := router.PathPrefix("/v2.10").Subrouter()
+
+	r.HandleFunc("/config", simpleHandler(getConfig_v2_9)).Methods(http.MethodGet)
+	r.HandleFunc("/public/projects/{id}", publicTransactionHandler(getPublicProject_v2_10)).Methods(http.MethodGet)
+
+	// Registered before the "/projects/{id}/..." routes so that "join" and "summaries" are never interpreted as project IDs.
+	r.HandleFunc("/projects/join/{code}", authenticatedTransactionHandler(joinProject_v2_10)).Methods(http.MethodPost)
+
+	r.HandleFunc("/projects", authenticatedTransactionHandler(getProjects_v2_9)).Methods(http.MethodGet)
+	r.HandleFunc("/projects/summaries", authenticatedTransactionHandler(getProjectSummaries_v2_10)).Methods(http.MethodGet)
+	r.HandleFunc("/projects", authenticatedTransactionHandler(addProject_v2_9)).Methods(http.MethodPost)
+	r.HandleFunc("/projects/{id}", authenticatedTransactionHandler(getProject_v2_10)).Methods(http.MethodGet)
+	r.HandleFunc("/projects/{id}", authenticatedTransactionHandler(deleteProjects_v2_9)).Methods(http.MethodDelete)
+	r.HandleFunc("/projects/{id}", authenticatedTransactionHandler(updateProject_v2_10)).Methods(http.MethodPut)
+	r.HandleFunc("/projects/{id}/visibility", authenticatedTransactionHandler(updateProjectVisibility_v2_10)).Methods(http.MethodPut)
+	r.HandleFunc("/projects/{id}/josm", authenticatedTransactionHandler(updateProjectJosmConfig_v2_10)).Methods(http.MethodPut)
+	r.HandleFunc("/projects/{id}/assignment", authenticatedTransactionHandler(updateProjectAssignmentPolicy_v2_10)).Methods(http.MethodPut)
+	r.HandleFunc("/projects/{id}/export", authenticatedTransactionHandler(exportProject_v2_10)).Methods(http.MethodGet)
+	r.HandleFunc("/projects/{id}/stats", authenticatedTransactionHandler(getProjectStatistics_v2_10)).Methods(http.MethodGet)
+	r.HandleFunc("/projects/{id}/progress", authenticatedTransactionHandler(getProjectProgress_v2_10)).Methods(http.MethodGet)
+	r.HandleFunc("/projects/import", authenticatedTransactionHandler(importProject_v2_9)).Methods(http.MethodPost)
+	r.HandleFunc("/projects/{id}/users", authenticatedTransactionHandler(addUserToProject_v2_9)).Methods(http.MethodPost)
+	r.HandleFunc("/projects/{id}/users", authenticatedTransactionHandler(leaveProject_v2_9)).Methods(http.MethodDelete)
+	r.HandleFunc("/projects/{id}/users/{uid}", authenticatedTransactionHandler(removeUser_v2_9)).Methods(http.MethodDelete)
+	r.HandleFunc("/projects/{id}/comments", authenticatedTransactionHandler(getProjectComments_v2_10)).Methods(http.MethodGet)
+	r.HandleFunc("/projects/{id}/comments", authenticatedTransactionHandler(addProjectComment_v2_10)).Methods(http.MethodPost)
+	r.HandleFunc("/projects/{id}/invitations", authenticatedTransactionHandler(getInvitations_v2_10)).Methods(http.MethodGet)
+	r.HandleFunc("/projects/{id}/invitations", authenticatedTransactionHandler(addInvitation_v2_10)).Methods(http.MethodPost)
+	r.HandleFunc("/projects/{id}/invitations/{iid}", authenticatedTransactionHandler(deleteInvitation_v2_10)).Methods(http.MethodDelete)
+	r.HandleFunc("/projects/{id}/teams/{tid}", authenticatedTransactionHandler(addTeamToProject_v2_10)).Methods(http.MethodPost)
+	r.HandleFunc("/projects/{id}/teams/{tid}", authenticatedTransactionHandler(removeTeamFromProject_v2_10)).Methods(http.MethodDelete)
+	r.HandleFunc("/projects/{id}/reports", authenticatedTransactionHandler(getCommentReports_v2_10)).Methods(http.MethodGet)
+	r.HandleFunc("/projects/{id}/read-only-users/{uid}", authenticatedTransactionHandler(addReadOnlyUser_v2_10)).Methods(http.MethodPost)
+	r.HandleFunc("/projects/{id}/read-only-users/{uid}", authenticatedTransactionHandler(removeReadOnlyUser_v2_10)).Methods(http.MethodDelete)
+
+	r.HandleFunc("/tasks/{id}", authenticatedTransactionHandler(getTask_v2_10)).Methods(http.MethodGet)
+	r.HandleFunc("/tasks/{id}/editor-links", authenticatedTransactionHandler(getEditorLinks_v2_10)).Methods(http.MethodGet)
+	r.HandleFunc("/tasks/{id}/assignedUser", authenticatedTransactionHandler(assignUser_v2_9)).Methods(http.MethodPost)
+	r.HandleFunc("/tasks/{id}/assignedUser", authenticatedTransactionHandler(unassignUser_v2_9)).Methods(http.MethodDelete)
+	r.HandleFunc("/tasks/{id}/processPoints", authenticatedTransactionHandler(setProcessPoints_v2_9)).Methods(http.MethodPost)
+	r.HandleFunc("/tasks/{id}/comments", authenticatedTransactionHandler(getTaskComments_v2_10)).Methods(http.MethodGet)
+	r.HandleFunc("/tasks/{id}/comments", authenticatedTransactionHandler(addTaskComment_v2_10)).Methods(http.MethodPost)
+
+	r.HandleFunc("/comments/{id}", authenticatedTransactionHandler(updateComment_v2_10)).Methods(http.MethodPut)
+	r.HandleFunc("/comments/{id}", authenticatedTransactionHandler(deleteComment_v2_10)).Methods(http.MethodDelete)
+	r.HandleFunc("/comments/{id}/history", authenticatedTransactionHandler(getCommentHistory_v2_10)).Methods(http.MethodGet)
+	r.HandleFunc("/comments/{id}/issue", authenticatedTransactionHandler(setCommentIssueStatus_v2_10)).Methods(http.MethodPut)
+	r.HandleFunc("/comments/{id}/attachments", authenticatedTransactionHandler(addCommentAttachment_v2_10)).Methods(http.MethodPost)
+	r.HandleFunc("/comments/{id}/reports", authenticatedTransactionHandler(reportComment_v2_10)).Methods(http.MethodPost)
+	r.HandleFunc("/comments/{id}/reports", authenticatedTransactionHandler(dismissCommentReports_v2_10)).Methods(http.MethodDelete)
+	r.HandleFunc("/comments/{id}/hidden", authenticatedTransactionHandler(setCommentHidden_v2_10)).Methods(http.MethodPut)
+	r.HandleFunc("/attachments/{id}", authenticatedTransactionHandler(getAttachment_v2_10)).Methods(http.MethodGet)
+
+	r.HandleFunc("/campaigns", authenticatedTransactionHandler(getCampaigns_v2_10)).Methods(http.MethodGet)
+	r.HandleFunc("/campaigns", authenticatedTransactionHandler(addCampaign_v2_10)).Methods(http.MethodPost)
+	r.HandleFunc("/campaigns/{id}", authenticatedTransactionHandler(getCampaign_v2_10)).Methods(http.MethodGet)
+	r.HandleFunc("/campaigns/{id}", authenticatedTransactionHandler(deleteCampaign_v2_10)).Methods(http.MethodDelete)
+	r.HandleFunc("/campaigns/{id}/projects/{pid}", authenticatedTransactionHandler(addProjectToCampaign_v2_10)).Methods(http.MethodPost)
+	r.HandleFunc("/campaigns/{id}/projects/{pid}", authenticatedTransactionHandler(removeProjectFromCampaign_v2_10)).Methods(http.MethodDelete)
+	r.HandleFunc("/campaigns/{id}/users", authenticatedTransactionHandler(addUserToCampaign_v2_10)).Methods(http.MethodPost)
+	r.HandleFunc("/campaigns/{id}/users/{uid}", authenticatedTransactionHandler(removeUserFromCampaign_v2_10)).Methods(http.MethodDelete)
+
+	r.HandleFunc("/teams", authenticatedTransactionHandler(getTeams_v2_10)).Methods(http.MethodGet)
+	r.HandleFunc("/teams", authenticatedTransactionHandler(addTeam_v2_10)).Methods(http.MethodPost)
+	r.HandleFunc("/teams/{id}", authenticatedTransactionHandler(getTeam_v2_10)).Methods(http.MethodGet)
+	r.HandleFunc("/teams/{id}", authenticatedTransactionHandler(deleteTeam_v2_10)).Methods(http.MethodDelete)
+	r.HandleFunc("/teams/{id}/users", authenticatedTransactionHandler(addUserToTeam_v2_10)).Methods(http.MethodPost)
+	r.HandleFunc("/teams/{id}/users/{uid}", authenticatedTransactionHandler(removeUserFromTeam_v2_10)).Methods(http.MethodDelete)
+	r.HandleFunc("/teams/{id}/admins/{uid}", authenticatedTransactionHandler(addAdminToTeam_v2_10)).Methods(http.MethodPost)
+
+	r.HandleFunc("/leaderboard", authenticatedTransactionHandler(getLeaderboard_v2_10)).Methods(http.MethodGet)
+
+	r.HandleFunc("/updates", authenticatedWebsocket(getWebsocketConnection_v2_9))
+
+	return r, "v2.10"
+}
+
+// Get invitations
+// @Summary Get all invitations of a project.
+// @Description Gets all invitations (including their secret codes) of the project. The requesting user must be the owner of the project.
+// @Version 2.10
+// @Tags invitations
+// @Produce json
+// @Param id path string true "ID of the project"
+// @Success 200 {object} []invitation.Invitation
+// @Router /v2.10/projects/{id}/invitations [GET]
+func getInvitations_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	projectId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	invitations, err := context.InvitationService.GetInvitations(projectId, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	context.Log("Successfully got invitations of project %s", projectId)
+
+	return JsonResponse(invitations)
+}
+
+// Add invitation
+// @Summary Creates a new invitation for a project.
+// @Description Creates a new invitation with a secret code, which can be used by any authenticated user to join the project. An expiration date and a maximum number of uses are optional. The requesting user must be the owner of the project.
+// @Version 2.10
+// @Tags invitations
+// @Produce json
+// @Param id path string true "ID of the project"
+// @Param invitation body invitation.DraftDto true "Draft of the invitation"
+// @Success 200 {object} invitation.Invitation
+// @Router /v2.10/projects/{id}/invitations [POST]
+func addInvitation_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	projectId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	bodyBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		return BadRequestError(errors.Wrap(err, "error reading request body"))
+	}
+
+	var dto invitation.DraftDto
+	err = json.Unmarshal(bodyBytes, &dto)
+	if err != nil {
+		return BadRequestError(errors.Wrap(err, "error unmarshalling invitation draft"))
+	}
+
+	addedInvitation, err := context.InvitationService.AddInvitation(projectId, &dto, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	context.Log("Successfully added invitation %s to project %s", addedInvitation.Id, projectId)
+
+	return JsonResponse(addedInvitation)
+}
+
+// Delete invitation
+// @Summary Revokes an invitation.
+// @Description Deletes the invitation so that its code cannot be used anymore. The requesting user must be the owner of the project.
+// @Version 2.10
+// @Tags invitations
+// @Param id path string true "ID of the project"
+// @Param iid path string true "ID of the invitation"
+// @Router /v2.10/projects/{id}/invitations/{iid} [DELETE]
+func deleteInvitation_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	projectId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	invitationId, ok := vars["iid"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'iid' not set"))
+	}
+
+	err := context.InvitationService.DeleteInvitation(projectId, invitationId, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	context.Log("Successfully deleted invitation %s of project %s", invitationId, projectId)
+
+	return EmptyResponse()
+}
+
+// Join project
+// @Summary Joins a project by using an invitation code.
+// @Description Adds the requesting user to the project the invitation belongs to. The invitation must neither be expired nor used up.
+// @Version 2.10
+// @Tags invitations
+// @Produce json
+// @Param code path string true "The secret code of the invitation"
+// @Success 200 {object} project.Project
+// @Router /v2.10/projects/join/{code} [POST]
+func joinProject_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	code, ok := vars["code"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'code' not set"))
+	}
+
+	joinedProject, err := context.InvitationService.RedeemInvitation(code, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	sendUpdate_v2_9(context.WebsocketSender, joinedProject)
+
+	context.Log("Successfully joined project %s with invitation", joinedProject.Id)
+
+	return JsonResponse(joinedProject)
+}
+
+// Get public project
+// @Summary Get the public read-only view of a project.
+// @Description Gets a sanitised view of the project containing geometries, progress and aggregated numbers. User-IDs and comments are only included when the owner allows it. This endpoint doesn't need authentication but only works for projects the owner made public.
+// @Version 2.10
+// @Tags public
+// @Produce json
+// @Param id path string true "ID of the project"
+// @Success 200 {object} project.PublicProject
+// @Router /v2.10/public/projects/{id} [GET]
+func getPublicProject_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	projectId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	publicProject, err := context.ProjectService.GetPublicProject(projectId)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	context.Log("Successfully got public project %s", projectId)
+
+	return JsonResponse(publicProject)
+}
+
+// Update project visibility
+// @Summary Update the public visibility of a project.
+// @Description Sets whether a read-only view of the project is available without login and whether this view contains user-IDs and comments. The requesting user must be the owner of the project.
+// @Version 2.10
+// @Tags projects
+// @Produce json
+// @Param id path string true "ID of the project"
+// @Param visibility body project.VisibilityDto true "The new visibility settings"
+// @Success 200 {object} project.Project
+// @Router /v2.10/projects/{id}/visibility [PUT]
+func updateProjectVisibility_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	projectId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	bodyBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		return BadRequestError(errors.Wrap(err, "error reading request body"))
+	}
+
+	var dto project.VisibilityDto
+	err = json.Unmarshal(bodyBytes, &dto)
+	if err != nil {
+		return BadRequestError(errors.Wrap(err, "error unmarshalling project visibility"))
+	}
+
+	updatedProject, err := context.ProjectService.UpdateVisibility(projectId, &dto, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	sendUpdate_v2_9(context.WebsocketSender, updatedProject)
+
+	context.Log("Successfully updated visibility of project %s", projectId)
+
+	return JsonResponse(updatedProject)
+}
+
+// Update project JOSM configuration
+// @Summary Update the JOSM data source and imagery of a project.
+// @Description Sets where JOSM loads the data from (OSM, Overpass with optional query template or a custom download URL) and which imagery layers it loads. The requesting user must be the owner of the project.
+// @Version 2.10
+// @Tags projects
+// @Produce json
+// @Param id path string true "ID of the project"
+// @Param josmConfig body project.JosmConfigDto true "The new JOSM configuration"
+// @Success 200 {object} project.Project
+// @Router /v2.10/projects/{id}/josm [PUT]
+func updateProjectJosmConfig_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	projectId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	bodyBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		return BadRequestError(errors.Wrap(err, "error reading request body"))
+	}
+
+	var dto project.JosmConfigDto
+	err = json.Unmarshal(bodyBytes, &dto)
+	if err != nil {
+		return BadRequestError(errors.Wrap(err, "error unmarshalling project JOSM configuration"))
+	}
+
+	updatedProject, err := context.ProjectService.UpdateJosmConfig(projectId, &dto, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	sendUpdate_v2_9(context.WebsocketSender, updatedProject)
+
+	context.Log("Successfully updated JOSM configuration of project %s", projectId)
+
+	return JsonResponse(updatedProject)
+}
+
+// Update project assignment policy
+// @Summary Update the assignment policy of a project.
+// @Description Sets whether users have to be assigned to a task before setting its process points (always, never or only above a number of members) and how many tasks a user can be assigned to at the same time. The requesting user must be the owner of the project.
+// @Version 2.10
+// @Tags projects
+// @Produce json
+// @Param id path string true "ID of the project"
+// @Param assignment body project.AssignmentDto true "The new assignment policy"
+// @Success 200 {object} project.Project
+// @Router /v2.10/projects/{id}/assignment [PUT]
+func updateProjectAssignmentPolicy_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	projectId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	bodyBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		return BadRequestError(errors.Wrap(err, "error reading request body"))
+	}
+
+	var dto project.AssignmentDto
+	err = json.Unmarshal(bodyBytes, &dto)
+	if err != nil {
+		return BadRequestError(errors.Wrap(err, "error unmarshalling project assignment policy"))
+	}
+
+	updatedProject, err := context.ProjectService.UpdateAssignmentPolicy(projectId, &dto, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	sendUpdate_v2_9(context.WebsocketSender, updatedProject)
+
+	context.Log("Successfully updated assignment policy of project %s", projectId)
+
+	return JsonResponse(updatedProject)
+}
+
+// Get project summaries
+// @Summary Get lightweight summaries of the projects of the requesting user.
+// @Description Gets one page of project summaries of the requesting user. In contrast to the normal project list, the summaries don't contain tasks and comments but only aggregated numbers.
+// @Version 2.10
+// @Tags projects
+// @Produce json
+// @Param page query int false "Number of the page, starting at 1. Default: 1" minimum(1)
+// @Param pageSize query int false "Maximum number of summaries per page. Default: 20" minimum(1) maximum(100)
+// @Param sort query string false "Field to sort by. Default: creationDate" Enums(creationDate, name, progress)
+// @Param order query string false "Sort order. Default: asc" Enums(asc, desc)
+// @Param search query string false "Only return projects containing this text in their name (case-insensitive)"
+// @Success 200 {object} project.SummaryPage
+// @Router /v2.10/projects/summaries [GET]
+func getProjectSummaries_v2_10(r *http.Request, context *Context) *ApiResponse {
+	page, err := util.GetIntParamOrDefault("page", r, 1)
+	if err != nil {
+		return BadRequestError(errors.Wrap(err, "url param 'page' is not a number"))
+	}
+
+	pageSize, err := util.GetIntParamOrDefault("pageSize", r, project.DefaultSummaryPageSize)
+	if err != nil {
+		return BadRequestError(errors.Wrap(err, "url param 'pageSize' is not a number"))
+	}
+
+	sortBy := project.SummarySortField(r.FormValue("sort"))
+	if sortBy == "" {
+		sortBy = project.SortByCreationDate
+	}
+
+	order := r.FormValue("order")
+	if order != "" && order != "asc" && order != "desc" {
+		return BadRequestError(errors.New(fmt.Sprintf("url param 'order' must be 'asc' or 'desc' but was '%s'", order)))
+	}
+
+	filter := &project.SummaryFilterDto{
+		Page:       page,
+		PageSize:   pageSize,
+		SortBy:     sortBy,
+		Descending: order == "desc",
+		Search:     r.FormValue("search"),
+	}
+
+	summaryPage, err := context.ProjectService.GetProjectSummaries(context.Token.UID, filter)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	context.Log("Successfully got %d project summaries", len(summaryPage.Summaries))
+
+	return JsonResponse(summaryPage)
+}
+
+// Export project
+// @Summary Get a JSON or GeoJSON representation of the project.
+// @Description The format "stm" (default) aims to transfer a project to another STM instance or to simply create a backup of a project. The format "geojson" returns a FeatureCollection of the tasks for GIS applications, each feature has the properties "id", "name", "processPoints", "maxProcessPoints", "completion" (between 0 and 1) and "assignedUser". The requesting user must be a member of the project, only exports of the owner contain the content of hidden comments.
+// @Version 2.10
+// @Tags projects
+// @Produce json
+// @Param id path string true "ID of the project"
+// @Param format query string false "Either 'stm' or 'geojson'. Default: 'stm'"
+// @Success 200 {object} export.ProjectExport
+// @Router /v2.10/projects/{id}/export [GET]
+func exportProject_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	projectId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	format := export.Format(r.FormValue("format"))
+	switch format {
+	case "", export.FormatStm:
+		projectExport, err := context.ExportService.ExportProject(projectId, context.Token.UID)
+		if err != nil {
+			return InternalServerError(err)
+		}
+
+		return JsonResponse(projectExport)
+	case export.FormatGeoJson:
+		featureCollection, err := context.ExportService.ExportProjectAsGeoJson(projectId, context.Token.UID)
+		if err != nil {
+			return InternalServerError(err)
+		}
+
+		context.Log("Successfully exported %d tasks of project %s as GeoJSON", len(featureCollection.Features), projectId)
+
+		return JsonResponse(featureCollection)
+	default:
+		return BadRequestError(errors.New(fmt.Sprintf("unknown export format '%s'", format)))
+	}
+}
+
+// Get project statistics
+// @Summary Get statistics and contributions of a project.
+// @Description Gets the progress of the project (task counts, mapped area, first and last activity) and the contributions of each user. The requesting user must be a member of the project.
+// @Version 2.10
+// @Tags projects
+// @Produce json
+// @Param id path string true "ID of the project"
+// @Success 200 {object} project.Statistics
+// @Router /v2.10/projects/{id}/stats [GET]
+func getProjectStatistics_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	projectId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	statistics, err := context.ProjectService.GetStatistics(projectId, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	context.Log("Successfully got statistics of project %s", projectId)
+
+	return JsonResponse(statistics)
+}
+
+// Get project progress
+// @Summary Get the progress of a project over time.
+// @Description Gets the done and total process points of the project as time series together with the current velocity and an estimated completion date. The requesting user must be a member of the project.
+// @Version 2.10
+// @Tags projects
+// @Produce json
+// @Param id path string true "ID of the project"
+// @Param from query string false "Start of the time series in RFC 3339 format. Default: creation date of the project, but at most as many intervals before the end date as one time series may contain"
+// @Param to query string false "End of the time series in RFC 3339 format. Default: now"
+// @Param interval query string false "Time between two points of the time series. Default: day" Enums(hour, day, week)
+// @Success 200 {object} project.Progress
+// @Router /v2.10/projects/{id}/progress [GET]
+func getProjectProgress_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	projectId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	filter := &project.ProgressFilterDto{
+		Interval: project.ProgressInterval(r.FormValue("interval")),
+	}
+	if filter.Interval == "" {
+		filter.Interval = project.IntervalDay
+	}
+
+	var err error
+	filter.From, err = getOptionalDateParam("from", r)
+	if err != nil {
+		return BadRequestError(err)
+	}
+
+	filter.To, err = getOptionalDateParam("to", r)
+	if err != nil {
+		return BadRequestError(err)
+	}
+
+	progress, err := context.ProjectService.GetProgress(projectId, filter, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	context.Log("Successfully got progress of project %s with %d points", projectId, len(progress.Points))
+
+	return JsonResponse(progress)
+}
+
+// Get leaderboard
+// @Summary Get a ranking of users by their contributions.
+// @Description Ranks all users by the tasks they finished or the process points they set within a time window. Only projects the requesting user is a member of are considered.
+// @Version 2.10
+// @Tags leaderboard
+// @Produce json
+// @Param from query string false "Start of the time window in RFC 3339 format. Default: 30 days before the end"
+// @Param to query string false "End of the time window in RFC 3339 format. Default: now"
+// @Param projects query string false "Comma separated list of project IDs. Default: all projects of the requesting user"
+// @Param sortBy query string false "Value to rank the users by. Default: tasks" Enums(tasks, points)
+// @Param limit query int false "Maximum number of entries. Default: 10" minimum(1) maximum(100)
+// @Success 200 {object} leaderboard.Leaderboard
+// @Router /v2.10/leaderboard [GET]
+func getLeaderboard_v2_10(r *http.Request, context *Context) *ApiResponse {
+	limit, err := util.GetIntParamOrDefault("limit", r, leaderboard.DefaultLimit)
+	if err != nil {
+		return BadRequestError(errors.Wrap(err, "url param 'limit' is not a number"))
+	}
+
+	filter := &leaderboard.FilterDto{
+		ProjectIds: make([]string, 0),
+		SortBy:     leaderboard.SortField(r.FormValue("sortBy")),
+		Limit:      limit,
+	}
+	if filter.SortBy == "" {
+		filter.SortBy = leaderboard.SortByTasks
+	}
+
+	for _, projectId := range strings.Split(r.FormValue("projects"), ",") {
+		if strings.TrimSpace(projectId) != "" {
+			filter.ProjectIds = append(filter.ProjectIds, strings.TrimSpace(projectId))
+		}
+	}
+
+	filter.From, err = getOptionalDateParam("from", r)
+	if err != nil {
+		return BadRequestError(err)
+	}
+
+	filter.To, err = getOptionalDateParam("to", r)
+	if err != nil {
+		return BadRequestError(err)
+	}
+
+	result, err := context.LeaderboardService.GetLeaderboard(filter, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	context.Log("Successfully got leaderboard with %d entries", len(result.Entries))
+
+	return JsonResponse(result)
+}
+
+// getOptionalDateParam returns NULL when the parameter is not set. Dates not in RFC 3339 format are an error.
+func getOptionalDateParam(param string, r *http.Request) (*time.Time, error) {
+	value := r.FormValue(param)
+	if value == "" {
+		return nil, nil
+	}
+
+	date, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("url param '%s' is not a valid RFC 3339 date", param))
+	}
+
+	return &date, nil
+}
+
+// Update project
+// @Summary Update a project.
+// @Description Updates name, description, JOSM data source as well as start and due date of the project. The requesting user must be the owner of the project.
+// @Version 2.10
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param id path string true "ID of the project"
+// @Param project body project.UpdateDto true "The new values of the project. Omitted start and due dates stay unchanged, null removes them. Omitted changeset settings stay unchanged as well."
+// @Success 200 {object} project.Project
+// @Router /v2.10/projects/{id} [PUT]
+func updateProject_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	projectId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	bodyBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		return BadRequestError(errors.Wrap(err, "error reading request body"))
+	}
+
+	var dto project.UpdateDto
+	err = json.Unmarshal(bodyBytes, &dto)
+	if err != nil {
+		return BadRequestError(errors.Wrap(err, "error unmarshalling project update"))
+	}
+
+	// Used to distinguish omitted fields from fields explicitly set to null
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(bodyBytes, &fields)
+	if err != nil {
+		return BadRequestError(errors.Wrap(err, "error unmarshalling project update"))
+	}
+
+	// The changeset settings are only changed when at least one of them is given, omitted ones keep their value
+	var changeset *project.ChangesetDto
+	_, hasComment := fields["changesetComment"]
+	_, hasSource := fields["changesetSource"]
+	_, hasHashtags := fields["changesetHashtags"]
+	if hasComment || hasSource || hasHashtags {
+		currentProject, err := context.ProjectService.GetProject(projectId, context.Token.UID)
+		if err != nil {
+			return InternalServerError(err)
+		}
+
+		changeset = &dto.ChangesetDto
+		if !hasComment {
+			changeset.ChangesetComment = currentProject.ChangesetComment
+		}
+		if !hasSource {
+			changeset.ChangesetSource = currentProject.ChangesetSource
+		}
+		if !hasHashtags {
+			changeset.ChangesetHashtags = currentProject.ChangesetHashtags
+		}
+	}
+
+	updatedProject, err := context.ProjectService.Update(projectId, dto.Name, dto.Description, dto.JosmDataSource, changeset, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	// Omitted dates keep their current value, only an explicit null removes a date
+	startDate := dto.StartDate
+	if _, ok := fields["startDate"]; !ok {
+		startDate = updatedProject.StartDate
+	}
+	dueDate := dto.DueDate
+	if _, ok := fields["dueDate"]; !ok {
+		dueDate = updatedProject.DueDate
+	}
+
+	updatedProject, err = context.ProjectService.UpdateSchedule(projectId, startDate, dueDate, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	sendUpdate_v2_9(context.WebsocketSender, updatedProject)
+
+	context.Log("Successfully updated project %s", projectId)
+
+	return JsonResponse(updatedProject)
+}
+
+// Get editor links
+// @Summary Gets the URLs to edit a task in JOSM and iD.
+// @Description Gets the JOSM remote-control URLs (task polygon, data and imagery) and the iD URL with changeset comment and hashtags. They are computed from the task geometry and the JOSM configuration of the project. The requesting user must be a member of the project.
+// @Version 2.10
+// @Tags tasks
+// @Produce json
+// @Param id path string true "The ID of the task"
+// @Success 200 {object} project.EditorLinks
+// @Router /v2.10/tasks/{id}/editor-links [GET]
+func getEditorLinks_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	taskId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	links, err := context.ProjectService.GetEditorLinks(taskId, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	context.Log("Successfully got editor links of task %s", taskId)
+
+	return JsonResponse(links)
+}
+
+// Get project
+// @Summary Get a specific project.
+// @Description Gets the project with its tasks. The embedded comments can be limited to the latest threads of the project and of each task, the complete comments are available via the comment endpoints. The requesting user must be a member of the project.
+// @Version 2.10
+// @Tags projects
+// @Produce json
+// @Param id path string true "ID of the project"
+// @Param maxComments query int false "Maximum number of the latest comment threads embedded in the project and each task. 0 omits all comments. Default: all comments"
+// @Success 200 {object} project.Project
+// @Router /v2.10/projects/{id} [GET]
+func getProject_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	projectId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	maxComments, err := util.GetIntParamOrDefault("maxComments", r, -1)
+	if err != nil {
+		return BadRequestError(errors.Wrap(err, "url param 'maxComments' is not a number"))
+	}
+
+	p, err := context.ProjectService.GetProjectWithLatestComments(projectId, context.Token.UID, maxComments)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	context.Log("Successfully got project %s", projectId)
+
+	return JsonResponse(p)
+}
+
+// Get project comments
+// @Summary Get the comments of a project.
+// @Description Gets one page of the comment threads of the project, the oldest first. The requesting user must be a member of the project.
+// @Version 2.10
+// @Tags comments
+// @Produce json
+// @Param id path string true "ID of the project"
+// @Param cursor query string false "The 'nextCursor' of the previous page. Default: first page"
+// @Param pageSize query int false "Maximum number of threads per page. Default: 50, maximum: 100"
+// @Param since query string false "RFC 3339 date. When set, only threads with comments created or edited after this date are returned"
+// @Success 200 {object} comment.Page
+// @Router /v2.10/projects/{id}/comments [GET]
+func getProjectComments_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	projectId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	filter, err := getCommentPageFilter(r)
+	if err != nil {
+		return BadRequestError(err)
+	}
+
+	page, err := context.ProjectService.GetComments(projectId, filter, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	context.Log("Successfully got %d comment threads of project %s", len(page.Comments), projectId)
+
+	return JsonResponse(page)
+}
+
+// Get task
+// @Summary Get a specific task.
+// @Description Gets the task. The embedded comments can be limited to the latest threads, the complete comments are available via the comment endpoint. The requesting user must be a member of the project.
+// @Version 2.10
+// @Tags tasks
+// @Produce json
+// @Param id path string true "The ID of the task"
+// @Param maxComments query int false "Maximum number of the latest comment threads embedded in the task. 0 omits all comments. Default: all comments"
+// @Success 200 {object} task.Task
+// @Router /v2.10/tasks/{id} [GET]
+func getTask_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	taskId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	maxComments, err := util.GetIntParamOrDefault("maxComments", r, -1)
+	if err != nil {
+		return BadRequestError(errors.Wrap(err, "url param 'maxComments' is not a number"))
+	}
+
+	t, err := context.TaskService.GetTaskOfMember(taskId, context.Token.UID, maxComments)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	context.Log("Successfully got task %s", taskId)
+
+	return JsonResponse(*t)
+}
+
+// Get task comments
+// @Summary Get the comments of a task.
+// @Description Gets one page of the comment threads of the task, the oldest first. The requesting user must be a member of the project.
+// @Version 2.10
+// @Tags comments
+// @Produce json
+// @Param id path string true "The ID of the task"
+// @Param cursor query string false "The 'nextCursor' of the previous page. Default: first page"
+// @Param pageSize query int false "Maximum number of threads per page. Default: 50, maximum: 100"
+// @Param since query string false "RFC 3339 date. When set, only threads with comments created or edited after this date are returned"
+// @Success 200 {object} comment.Page
+// @Router /v2.10/tasks/{id}/comments [GET]
+func getTaskComments_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	taskId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	filter, err := getCommentPageFilter(r)
+	if err != nil {
+		return BadRequestError(err)
+	}
+
+	page, err := context.TaskService.GetComments(taskId, filter, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	context.Log("Successfully got %d comment threads of task %s", len(page.Comments), taskId)
+
+	return JsonResponse(page)
+}
+
+func getCommentPageFilter(r *http.Request) (*comment.PageFilterDto, error) {
+	pageSize, err := util.GetIntParamOrDefault("pageSize", r, comment.DefaultPageSize)
+	if err != nil {
+		return nil, errors.Wrap(err, "url param 'pageSize' is not a number")
+	}
+
+	since, err := getOptionalDateParam("since", r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &comment.PageFilterDto{
+		Cursor:   r.FormValue("cursor"),
+		PageSize: pageSize,
+		Since:    since,
+	}, nil
+}
+
+// Add project comment
+// @Summary Adds a new comment to the project.
+// @Description The number of maximum characters is restricted by the server config. Mentions of users who aren't members of the project are ignored and listed in the warnings of the returned comment. The requesting user must be a member of the project and not in read-only mode.
+// @Version 2.10
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path string true "The ID of the project"
+// @Param comment body comment.DraftDto true "The new comment"
+// @Success 200 {object} project.CommentResultDto
+// @Router /v2.10/projects/{id}/comments [POST]
+func addProjectComment_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	projectId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	bodyBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		return BadRequestError(errors.Wrap(err, "error reading request body"))
+	}
+
+	var dto comment.DraftDto
+	err = json.Unmarshal(bodyBytes, &dto)
+	if err != nil {
+		return BadRequestError(errors.Wrap(err, "error unmarshalling comment draft"))
+	}
+
+	addedComment, err := context.ProjectService.AddComment(projectId, &dto, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	projectWithComment, err := context.ProjectService.GetProject(projectId, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	sendUpdate_v2_9(context.WebsocketSender, projectWithComment)
+	sendMention_v2_10(context.WebsocketSender, projectWithComment, addedComment)
+
+	context.Log("Successfully added comment %s to project %s with warnings %v", addedComment.Id, projectId, addedComment.Warnings)
+
+	return JsonResponse(&project.CommentResultDto{
+		Project: projectWithComment,
+		Comment: addedComment,
+	})
+}
+
+// Add task comment
+// @Summary Adds a new comment to the task.
+// @Description The number of maximum characters is restricted by the server config. Mentions of users who aren't members of the project are ignored and listed in the warnings of the returned comment. The requesting user must not be in read-only mode.
+// @Version 2.10
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path string true "The ID of the task"
+// @Param comment body comment.DraftDto true "The new comment"
+// @Success 200 {object} project.CommentResultDto
+// @Router /v2.10/tasks/{id}/comments [POST]
+func addTaskComment_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	taskId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	bodyBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		return BadRequestError(errors.Wrap(err, "error reading request body"))
+	}
+
+	var dto comment.DraftDto
+	err = json.Unmarshal(bodyBytes, &dto)
+	if err != nil {
+		return BadRequestError(errors.Wrap(err, "error unmarshalling comment draft"))
+	}
+
+	addedComment, err := context.TaskService.AddComment(taskId, &dto, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	projectOfTask, err := context.ProjectService.GetProjectByTask(taskId)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	sendUpdate_v2_9(context.WebsocketSender, projectOfTask)
+	sendMention_v2_10(context.WebsocketSender, projectOfTask, addedComment)
+
+	context.Log("Successfully added comment %s to task %s with warnings %v", addedComment.Id, taskId, addedComment.Warnings)
+
+	return JsonResponse(&project.CommentResultDto{
+		Project: projectOfTask,
+		Comment: addedComment,
+	})
+}
+
+// Update comment
+// @Summary Changes the text of a comment.
+// @Description Changes the text of the comment and keeps the previous text in the edit history. The number of maximum characters is restricted by the server config. Mentions of users who aren't members of the project are ignored and listed in the warnings of the returned comment. The requesting user must be the author of the comment.
+// @Version 2.10
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path string true "The ID of the comment"
+// @Param comment body comment.DraftDto true "The new text of the comment"
+// @Success 200 {object} project.CommentResultDto
+// @Router /v2.10/comments/{id} [PUT]
+func updateComment_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	commentId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	bodyBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		return BadRequestError(errors.Wrap(err, "error reading request body"))
+	}
+
+	var dto comment.DraftDto
+	err = json.Unmarshal(bodyBytes, &dto)
+	if err != nil {
+		return BadRequestError(errors.Wrap(err, "error unmarshalling comment draft"))
+	}
+
+	updatedProject, updatedComment, err := context.ProjectService.UpdateComment(commentId, &dto, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	sendUpdate_v2_9(context.WebsocketSender, updatedProject)
+
+	context.Log("Successfully updated comment %s with warnings %v", commentId, updatedComment.Warnings)
+
+	return JsonResponse(&project.CommentResultDto{
+		Project: updatedProject,
+		Comment: updatedComment,
+	})
+}
+
+// Delete comment
+// @Summary Deletes a comment.
+// @Description Deletes the comment including its edit history. The requesting user must be the author of the comment or the owner of the project.
+// @Version 2.10
+// @Tags comments
+// @Produce json
+// @Param id path string true "The ID of the comment"
+// @Success 200 {object} project.Project
+// @Router /v2.10/comments/{id} [DELETE]
+func deleteComment_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	commentId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	updatedProject, err := context.ProjectService.DeleteComment(commentId, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	sendUpdate_v2_9(context.WebsocketSender, updatedProject)
+
+	context.Log("Successfully deleted comment %s", commentId)
+
+	return JsonResponse(updatedProject)
+}
+
+// Get comment history
+// @Summary Gets the previous versions of a comment.
+// @Description Gets the previous texts of the comment, the oldest first. The requesting user must be a member of the project.
+// @Version 2.10
+// @Tags comments
+// @Produce json
+// @Param id path string true "The ID of the comment"
+// @Success 200 {object} []comment.Edit
+// @Router /v2.10/comments/{id}/history [GET]
+func getCommentHistory_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	commentId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	edits, err := context.ProjectService.GetCommentHistory(commentId, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	context.Log("Successfully got %d previous versions of comment %s", len(edits), commentId)
+
+	return JsonResponse(edits)
+}
+
+// Set comment issue status
+// @Summary Marks a comment as open or resolved issue.
+// @Description Sets the issue status of the comment to "OPEN", "RESOLVED" or "NONE" for normal comments. The number of open issues is part of tasks and projects. The requesting user must be the author of the comment, the user assigned to the task of the comment or the owner of the project.
+// @Version 2.10
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path string true "The ID of the comment"
+// @Param status body comment.IssueStatusDto true "The new issue status"
+// @Success 200 {object} project.Project
+// @Router /v2.10/comments/{id}/issue [PUT]
+func setCommentIssueStatus_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	commentId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	bodyBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		return BadRequestError(errors.Wrap(err, "error reading request body"))
+	}
+
+	var dto comment.IssueStatusDto
+	err = json.Unmarshal(bodyBytes, &dto)
+	if err != nil {
+		return BadRequestError(errors.Wrap(err, "error unmarshalling issue status"))
+	}
+
+	updatedProject, err := context.ProjectService.SetCommentIssueStatus(commentId, dto.Status, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	sendUpdate_v2_9(context.WebsocketSender, updatedProject)
+
+	context.Log("Successfully set issue status of comment %s to %s", commentId, dto.Status)
+
+	return JsonResponse(updatedProject)
+}
+
+// Add comment attachment
+// @Summary Attaches a file to a comment.
+// @Description The file is uploaded as multipart form with the field "file". Only images (PNG, JPEG, GIF, WebP) and GeoJSON files up to the configured maximum size are allowed and a comment can have at most five attachments. The requesting user must be the author of the comment.
+// @Version 2.10
+// @Tags comments
+// @Accept mpfd
+// @Produce json
+// @Param id path string true "The ID of the comment"
+// @Param file formData file true "The file to attach"
+// @Success 200 {object} project.Project
+// @Router /v2.10/comments/{id}/attachments [POST]
+func addCommentAttachment_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	commentId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	// Some additional space for the other parts of the multipart body. The actual size of the file is checked later.
+	maxBodySize := int64(config.Conf.MaxAttachmentSize) + 1024*1024
+	r.Body = http.MaxBytesReader(nil, r.Body, maxBodySize)
+
+	err := r.ParseMultipartForm(maxBodySize)
+	if err != nil {
+		return BadRequestError(errors.Wrap(err, "error parsing multipart form"))
+	}
+
+	file, fileHeader, err := r.FormFile("file")
+	if err != nil {
+		return BadRequestError(errors.Wrap(err, "form field 'file' not set"))
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(io.LimitReader(file, int64(config.Conf.MaxAttachmentSize)+1))
+	if err != nil {
+		return BadRequestError(errors.Wrap(err, "error reading file"))
+	}
+
+	updatedProject, err := context.ProjectService.AddCommentAttachment(commentId, fileHeader.Filename, content, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	sendUpdate_v2_9(context.WebsocketSender, updatedProject)
+
+	context.Log("Successfully added attachment '%s' to comment %s", fileHeader.Filename, commentId)
+
+	return JsonResponse(updatedProject)
+}
+
+// Get attachment
+// @Summary Gets the file of a comment attachment.
+// @Description Returns the raw file as download. The requesting user must be a member of the project the comment belongs to.
+// @Version 2.10
+// @Tags comments
+// @Produce octet-stream
+// @Param id path string true "The ID of the attachment"
+// @Success 200 {file} file
+// @Router /v2.10/attachments/{id} [GET]
+func getAttachment_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	attachmentId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	attachment, content, err := context.ProjectService.GetCommentAttachment(attachmentId, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	context.Log("Successfully got attachment %s", attachmentId)
+
+	return FileResponse(content, attachment.ContentType, attachment.FileName)
+}
+
+// Report comment
+// @Summary Reports an inappropriate comment to the owner of the project.
+// @Description Each member can report a comment once, reporting it again replaces the reason. The requesting user must be a member of the project the comment belongs to.
+// @Version 2.10
+// @Tags comments
+// @Accept json
+// @Param id path string true "The ID of the comment"
+// @Param report body comment.ReportDto true "The reason of the report"
+// @Success 200
+// @Router /v2.10/comments/{id}/reports [POST]
+func reportComment_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	commentId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	bodyBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		return BadRequestError(errors.Wrap(err, "error reading request body"))
+	}
+
+	var dto comment.ReportDto
+	err = json.Unmarshal(bodyBytes, &dto)
+	if err != nil {
+		return BadRequestError(errors.Wrap(err, "error unmarshalling report"))
+	}
+
+	err = context.ProjectService.ReportComment(commentId, &dto, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	context.Log("Successfully reported comment %s", commentId)
+
+	return EmptyResponse()
+}
+
+// Dismiss comment reports
+// @Summary Removes all reports of a comment.
+// @Description The requesting user must be the owner of the project the comment belongs to.
+// @Version 2.10
+// @Tags comments
+// @Param id path string true "The ID of the comment"
+// @Success 200
+// @Router /v2.10/comments/{id}/reports [DELETE]
+func dismissCommentReports_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	commentId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	err := context.ProjectService.DismissCommentReports(commentId, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	context.Log("Successfully dismissed reports of comment %s", commentId)
+
+	return EmptyResponse()
+}
+
+// Hide comment
+// @Summary Hides a comment or shows it again.
+// @Description Hidden comments stay in their threads but their text, mentions and attachments are empty. The requesting user must be the owner of the project the comment belongs to.
+// @Version 2.10
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path string true "The ID of the comment"
+// @Param hidden body comment.HiddenDto true "Whether the comment should be hidden"
+// @Success 200 {object} project.Project
+// @Router /v2.10/comments/{id}/hidden [PUT]
+func setCommentHidden_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	commentId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	bodyBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		return BadRequestError(errors.Wrap(err, "error reading request body"))
+	}
+
+	var dto comment.HiddenDto
+	err = json.Unmarshal(bodyBytes, &dto)
+	if err != nil {
+		return BadRequestError(errors.Wrap(err, "error unmarshalling hidden flag"))
+	}
+
+	updatedProject, err := context.ProjectService.SetCommentHidden(commentId, dto.Hidden, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	sendUpdate_v2_9(context.WebsocketSender, updatedProject)
+
+	context.Log("Successfully set hidden flag of comment %s to %t", commentId, dto.Hidden)
+
+	return JsonResponse(updatedProject)
+}
+
+// Get campaigns
+// @Summary Get all campaigns of the requesting user.
+// @Version 2.10
+// @Tags campaigns
+// @Produce json
+// @Success 200 {object} []campaign.Campaign
+// @Router /v2.10/campaigns [GET]
+func getCampaigns_v2_10(r *http.Request, context *Context) *ApiResponse {
+	campaigns, err := context.CampaignService.GetCampaigns(context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	context.Log("Successfully got %d campaigns", len(campaigns))
+
+	return JsonResponse(campaigns)
+}
+
+// Add campaign
+// @Summary Adds a new campaign.
+// @Description Adds a new campaign with the requesting user as campaign manager.
+// @Version 2.10
+// @Tags campaigns
+// @Accept json
+// @Produce json
+// @Param campaign body campaign.DraftDto true "Draft of the campaign"
+// @Success 200 {object} campaign.Campaign
+// @Router /v2.10/campaigns [POST]
+func addCampaign_v2_10(r *http.Request, context *Context) *ApiResponse {
+	bodyBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		return BadRequestError(errors.Wrap(err, "error reading request body"))
+	}
+
+	var dto campaign.DraftDto
+	err = json.Unmarshal(bodyBytes, &dto)
+	if err != nil {
+		return BadRequestError(errors.Wrap(err, "error unmarshalling campaign draft"))
+	}
+
+	addedCampaign, err := context.CampaignService.AddCampaign(&dto, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	context.Log("Successfully added campaign %s", addedCampaign.Id)
+
+	return JsonResponse(addedCampaign)
+}
+
+// Get campaign
+// @Summary Get a specific campaign.
+// @Description Gets the campaign with its project IDs and the progress aggregated over all its projects. The requesting user must be a member of the campaign.
+// @Version 2.10
+// @Tags campaigns
+// @Produce json
+// @Param id path string true "ID of the campaign"
+// @Success 200 {object} campaign.Campaign
+// @Router /v2.10/campaigns/{id} [GET]
+func getCampaign_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	campaignId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	result, err := context.CampaignService.GetCampaign(campaignId, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	context.Log("Successfully got campaign %s", campaignId)
+
+	return JsonResponse(result)
+}
+
+// Delete campaign
+// @Summary Deletes a campaign.
+// @Description Deletes the campaign but not its projects. The requesting user must be the campaign manager.
+// @Version 2.10
+// @Tags campaigns
+// @Param id path string true "ID of the campaign"
+// @Success 200
+// @Router /v2.10/campaigns/{id} [DELETE]
+func deleteCampaign_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	campaignId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	err := context.CampaignService.DeleteCampaign(campaignId, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	context.Log("Successfully deleted campaign %s", campaignId)
+
+	return EmptyResponse()
+}
+
+// Add project to campaign
+// @Summary Adds a project to a campaign.
+// @Description Adds the project to the campaign. The requesting user must be the campaign manager and the owner of the project. A project can only be in one campaign.
+// @Version 2.10
+// @Tags campaigns
+// @Produce json
+// @Param id path string true "ID of the campaign"
+// @Param pid path string true "ID of the project"
+// @Success 200 {object} campaign.Campaign
+// @Router /v2.10/campaigns/{id}/projects/{pid} [POST]
+func addProjectToCampaign_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	campaignId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	projectId, ok := vars["pid"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'pid' not set"))
+	}
+
+	updatedCampaign, err := context.CampaignService.AddProject(campaignId, projectId, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	context.Log("Successfully added project %s to campaign %s", projectId, campaignId)
+
+	return JsonResponse(updatedCampaign)
+}
+
+// Remove project from campaign
+// @Summary Removes a project from a campaign.
+// @Description Removes the project from the campaign without deleting it. The requesting user must be the campaign manager.
+// @Version 2.10
+// @Tags campaigns
+// @Produce json
+// @Param id path string true "ID of the campaign"
+// @Param pid path string true "ID of the project"
+// @Success 200 {object} campaign.Campaign
+// @Router /v2.10/campaigns/{id}/projects/{pid} [DELETE]
+func removeProjectFromCampaign_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	campaignId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	projectId, ok := vars["pid"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'pid' not set"))
+	}
+
+	updatedCampaign, err := context.CampaignService.RemoveProject(campaignId, projectId, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	context.Log("Successfully removed project %s from campaign %s", projectId, campaignId)
+
+	return JsonResponse(updatedCampaign)
+}
+
+// Add user to campaign
+// @Summary Adds a user to a campaign.
+// @Description Adds the given user to the members of the campaign. The requesting user must be the campaign manager.
+// @Version 2.10
+// @Tags campaigns
+// @Produce json
+// @Param id path string true "ID of the campaign"
+// @Param uid query string true "The OSM user-ID to add to the campaign"
+// @Success 200 {object} campaign.Campaign
+// @Router /v2.10/campaigns/{id}/users [POST]
+func addUserToCampaign_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	campaignId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	userToAdd, err := util.GetParam("uid", r)
+	if err != nil {
+		return BadRequestError(errors.Wrap(err, "url param 'uid' not set"))
+	}
+
+	updatedCampaign, err := context.CampaignService.AddUser(campaignId, userToAdd, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	context.Log("Successfully added user %s to campaign %s", userToAdd, campaignId)
+
+	return JsonResponse(updatedCampaign)
+}
+
+// Remove user from campaign
+// @Summary Removes a user from a campaign.
+// @Description Removes the given user from the members of the campaign. The requesting user must be the campaign manager.
+// @Version 2.10
+// @Tags campaigns
+// @Produce json
+// @Param id path string true "ID of the campaign"
+// @Param uid path string true "The OSM user-ID to remove from the campaign"
+// @Success 200 {object} campaign.Campaign
+// @Router /v2.10/campaigns/{id}/users/{uid} [DELETE]
+func removeUserFromCampaign_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	campaignId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	userToRemove, ok := vars["uid"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'uid' not set"))
+	}
+
+	updatedCampaign, err := context.CampaignService.RemoveUser(campaignId, userToRemove, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	context.Log("Successfully removed user %s from campaign %s", userToRemove, campaignId)
+
+	return JsonResponse(updatedCampaign)
+}
+
+// Add team to project
+// @Summary Adds a team to a project.
+// @Description Adds the team to the project so that all team members are members of the project. The requesting user must be the owner of the project and a member of the team.
+// @Version 2.10
+// @Tags projects
+// @Produce json
+// @Param id path string true "ID of the project"
+// @Param tid path string true "ID of the team"
+// @Success 200 {object} project.Project
+// @Router /v2.10/projects/{id}/teams/{tid} [POST]
+func addTeamToProject_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	projectId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	teamId, ok := vars["tid"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'tid' not set"))
+	}
+
+	updatedProject, err := context.ProjectService.AddTeam(projectId, teamId, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	sendUpdate_v2_9(context.WebsocketSender, updatedProject)
+
+	context.Log("Successfully added team %s to project %s", teamId, projectId)
+
+	return JsonResponse(updatedProject)
+}
+
+// Remove team from project
+// @Summary Removes a team from a project.
+// @Description Removes the team from the project. Team members who aren't members of the project anymore get unassigned from their tasks. The requesting user must be the owner of the project.
+// @Version 2.10
+// @Tags projects
+// @Produce json
+// @Param id path string true "ID of the project"
+// @Param tid path string true "ID of the team"
+// @Success 200 {object} project.Project
+// @Router /v2.10/projects/{id}/teams/{tid} [DELETE]
+func removeTeamFromProject_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	projectId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	teamId, ok := vars["tid"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'tid' not set"))
+	}
+
+	updatedProject, err := context.ProjectService.RemoveTeam(projectId, teamId, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	sendUpdate_v2_9(context.WebsocketSender, updatedProject)
+
+	context.Log("Successfully removed team %s from project %s", teamId, projectId)
+
+	return JsonResponse(updatedProject)
+}
+
+// Get comment reports
+// @Summary Gets the reported comments of a project.
+// @Description Returns the reports of all comments on the project and its tasks, the oldest first. The reports contain the original text of the comments, even of hidden ones. The requesting user must be the owner of the project.
+// @Version 2.10
+// @Tags projects
+// @Produce json
+// @Param id path string true "ID of the project"
+// @Success 200 {object} []comment.Report
+// @Router /v2.10/projects/{id}/reports [GET]
+func getCommentReports_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	projectId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	reports, err := context.ProjectService.GetCommentReports(projectId, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	context.Log("Successfully got %d comment reports of project %s", len(reports), projectId)
+
+	return JsonResponse(reports)
+}
+
+// Add read-only user
+// @Summary Puts a member of a project into read-only mode.
+// @Description Users in read-only mode can't write comments in the project anymore. The requesting user must be the owner of the project and the owner can't be put into read-only mode.
+// @Version 2.10
+// @Tags projects
+// @Produce json
+// @Param id path string true "ID of the project"
+// @Param uid path string true "ID of the user"
+// @Success 200 {object} project.Project
+// @Router /v2.10/projects/{id}/read-only-users/{uid} [POST]
+func addReadOnlyUser_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	projectId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	userId, ok := vars["uid"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'uid' not set"))
+	}
+
+	updatedProject, err := context.ProjectService.AddReadOnlyUser(projectId, userId, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	sendUpdate_v2_9(context.WebsocketSender, updatedProject)
+
+	context.Log("Successfully put user %s into read-only mode in project %s", userId, projectId)
+
+	return JsonResponse(updatedProject)
+}
+
+// Remove read-only user
+// @Summary Allows a member of a project to write comments again.
+// @Description The requesting user must be the owner of the project.
+// @Version 2.10
+// @Tags projects
+// @Produce json
+// @Param id path string true "ID of the project"
+// @Param uid path string true "ID of the user"
+// @Success 200 {object} project.Project
+// @Router /v2.10/projects/{id}/read-only-users/{uid} [DELETE]
+func removeReadOnlyUser_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	projectId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	userId, ok := vars["uid"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'uid' not set"))
+	}
+
+	updatedProject, err := context.ProjectService.RemoveReadOnlyUser(projectId, userId, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	sendUpdate_v2_9(context.WebsocketSender, updatedProject)
+
+	context.Log("Successfully removed user %s from read-only mode in project %s", userId, projectId)
+
+	return JsonResponse(updatedProject)
+}
+
+// Get teams
+// @Summary Get all teams of the requesting user.
+// @Version 2.10
+// @Tags teams
+// @Produce json
+// @Success 200 {object} []team.Team
+// @Router /v2.10/teams [GET]
+func getTeams_v2_10(r *http.Request, context *Context) *ApiResponse {
+	teams, err := context.TeamService.GetTeams(context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	context.Log("Successfully got %d teams", len(teams))
+
+	return JsonResponse(teams)
+}
+
+// Add team
+// @Summary Adds a new team.
+// @Description Adds a new team with the requesting user as admin.
+// @Version 2.10
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param team body team.DraftDto true "Draft of the team"
+// @Success 200 {object} team.Team
+// @Router /v2.10/teams [POST]
+func addTeam_v2_10(r *http.Request, context *Context) *ApiResponse {
+	bodyBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		return BadRequestError(errors.Wrap(err, "error reading request body"))
+	}
+
+	var dto team.DraftDto
+	err = json.Unmarshal(bodyBytes, &dto)
+	if err != nil {
+		return BadRequestError(errors.Wrap(err, "error unmarshalling team draft"))
+	}
+
+	addedTeam, err := context.TeamService.AddTeam(&dto, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	context.Log("Successfully added team %s", addedTeam.Id)
+
+	return JsonResponse(addedTeam)
+}
+
+// Get team
+// @Summary Get a specific team.
+// @Description Gets the team with the IDs of its projects. The requesting user must be a member of the team.
+// @Version 2.10
+// @Tags teams
+// @Produce json
+// @Param id path string true "ID of the team"
+// @Success 200 {object} team.Team
+// @Router /v2.10/teams/{id} [GET]
+func getTeam_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	teamId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	result, err := context.TeamService.GetTeam(teamId, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	context.Log("Successfully got team %s", teamId)
+
+	return JsonResponse(result)
+}
+
+// Delete team
+// @Summary Deletes a team.
+// @Description Deletes the team and removes it from all its projects. Former members of these projects get unassigned from their tasks. The requesting user must be an admin of the team.
+// @Version 2.10
+// @Tags teams
+// @Param id path string true "ID of the team"
+// @Success 200
+// @Router /v2.10/teams/{id} [DELETE]
+func deleteTeam_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	teamId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	updatedProjects, err := context.TeamService.DeleteTeam(teamId, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	for _, updatedProject := range updatedProjects {
+		sendUpdate_v2_9(context.WebsocketSender, updatedProject)
+	}
+
+	context.Log("Successfully deleted team %s", teamId)
+
+	return EmptyResponse()
+}
+
+// Add user to team
+// @Summary Adds a user to a team.
+// @Description Adds the given user to the members of the team and therefore to all projects of the team. The requesting user must be an admin of the team.
+// @Version 2.10
+// @Tags teams
+// @Produce json
+// @Param id path string true "ID of the team"
+// @Param uid query string true "The OSM user-ID to add to the team"
+// @Success 200 {object} team.Team
+// @Router /v2.10/teams/{id}/users [POST]
+func addUserToTeam_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	teamId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	userToAdd, err := util.GetParam("uid", r)
+	if err != nil {
+		return BadRequestError(errors.Wrap(err, "url param 'uid' not set"))
+	}
+
+	updatedTeam, updatedProjects, err := context.TeamService.AddUser(teamId, userToAdd, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	for _, updatedProject := range updatedProjects {
+		sendUpdate_v2_9(context.WebsocketSender, updatedProject)
+	}
+
+	context.Log("Successfully added user %s to team %s", userToAdd, teamId)
+
+	return JsonResponse(updatedTeam)
+}
+
+// Remove user from team
+// @Summary Removes a user from a team.
+// @Description Removes the given user from the team. Admins can remove every member and every member can leave the team, but the last admin cannot be removed. The user gets unassigned from all tasks of projects he/she isn't a member of anymore.
+// @Version 2.10
+// @Tags teams
+// @Produce json
+// @Param id path string true "ID of the team"
+// @Param uid path string true "The OSM user-ID to remove from the team"
+// @Success 200 {object} team.Team
+// @Router /v2.10/teams/{id}/users/{uid} [DELETE]
+func removeUserFromTeam_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	teamId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	userToRemove, ok := vars["uid"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'uid' not set"))
+	}
+
+	updatedTeam, leftProjects, err := context.TeamService.RemoveUser(teamId, userToRemove, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	for _, leftProject := range leftProjects {
+		sendUserRemoved_v2_9(context.WebsocketSender, leftProject, userToRemove)
+	}
+
+	context.Log("Successfully removed user %s from team %s", userToRemove, teamId)
+
+	return JsonResponse(updatedTeam)
+}
+
+// Add admin to team
+// @Summary Makes a member of a team an admin.
+// @Description Makes the given member of the team an admin. The requesting user must be an admin of the team.
+// @Version 2.10
+// @Tags teams
+// @Produce json
+// @Param id path string true "ID of the team"
+// @Param uid path string true "The OSM user-ID of the member"
+// @Success 200 {object} team.Team
+// @Router /v2.10/teams/{id}/admins/{uid} [POST]
+func addAdminToTeam_v2_10(r *http.Request, context *Context) *ApiResponse {
+	vars := mux.Vars(r)
+	teamId, ok := vars["id"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'id' not set"))
+	}
+
+	userId, ok := vars["uid"]
+	if !ok {
+		return BadRequestError(errors.New("url segment 'uid' not set"))
+	}
+
+	updatedTeam, err := context.TeamService.AddAdmin(teamId, userId, context.Token.UID)
+	if err != nil {
+		return InternalServerError(err)
+	}
+
+	context.Log("Successfully made user %s an admin of team %s", userId, teamId)
+
+	return JsonResponse(updatedTeam)
+}
+
+// sendMention_v2_10 notifies the users mentioned in the comment. The message contains the ID of the project so that
+// clients can load the project containing the comment.
+func sendMention_v2_10(sender *websocket.Sender, projectOfComment *project.Project, mentioningComment *comment.Comment) {
+	if len(mentioningComment.Mentions) == 0 {
+		return
+	}
+
+	sender.Send(websocket.Message{
+		Type: websocket.MessageType_CommentMention,
+		Id:   projectOfComment.Id,
+	}, mentioningComment.Mentions...)
+}
@@ -221,7 +221,7 @@ func addProjectComments_v2_9(r *http.Request, context *Context) *ApiResponse {
 
 	user := context.Token.UID
 
-	err = context.ProjectService.AddComment(projectId, &dto, user)
+	addedComment, err := context.ProjectService.AddComment(projectId, &dto, user)
 	if err != nil {
 		return InternalServerError(err)
 	}
@@ -233,7 +233,7 @@ func addProjectComments_v2_9(r *http.Request, context *Context) *ApiResponse {
 
 	sendUpdate_v2_9(context.WebsocketSender, projectWithComment)
 
-	context.Log("Successfully added comment to project %s", projectId)
+	context.Log("Successfully added comment to project %s with warnings %v", projectId, addedComment.Warnings)
 
 	return JsonResponse(projectWithComment)
 }
@@ -295,7 +295,7 @@ func exportProject_v2_9(r *http.Request, context *Context) *ApiResponse {
 
 // Imports a previously exported project.
 // @Summary Imports a previously exported project.
-// @Description This aims to import a project from e.g. a backup or to migrate to another STM instance.
+// @Description This aims to import a project from e.g. a backup or to migrate to another STM instance. Exports of all format versions (incl. the legacy format without "formatVersion") are supported.
 // @Version 2.9
 // @Tags projects
 // @Produce json
@@ -353,7 +353,7 @@ func updateProject_v2_9(r *http.Request, context *Context) *ApiResponse {
 		return InternalServerError(errors.Wrap(err, "error unmarshalling project update"))
 	}
 
-	updatedProject, err := context.ProjectService.Update(projectId, dto.Name, dto.Description, dto.JosmDataSource, context.Token.UID)
+	updatedProject, err := context.ProjectService.Update(projectId, dto.Name, dto.Description, dto.JosmDataSource, nil, context.Token.UID)
 	if err != nil {
 		return InternalServerError(err)
 	}
@@ -559,7 +559,7 @@ func addTaskComments_v2_9(r *http.Request, context *Context) *ApiResponse {
 
 	user := context.Token.UID
 
-	err = context.TaskService.AddComment(taskId, &dto, user)
+	addedComment, err := context.TaskService.AddComment(taskId, &dto, user)
 	if err != nil {
 		return InternalServerError(err)
 	}
@@ -574,7 +574,7 @@ func addTaskComments_v2_9(r *http.Request, context *Context) *ApiResponse {
 		return InternalServerError(err)
 	}
 
-	context.Log("Successfully added comment to task '%s'", taskId)
+	context.Log("Successfully added comment to task '%s' with warnings %v", taskId, addedComment.Warnings)
 
 	return JsonResponse(taskOfComment)
 }
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"fmt"
+	"stm/util"
+	"time"
+)
+
+const (
+	attachmentCleanupInterval = time.Hour
+)
+
+// startAttachmentCleanup regularly removes files of the attachment directory that don't belong to any attachment. This
+// runs forever and should therefore be started as goroutine.
+func startAttachmentCleanup() {
+	for range time.Tick(attachmentCleanupInterval) {
+		removeOrphanedAttachmentFiles()
+	}
+}
+
+// removeOrphanedAttachmentFiles removes the files of deleted or rolled back attachments. Files younger than the cleanup
+// interval are kept, their transaction might still be running.
+func removeOrphanedAttachmentFiles() {
+	logger := util.NewLogger()
+
+	context, err := createContext(nil, logger)
+	if err != nil {
+		logger.Err("Unable to create context for attachment cleanup: %s", err)
+		return
+	}
+
+	// Recover from panic and perform rollback on transaction
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Err("!! PANIC !! Recover from panic during attachment cleanup: %v", r)
+			logger.Stack(fmt.Errorf("%v", r))
+
+			err := context.Transaction.Rollback()
+			if err != nil {
+				logger.Err("Rollback of transaction failed: %s", err)
+			}
+		}
+	}()
+
+	err = context.CommentService.RemoveOrphanedAttachmentFiles(attachmentCleanupInterval)
+	if err != nil {
+		panic(err)
+	}
+
+	err = context.Transaction.Commit()
+	if err != nil {
+		logger.Err("Unable to commit transaction of attachment cleanup: %s", err)
+	}
+}
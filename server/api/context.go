@@ -3,25 +3,34 @@ package api
 import (
 	"database/sql"
 	"github.com/pkg/errors"
+	"stm/campaign"
 	"stm/comment"
 	"stm/database"
 	"stm/export"
+	"stm/invitation"
+	"stm/leaderboard"
 	"stm/oauth2"
 	"stm/permission"
 	"stm/project"
 	"stm/task"
+	"stm/team"
 	"stm/util"
 	"stm/websocket"
 )
 
 type Context struct {
 	*util.Logger
-	Token           *oauth2.Token
-	Transaction     *sql.Tx
-	ProjectService  *project.Service
-	TaskService     *task.Service
-	ExportService   *export.Service
-	WebsocketSender *websocket.Sender
+	Token              *oauth2.Token
+	Transaction        *sql.Tx
+	ProjectService     *project.Service
+	TaskService        *task.Service
+	CommentService     *comment.Service
+	ExportService      *export.Service
+	InvitationService  *invitation.Service
+	LeaderboardService *leaderboard.Service
+	CampaignService    *campaign.Service
+	TeamService        *team.Service
+	WebsocketSender    *websocket.Sender
 }
 
 // createContext starts a new Transaction and creates new service instances which use this new Transaction so that all
@@ -40,10 +49,15 @@ func createContext(token *oauth2.Token, logger *util.Logger) (*Context, error) {
 	permissionStore := permission.Init(tx, ctx.Logger)
 	commentStore := comment.GetStore(tx, ctx.Logger)
 	commentService := comment.Init(ctx.Logger, commentStore)
+	ctx.CommentService = commentService
 
 	ctx.TaskService = task.Init(tx, ctx.Logger, permissionStore, commentService, commentStore)
 	ctx.ProjectService = project.Init(tx, ctx.Logger, ctx.TaskService, permissionStore, commentService, commentStore)
-	ctx.ExportService = export.Init(logger, ctx.ProjectService)
+	ctx.ExportService = export.Init(logger, ctx.ProjectService, ctx.TaskService)
+	ctx.InvitationService = invitation.Init(tx, ctx.Logger, permissionStore, ctx.ProjectService)
+	ctx.LeaderboardService = leaderboard.Init(tx, ctx.Logger, permissionStore)
+	ctx.CampaignService = campaign.Init(tx, ctx.Logger, permissionStore)
+	ctx.TeamService = team.Init(tx, ctx.Logger, permissionStore, ctx.ProjectService)
 	ctx.WebsocketSender = websocket.Init(ctx.Logger)
 
 	return ctx, nil
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"fmt"
+	"stm/util"
+	"stm/websocket"
+	"time"
+)
+
+const (
+	dueDateCheckInterval = time.Minute
+)
+
+// startDueDateWatcher regularly checks for projects whose due date passed and notifies their members via websocket.
+// This runs forever and should therefore be started as goroutine.
+func startDueDateWatcher() {
+	for range time.Tick(dueDateCheckInterval) {
+		notifyAboutPassedDueDates()
+	}
+}
+
+// notifyAboutPassedDueDates sends a websocket message for each project whose due date passed since the last check.
+func notifyAboutPassedDueDates() {
+	logger := util.NewLogger()
+
+	context, err := createContext(nil, logger)
+	if err != nil {
+		logger.Err("Unable to create context for due date check: %s", err)
+		return
+	}
+
+	// Recover from panic and perform rollback on transaction
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Err("!! PANIC !! Recover from panic during due date check: %v", r)
+			logger.Stack(fmt.Errorf("%v", r))
+
+			err := context.Transaction.Rollback()
+			if err != nil {
+				logger.Err("Rollback of transaction failed: %s", err)
+			}
+		}
+	}()
+
+	projects, err := context.ProjectService.MarkDueDatePassed()
+	if err != nil {
+		panic(err)
+	}
+
+	err = context.Transaction.Commit()
+	if err != nil {
+		logger.Err("Unable to commit transaction of due date check: %s", err)
+		return
+	}
+
+	// Only notify after the commit, otherwise the notifications would be sent again after a rollback
+	for _, p := range projects {
+		context.Log("Due date of project %s passed", p.Id)
+		context.WebsocketSender.Send(websocket.Message{
+			Type: websocket.MessageType_ProjectDueDatePassed,
+			Id:   p.Id,
+		}, p.Users...)
+	}
+}
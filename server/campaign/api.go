@@ -0,0 +1,7 @@
+package campaign
+
+type DraftDto struct {
+	Name        string   `json:"name"`        // Name of the campaign. Must not be NULL or empty.
+	Description string   `json:"description"` // Description of the campaign. Must not be NULL but can be empty.
+	Users       []string `json:"users"`       // List of user-IDs. The requesting user (campaign manager) is always added.
+}
@@ -0,0 +1,19 @@
+package campaign
+
+import "time"
+
+// Campaign groups several projects, e.g. one project per district of a large activation. The numbers are aggregated
+// over all tasks of all projects of the campaign.
+type Campaign struct {
+	Id                 string     `json:"id"`                 // The ID of the campaign.
+	Name               string     `json:"name"`               // The name of the campaign. Will not be NULL or empty.
+	Description        string     `json:"description"`        // Some description. Will not be NULL but might be empty.
+	Owner              string     `json:"owner"`              // User-ID of the campaign manager. Will not be NULL or empty.
+	Users              []string   `json:"users"`              // Array of user-IDs (=members of this campaign). Will not be NULL or empty.
+	CreationDate       *time.Time `json:"creationDate"`       // UTC Date in RFC 3339 format.
+	ProjectIds         []string   `json:"projectIds"`         // IDs of the projects of this campaign. Will not be NULL but might be empty.
+	TaskCount          int        `json:"taskCount"`          // Number of tasks in all projects.
+	DoneTaskCount      int        `json:"doneTaskCount"`      // Number of tasks in all projects where the maximum process points have been reached.
+	TotalProcessPoints int        `json:"totalProcessPoints"` // Sum of all maximum process points of all tasks of all projects.
+	DoneProcessPoints  int        `json:"doneProcessPoints"`  // Sum of all process points that have been set in all projects.
+}
@@ -0,0 +1,188 @@
+package campaign
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/pkg/errors"
+	"stm/config"
+	"stm/permission"
+	"stm/util"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type Service struct {
+	*util.Logger
+	store           *store
+	permissionStore *permission.Store
+}
+
+func Init(tx *sql.Tx, logger *util.Logger, permissionStore *permission.Store) *Service {
+	return &Service{
+		Logger:          logger,
+		store:           getStore(tx, logger),
+		permissionStore: permissionStore,
+	}
+}
+
+// GetCampaigns returns all campaigns the given user is a member of.
+func (s *Service) GetCampaigns(userId string) ([]*Campaign, error) {
+	return s.store.getCampaignsOfUser(userId)
+}
+
+func (s *Service) GetCampaign(campaignId string, potentialMemberId string) (*Campaign, error) {
+	err := s.permissionStore.VerifyMembershipCampaign(campaignId, potentialMemberId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.store.getCampaign(campaignId)
+}
+
+// AddCampaign creates a new campaign with the requesting user as campaign manager.
+func (s *Service) AddCampaign(draft *DraftDto, requestingUserId string) (*Campaign, error) {
+	if strings.TrimSpace(draft.Name) == "" {
+		return nil, errors.New("Name must be set")
+	}
+
+	if utf8.RuneCountInString(draft.Description) > config.Conf.MaxDescriptionLength {
+		return nil, errors.New(fmt.Sprintf("Description too long. Allowed are %d characters but found %d.", config.Conf.MaxDescriptionLength, utf8.RuneCountInString(draft.Description)))
+	}
+
+	users := []string{requestingUserId}
+	for _, u := range draft.Users {
+		if u != requestingUserId && strings.TrimSpace(u) != "" {
+			users = append(users, u)
+		}
+	}
+	draft.Users = users
+
+	campaign, err := s.store.addCampaign(draft, requestingUserId, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	s.Log("Added campaign %s", campaign.Id)
+
+	return campaign, nil
+}
+
+// DeleteCampaign removes the campaign but not its projects. The requesting user must be the campaign manager.
+func (s *Service) DeleteCampaign(campaignId string, requestingUserId string) error {
+	err := s.permissionStore.VerifyCampaignOwnership(campaignId, requestingUserId)
+	if err != nil {
+		return err
+	}
+
+	err = s.store.delete(campaignId)
+	if err != nil {
+		return err
+	}
+	s.Log("Deleted campaign %s", campaignId)
+
+	return nil
+}
+
+// AddProject adds the project to the campaign. The requesting user must be the campaign manager and the owner of the
+// project. A project can only be in one campaign at a time.
+func (s *Service) AddProject(campaignId string, projectId string, requestingUserId string) (*Campaign, error) {
+	err := s.permissionStore.VerifyCampaignOwnership(campaignId, requestingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.permissionStore.VerifyOwnership(projectId, requestingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.store.setCampaignOfProject(projectId, nil, &campaignId)
+	if err != nil {
+		return nil, err
+	}
+	s.Log("Added project %s to campaign %s", projectId, campaignId)
+
+	return s.store.getCampaign(campaignId)
+}
+
+// RemoveProject removes the project from the campaign without deleting it. The requesting user must be the campaign
+// manager.
+func (s *Service) RemoveProject(campaignId string, projectId string, requestingUserId string) (*Campaign, error) {
+	err := s.permissionStore.VerifyCampaignOwnership(campaignId, requestingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.store.setCampaignOfProject(projectId, &campaignId, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.Log("Removed project %s from campaign %s", projectId, campaignId)
+
+	return s.store.getCampaign(campaignId)
+}
+
+// AddUser adds the user to the members of the campaign. The requesting user must be the campaign manager.
+func (s *Service) AddUser(campaignId string, userId string, requestingUserId string) (*Campaign, error) {
+	err := s.permissionStore.VerifyCampaignOwnership(campaignId, requestingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	campaign, err := s.store.getCampaign(campaignId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, u := range campaign.Users {
+		if u == userId {
+			return nil, errors.New(fmt.Sprintf("user %s is already a member of campaign %s", userId, campaignId))
+		}
+	}
+
+	campaign, err = s.store.updateUsers(campaignId, append(campaign.Users, userId))
+	if err != nil {
+		return nil, err
+	}
+	s.Log("Added user %s to campaign %s", userId, campaignId)
+
+	return campaign, nil
+}
+
+// RemoveUser removes the user from the members of the campaign. The requesting user must be the campaign manager, who
+// can't be removed.
+func (s *Service) RemoveUser(campaignId string, userId string, requestingUserId string) (*Campaign, error) {
+	err := s.permissionStore.VerifyCampaignOwnership(campaignId, requestingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if userId == requestingUserId {
+		return nil, errors.New("the campaign manager cannot be removed from the campaign")
+	}
+
+	err = s.permissionStore.VerifyMembershipCampaign(campaignId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	campaign, err := s.store.getCampaign(campaignId)
+	if err != nil {
+		return nil, err
+	}
+
+	remainingUsers := make([]string, 0)
+	for _, u := range campaign.Users {
+		if u != userId {
+			remainingUsers = append(remainingUsers, u)
+		}
+	}
+
+	campaign, err = s.store.updateUsers(campaignId, remainingUsers)
+	if err != nil {
+		return nil, err
+	}
+	s.Log("Removed user %s from campaign %s", userId, campaignId)
+
+	return campaign, nil
+}
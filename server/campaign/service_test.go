@@ -0,0 +1,246 @@
+package campaign
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/hauke96/sigolo"
+	"github.com/pkg/errors"
+	"stm/config"
+	"stm/permission"
+	"stm/test"
+	"stm/util"
+	"testing"
+
+	_ "github.com/lib/pq" // Make driver "postgres" usable
+)
+
+var (
+	tx *sql.Tx
+	s  *Service
+	h  *test.Helper
+)
+
+func TestMain(m *testing.M) {
+	h = test.NewTestHelper(setup)
+	m.Run()
+}
+
+func setup() {
+	sigolo.LogLevel = sigolo.LOG_DEBUG
+	config.LoadConfig("../test/test-config.json")
+	h.InitWithDummyData(config.Conf.DbUsername, config.Conf.DbPassword, config.Conf.DbDatabase)
+	tx = h.NewTransaction()
+
+	logger := util.NewLogger()
+
+	permissionStore := permission.Init(tx, logger)
+	s = Init(tx, logger, permissionStore)
+}
+
+func TestGetCampaigns(t *testing.T) {
+	h.Run(t, func() error {
+		campaigns, err := s.GetCampaigns("John")
+		if err != nil {
+			return err
+		}
+
+		if len(campaigns) != 1 || campaigns[0].Id != "1" {
+			return errors.New(fmt.Sprintf("John should only be member of campaign 1: %#v", campaigns))
+		}
+
+		campaigns, err = s.GetCampaigns("Peter")
+		if err != nil {
+			return err
+		}
+		if len(campaigns) != 0 {
+			return errors.New(fmt.Sprintf("Peter should not be member of any campaign: %#v", campaigns))
+		}
+
+		return nil
+	})
+}
+
+func TestGetCampaign(t *testing.T) {
+	h.Run(t, func() error {
+		campaign, err := s.GetCampaign("1", "John")
+		if err != nil {
+			return err
+		}
+
+		if campaign.Name != "Campaign 1" || campaign.Owner != "Maria" || len(campaign.Users) != 2 {
+			return errors.New(fmt.Sprintf("Campaign not matching: %#v", campaign))
+		}
+		if len(campaign.ProjectIds) != 1 || campaign.ProjectIds[0] != "2" {
+			return errors.New(fmt.Sprintf("Campaign should contain project 2: %v", campaign.ProjectIds))
+		}
+		if campaign.TaskCount != 5 || campaign.DoneTaskCount != 1 || campaign.TotalProcessPoints != 308 || campaign.DoneProcessPoints != 154 {
+			return errors.New(fmt.Sprintf("Aggregated numbers not matching: %#v", campaign))
+		}
+
+		// Member of the project but not of the campaign
+		_, err = s.GetCampaign("1", "Anna")
+		if err == nil {
+			return errors.New("Non-member should not be able to get campaign")
+		}
+
+		return nil
+	})
+}
+
+func TestAddCampaign(t *testing.T) {
+	h.Run(t, func() error {
+		campaign, err := s.AddCampaign(&DraftDto{Name: "New campaign", Description: "foo", Users: []string{"Anna", "Otto"}}, "Otto")
+		if err != nil {
+			return err
+		}
+
+		if campaign.Id == "" || campaign.Name != "New campaign" || campaign.Owner != "Otto" || campaign.CreationDate == nil {
+			return errors.New(fmt.Sprintf("Campaign not matching: %#v", campaign))
+		}
+		if len(campaign.Users) != 2 || campaign.Users[0] != "Otto" || campaign.Users[1] != "Anna" {
+			return errors.New(fmt.Sprintf("Users not matching: %v", campaign.Users))
+		}
+		if len(campaign.ProjectIds) != 0 || campaign.TaskCount != 0 {
+			return errors.New(fmt.Sprintf("New campaign should not have projects: %#v", campaign))
+		}
+
+		_, err = s.AddCampaign(&DraftDto{Name: " "}, "Otto")
+		if err == nil {
+			return errors.New("Campaign without name should not be allowed")
+		}
+
+		return nil
+	})
+}
+
+func TestAddAndRemoveProject(t *testing.T) {
+	h.Run(t, func() error {
+		campaign, err := s.AddCampaign(&DraftDto{Name: "Otto's campaign"}, "Otto")
+		if err != nil {
+			return err
+		}
+
+		campaign, err = s.AddProject(campaign.Id, "3", "Otto")
+		if err != nil {
+			return err
+		}
+		if len(campaign.ProjectIds) != 1 || campaign.ProjectIds[0] != "3" || campaign.TaskCount != 2 || campaign.TotalProcessPoints != 2000 {
+			return errors.New(fmt.Sprintf("Project 3 not added correctly: %#v", campaign))
+		}
+
+		// Maria is not the owner of project 3
+		_, err = s.AddProject("1", "3", "Maria")
+		if err == nil {
+			return errors.New("Adding project of someone else should not work")
+		}
+
+		campaign, err = s.RemoveProject(campaign.Id, "3", "Otto")
+		if err != nil {
+			return err
+		}
+		if len(campaign.ProjectIds) != 0 || campaign.TaskCount != 0 {
+			return errors.New(fmt.Sprintf("Project 3 not removed: %#v", campaign))
+		}
+
+		// Not in this campaign
+		_, err = s.RemoveProject(campaign.Id, "2", "Otto")
+		if err == nil {
+			return errors.New("Removing project of other campaign should not work")
+		}
+
+		return nil
+	})
+}
+
+func TestAddProjectOfOtherCampaign(t *testing.T) {
+	h.Run(t, func() error {
+		campaign, err := s.AddCampaign(&DraftDto{Name: "Maria's second campaign"}, "Maria")
+		if err != nil {
+			return err
+		}
+
+		// Project 2 is already in campaign 1
+		_, err = s.AddProject(campaign.Id, "2", "Maria")
+		if err == nil {
+			return errors.New("Project should only be in one campaign")
+		}
+
+		// Maria is not the owner of project 1
+		_, err = s.AddProject(campaign.Id, "1", "Maria")
+		if err == nil {
+			return errors.New("Only the owner of a project should be able to add it")
+		}
+
+		// John is not the campaign manager
+		_, err = s.RemoveProject("1", "2", "John")
+		if err == nil {
+			return errors.New("Only the campaign manager should be able to remove projects")
+		}
+
+		return nil
+	})
+}
+
+func TestAddAndRemoveUser(t *testing.T) {
+	h.Run(t, func() error {
+		campaign, err := s.AddUser("1", "Anna", "Maria")
+		if err != nil {
+			return err
+		}
+		if len(campaign.Users) != 3 || campaign.Users[2] != "Anna" {
+			return errors.New(fmt.Sprintf("Anna not added: %v", campaign.Users))
+		}
+
+		_, err = s.AddUser("1", "Anna", "Maria")
+		if err == nil {
+			return errors.New("Adding a user twice should not work")
+		}
+
+		_, err = s.AddUser("1", "Otto", "John")
+		if err == nil {
+			return errors.New("Only the campaign manager should be able to add users")
+		}
+
+		campaign, err = s.RemoveUser("1", "John", "Maria")
+		if err != nil {
+			return err
+		}
+		if len(campaign.Users) != 2 || campaign.Users[1] != "Anna" {
+			return errors.New(fmt.Sprintf("John not removed: %v", campaign.Users))
+		}
+
+		_, err = s.RemoveUser("1", "Maria", "Maria")
+		if err == nil {
+			return errors.New("Campaign manager should not be removable")
+		}
+
+		return nil
+	})
+}
+
+func TestDeleteCampaign(t *testing.T) {
+	h.Run(t, func() error {
+		err := s.DeleteCampaign("1", "John")
+		if err == nil {
+			return errors.New("Only the campaign manager should be able to delete the campaign")
+		}
+
+		err = s.DeleteCampaign("1", "Maria")
+		if err != nil {
+			return err
+		}
+
+		_, err = s.GetCampaign("1", "Maria")
+		if err == nil {
+			return errors.New("Campaign should not exist anymore")
+		}
+
+		// The project stays and can be added to another campaign
+		campaign, err := s.AddCampaign(&DraftDto{Name: "New campaign"}, "Maria")
+		if err != nil {
+			return err
+		}
+		_, err = s.AddProject(campaign.Id, "2", "Maria")
+		return err
+	})
+}
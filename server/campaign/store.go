@@ -0,0 +1,204 @@
+package campaign
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"stm/util"
+	"strconv"
+	"time"
+)
+
+type store struct {
+	*util.Logger
+	tx           *sql.Tx
+	table        string
+	projectTable string
+	taskTable    string
+}
+
+var (
+	returnValues = "id, name, description, owner, users, creation_date"
+)
+
+func getStore(tx *sql.Tx, logger *util.Logger) *store {
+	return &store{
+		Logger:       logger,
+		tx:           tx,
+		table:        "campaigns",
+		projectTable: "projects",
+		taskTable:    "tasks",
+	}
+}
+
+func (s *store) getCampaignsOfUser(userId string) ([]*Campaign, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE $1 = ANY(users) ORDER BY id;", returnValues, s.table)
+	return s.execQuery(query, userId)
+}
+
+func (s *store) getCampaign(campaignId string) (*Campaign, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id = $1;", returnValues, s.table)
+	return s.execQuerySingle(query, campaignId)
+}
+
+func (s *store) addCampaign(draft *DraftDto, owner string, creationDate time.Time) (*Campaign, error) {
+	query := fmt.Sprintf("INSERT INTO %s (name, description, owner, users, creation_date) VALUES($1, $2, $3, $4, $5) RETURNING %s;", s.table, returnValues)
+	return s.execQuerySingle(query, draft.Name, draft.Description, owner, pq.Array(draft.Users), creationDate)
+}
+
+func (s *store) updateUsers(campaignId string, users []string) (*Campaign, error) {
+	query := fmt.Sprintf("UPDATE %s SET users=$2 WHERE id=$1 RETURNING %s;", s.table, returnValues)
+	return s.execQuerySingle(query, campaignId, pq.Array(users))
+}
+
+func (s *store) delete(campaignId string) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id=$1;", s.table)
+	s.LogQuery(query, campaignId)
+
+	_, err := s.tx.Exec(query, campaignId)
+	return err
+}
+
+// setCampaignOfProject sets the campaign of the given project. A campaign ID of NULL removes the project from its
+// campaign. The old campaign ID must match, so that a project is never moved from one campaign into another by accident.
+func (s *store) setCampaignOfProject(projectId string, oldCampaignId *string, newCampaignId *string) error {
+	query := fmt.Sprintf("UPDATE %s SET campaign_id=$3 WHERE id=$1 AND campaign_id IS NOT DISTINCT FROM $2;", s.projectTable)
+	s.LogQuery(query, projectId, oldCampaignId, newCampaignId)
+
+	result, err := s.tx.Exec(query, projectId, oldCampaignId, newCampaignId)
+	if err != nil {
+		return errors.Wrapf(err, "could not set campaign of project %s", projectId)
+	}
+
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "could not get number of affected rows")
+	}
+	if affectedRows != 1 {
+		return errors.New(fmt.Sprintf("project %s does not exist or is in a different campaign", projectId))
+	}
+
+	return nil
+}
+
+// execQuerySingle executes the given query and returns the only resulting campaign.
+func (s *store) execQuerySingle(query string, params ...interface{}) (*Campaign, error) {
+	campaigns, err := s.execQuery(query, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(campaigns) != 1 {
+		return nil, errors.New("Campaign does not exist")
+	}
+
+	return campaigns[0], nil
+}
+
+// execQuery executes the given query, turns the result into Campaign objects including their projects and aggregated
+// numbers and closes the query.
+func (s *store) execQuery(query string, params ...interface{}) ([]*Campaign, error) {
+	s.LogQuery(query, params...)
+
+	rows, err := s.tx.Query(query, params...)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not run query")
+	}
+
+	campaigns := make([]*Campaign, 0)
+	for rows.Next() {
+		campaign, err := rowToCampaign(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, "error converting row into campaign")
+		}
+
+		campaigns = append(campaigns, campaign)
+	}
+
+	err = rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.addProjectsToCampaigns(campaigns)
+	if err != nil {
+		return nil, err
+	}
+
+	return campaigns, nil
+}
+
+// addProjectsToCampaigns adds the project IDs and the numbers aggregated over all tasks to the campaigns. This needs one
+// query regardless of the number of campaigns.
+func (s *store) addProjectsToCampaigns(campaigns []*Campaign) error {
+	campaignIds := make([]string, len(campaigns))
+	campaignsById := make(map[string]*Campaign)
+	for i, campaign := range campaigns {
+		campaignIds[i] = campaign.Id
+		campaignsById[campaign.Id] = campaign
+	}
+
+	if len(campaigns) == 0 {
+		return nil
+	}
+
+	rawQueryString := `
+SELECT p.campaign_id, p.id,
+	COUNT(t.id), COUNT(t.id) FILTER (WHERE t.process_points = t.max_process_points),
+	COALESCE(SUM(t.max_process_points), 0), COALESCE(SUM(t.process_points), 0)
+FROM %s p LEFT JOIN %s t ON t.project_id = p.id
+WHERE p.campaign_id = ANY($1)
+GROUP BY p.campaign_id, p.id
+ORDER BY p.id;`
+
+	query := fmt.Sprintf(rawQueryString, s.projectTable, s.taskTable)
+	s.LogQuery(query, campaignIds)
+
+	rows, err := s.tx.Query(query, pq.Array(campaignIds))
+	if err != nil {
+		return errors.Wrap(err, "error executing query")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var campaignId, projectId, taskCount, doneTaskCount, totalProcessPoints, doneProcessPoints int
+
+		err = rows.Scan(&campaignId, &projectId, &taskCount, &doneTaskCount, &totalProcessPoints, &doneProcessPoints)
+		if err != nil {
+			return errors.Wrap(err, "could not scan rows")
+		}
+
+		campaign := campaignsById[strconv.Itoa(campaignId)]
+		campaign.ProjectIds = append(campaign.ProjectIds, strconv.Itoa(projectId))
+		campaign.TaskCount += taskCount
+		campaign.DoneTaskCount += doneTaskCount
+		campaign.TotalProcessPoints += totalProcessPoints
+		campaign.DoneProcessPoints += doneProcessPoints
+	}
+
+	return nil
+}
+
+// rowToCampaign turns the current row into a Campaign object without projects. This does not close the row.
+func rowToCampaign(rows *sql.Rows) (*Campaign, error) {
+	var id int
+	var creationDate *time.Time
+	result := Campaign{
+		ProjectIds: make([]string, 0),
+	}
+
+	err := rows.Scan(&id, &result.Name, &result.Description, &result.Owner, pq.Array(&result.Users), &creationDate)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not scan rows")
+	}
+
+	result.Id = strconv.Itoa(id)
+
+	if creationDate != nil {
+		t := creationDate.UTC()
+		result.CreationDate = &t
+	}
+
+	return &result, nil
+}
@@ -1,5 +1,48 @@
 package comment
 
+import "time"
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 100
+)
+
+const (
+	MaxReportReasonLength = 500
+)
+
+const (
+	MaxAttachmentsPerComment = 5
+	MaxAttachmentNameLength  = 255
+
+	GeoJsonContentType = "application/geo+json"
+)
+
+var (
+	// AllowedImageTypes are the content types of images that can be attached to comments. Besides images, GeoJSON files
+	// are allowed.
+	AllowedImageTypes = []string{"image/png", "image/jpeg", "image/gif", "image/webp"}
+)
+
 type DraftDto struct {
-	Text string `json:"text"` // The text of the comment.
+	Text     string `json:"text"`     // The text of the comment.
+	ParentId string `json:"parentId"` // Optional. The ID of the comment this comment replies to. It must belong to the same comment list.
+}
+
+type PageFilterDto struct {
+	Cursor   string     // When not empty, only threads after the thread with this ID are returned. This is the "nextCursor" of the previous page.
+	PageSize int        // Maximum number of threads per page.
+	Since    *time.Time // When not NULL, only threads containing a comment created or edited after this time are returned.
+}
+
+type IssueStatusDto struct {
+	Status IssueStatus `json:"status"` // One of "NONE", "OPEN" and "RESOLVED".
+}
+
+type ReportDto struct {
+	Reason string `json:"reason"` // Optional. Why the comment is inappropriate.
+}
+
+type HiddenDto struct {
+	Hidden bool `json:"hidden"` // True to hide the comment, false to show it again.
 }
@@ -2,9 +2,62 @@ package comment
 
 import "time"
 
+type IssueStatus string
+
+const (
+	IssueNone     IssueStatus = "NONE"     // A normal comment and no issue.
+	IssueOpen     IssueStatus = "OPEN"     // An issue that hasn't been addressed yet.
+	IssueResolved IssueStatus = "RESOLVED" // An issue that has been addressed.
+)
+
 type Comment struct {
-	Id           string     `json:"id"`           // The ID of the task.
-	Text         string     `json:"text"`         // The name of the task. If the properties of the geometry feature contain the field "name", this field is used here. If no name has been set, this field will be empty.
-	AuthorId     string     `json:"authorId"`     // The user-ID of the user who is currently assigned to this task. Will never be NULL but might be empty.
-	CreationDate *time.Time `json:"creationDate"` // The time this comment was created at.
+	Id           string       `json:"id"`                 // The ID of the task.
+	Text         string       `json:"text"`               // The name of the task. If the properties of the geometry feature contain the field "name", this field is used here. If no name has been set, this field will be empty.
+	Html         string       `json:"html"`               // The sanitised HTML of the Markdown text, see util.RenderMarkdown.
+	AuthorId     string       `json:"authorId"`           // The user-ID of the user who is currently assigned to this task. Will never be NULL but might be empty.
+	CreationDate *time.Time   `json:"creationDate"`       // The time this comment was created at.
+	LastEditDate *time.Time   `json:"lastEditDate"`       // The time this comment was edited the last time. NULL when the comment has never been edited.
+	ParentId     string       `json:"parentId"`           // The ID of the comment this comment replies to. Empty for top-level comments.
+	Replies      []Comment    `json:"replies"`            // The replies to this comment, the oldest first. Will never be NULL but might be empty.
+	Mentions     []string     `json:"mentions"`           // The user-IDs of the project members mentioned via "@<user>" in the text. Will never be NULL but might be empty.
+	IssueStatus  IssueStatus  `json:"issueStatus"`        // One of "NONE", "OPEN" and "RESOLVED". Comments can be used as issues, which are resolved when they have been addressed.
+	Attachments  []Attachment `json:"attachments"`        // The files attached to this comment. Will never be NULL but might be empty.
+	Hidden       bool         `json:"hidden"`             // True when the owner of the project hid this comment. The text, mentions and attachments of hidden comments are empty.
+	Warnings     []string     `json:"warnings,omitempty"` // Problems which didn't prevent storing the comment, e.g. mentions of users who aren't members. Only set in the response of adding or editing the comment.
+}
+
+// Attachment is a file attached to a comment. The content is available via the attachment endpoint.
+type Attachment struct {
+	Id          string `json:"id"`          // The ID of the attachment.
+	CommentId   string `json:"commentId"`   // The ID of the comment this file is attached to.
+	FileName    string `json:"fileName"`    // The name of the uploaded file.
+	ContentType string `json:"contentType"` // One of the allowed types, e.g. "image/png" or "application/geo+json".
+	Size        int    `json:"size"`        // The size of the file in bytes.
+
+	storageName string // Name of the file in the attachment directory.
+}
+
+// Report is the report of a member about an inappropriate comment. It contains the original content of the comment even
+// when the comment has been hidden.
+type Report struct {
+	Id              string     `json:"id"`              // The ID of the report.
+	CommentId       string     `json:"commentId"`       // The ID of the reported comment.
+	CommentText     string     `json:"commentText"`     // The text of the reported comment.
+	CommentAuthorId string     `json:"commentAuthorId"` // The user-ID of the author of the reported comment.
+	CommentHidden   bool       `json:"commentHidden"`   // True when the reported comment has already been hidden.
+	ReporterId      string     `json:"reporterId"`      // The user-ID of the member who reported the comment.
+	Reason          string     `json:"reason"`          // The reason given by the reporter. Might be empty.
+	CreationDate    *time.Time `json:"creationDate"`    // The time the comment was reported at.
+}
+
+// Edit is a previous version of a comment.
+type Edit struct {
+	Text     string     `json:"text"`     // The text of the comment before the edit.
+	EditDate *time.Time `json:"editDate"` // The time this version has been replaced by a newer one.
+}
+
+// Page is one page of the threads of a comment list.
+type Page struct {
+	Comments   []Comment `json:"comments"`   // The top-level comments with their nested replies, the oldest first. Will not be NULL but might be empty.
+	NextCursor string    `json:"nextCursor"` // The cursor to get the next page. Empty when this is the last page.
 }
@@ -1,11 +1,23 @@
 package comment
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/pkg/errors"
+	"net/http"
+	"os"
+	"path/filepath"
 	"stm/config"
 	"stm/util"
+	"strconv"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	geoJsonTypes = []string{"FeatureCollection", "Feature", "Point", "MultiPoint", "LineString", "MultiLineString", "Polygon", "MultiPolygon", "GeometryCollection"}
 )
 
 type Service struct {
@@ -20,10 +32,508 @@ func Init(logger *util.Logger, store *Store) *Service {
 	}
 }
 
-func (s *Service) AddComment(listId string, commentDraft *DraftDto, authorId string) error {
-	if len(commentDraft.Text) > config.Conf.MaxCommentLength {
-		return errors.New(fmt.Sprintf("Comment too long. Allowed are %d characters but found %d.", config.Conf.MaxCommentLength, len(commentDraft.Text)))
+// AddComment adds a new comment to the list. When the draft contains a parent ID, the new comment is a reply to this
+// parent comment, which must belong to the same list. Mentions of users who aren't one of the given members of the
+// project are ignored and returned as warnings of the comment. The author must not be one of the given read-only users
+// of the project.
+func (s *Service) AddComment(listId string, commentDraft *DraftDto, authorId string, members []string, readOnlyUsers []string) (*Comment, error) {
+	if contains(readOnlyUsers, authorId) {
+		return nil, errors.New(fmt.Sprintf("User %s is in read-only mode and not allowed to write comments", authorId))
+	}
+
+	err := validateDraft(commentDraft)
+	if err != nil {
+		return nil, err
+	}
+
+	mentions, unknownMentions := getMentions(commentDraft.Text, members)
+
+	if commentDraft.ParentId != "" {
+		parentListId, err := s.store.getCommentListIdOfComment(commentDraft.ParentId)
+		if err != nil {
+			return nil, err
+		}
+
+		if parentListId != listId {
+			return nil, errors.New(fmt.Sprintf("parent comment %s does not belong to comment list %s", commentDraft.ParentId, listId))
+		}
+	}
+
+	comment, err := s.store.addComment(listId, commentDraft.Text, authorId, commentDraft.ParentId, mentions, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	comment.Warnings = toMentionWarnings(unknownMentions)
+	return comment, nil
+}
+
+// ImportThreads adds the given threads to the list and keeps the authors, dates, mentions and states of all comments.
+// The IDs of the given comments are ignored, the replies are added to the new IDs of their parent comments. This is
+// meant to restore comments from an export and therefore doesn't check the mentions or read-only users.
+func (s *Service) ImportThreads(listId string, threads []Comment) error {
+	return s.importThreads(listId, threads, "")
+}
+
+func (s *Service) importThreads(listId string, threads []Comment, parentId string) error {
+	for _, c := range threads {
+		err := validateDraft(&DraftDto{Text: c.Text})
+		if err != nil {
+			return err
+		}
+
+		if c.AuthorId == "" || c.CreationDate == nil {
+			return errors.New(fmt.Sprintf("Imported comment %s must have an author and a creation date", c.Id))
+		}
+
+		if c.IssueStatus == "" {
+			c.IssueStatus = IssueNone
+		}
+		if c.IssueStatus != IssueNone && c.IssueStatus != IssueOpen && c.IssueStatus != IssueResolved {
+			return errors.New(fmt.Sprintf("Unknown issue status '%s' of imported comment %s", c.IssueStatus, c.Id))
+		}
+
+		if c.Mentions == nil {
+			c.Mentions = make([]string, 0)
+		}
+
+		importedComment, err := s.store.importComment(listId, &c, parentId)
+		if err != nil {
+			return err
+		}
+
+		err = s.importThreads(listId, c.Replies, importedComment.Id)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UpdateComment replaces the text of the comment and keeps the previous text in the edit history. Mentions of users who
+// aren't one of the given members of the project are ignored and returned as warnings of the comment. This doesn't
+// check any permissions, the caller has to make sure the requesting user is allowed to edit the comment.
+func (s *Service) UpdateComment(commentId string, commentDraft *DraftDto, members []string) (*Comment, error) {
+	err := validateDraft(commentDraft)
+	if err != nil {
+		return nil, err
+	}
+
+	mentions, unknownMentions := getMentions(commentDraft.Text, members)
+
+	comment, err := s.store.updateComment(commentId, commentDraft.Text, mentions, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	s.Log("Updated comment %s", commentId)
+
+	comment.Warnings = toMentionWarnings(unknownMentions)
+	return comment, nil
+}
+
+// SetIssueStatus marks the comment as open or resolved issue or as normal comment. This doesn't check any permissions,
+// the caller has to make sure the requesting user is allowed to change the status.
+func (s *Service) SetIssueStatus(commentId string, status IssueStatus) (*Comment, error) {
+	if status != IssueNone && status != IssueOpen && status != IssueResolved {
+		return nil, errors.New(fmt.Sprintf("Unknown issue status '%s'", status))
+	}
+
+	comment, err := s.store.updateIssueStatus(commentId, status)
+	if err != nil {
+		return nil, err
+	}
+	s.Log("Set issue status of comment %s to %s", commentId, status)
+
+	return comment, nil
+}
+
+// IsHidden returns true when the owner of the project hid the comment.
+func (s *Service) IsHidden(commentId string) (bool, error) {
+	comment, err := s.store.getComment(commentId)
+	if err != nil {
+		return false, err
+	}
+
+	return comment.Hidden, nil
+}
+
+// RevealHidden adds the text, HTML and mentions of all hidden comments (incl. replies) in the given threads. This
+// doesn't check any permissions, the caller has to make sure the requesting user is allowed to see hidden comments.
+func (s *Service) RevealHidden(threads []Comment) error {
+	rawComments, err := s.store.getRawComments(getHiddenCommentIds(threads))
+	if err != nil {
+		return err
+	}
+
+	revealHidden(threads, rawComments)
+	return nil
+}
+
+func getHiddenCommentIds(threads []Comment) []string {
+	commentIds := make([]string, 0)
+	for _, c := range threads {
+		if c.Hidden {
+			commentIds = append(commentIds, c.Id)
+		}
+		commentIds = append(commentIds, getHiddenCommentIds(c.Replies)...)
+	}
+
+	return commentIds
+}
+
+func revealHidden(threads []Comment, rawComments map[string]*Comment) {
+	for i, c := range threads {
+		if raw, ok := rawComments[c.Id]; ok {
+			threads[i].Text = raw.Text
+			threads[i].Html = raw.Html
+			threads[i].Mentions = raw.Mentions
+		}
+		revealHidden(c.Replies, rawComments)
+	}
+}
+
+// SetHidden hides the comment or shows it again. This doesn't check any permissions, the caller has to make sure the
+// requesting user is allowed to moderate the comment.
+func (s *Service) SetHidden(commentId string, hidden bool) (*Comment, error) {
+	comment, err := s.store.updateHidden(commentId, hidden)
+	if err != nil {
+		return nil, err
+	}
+	s.Log("Set hidden flag of comment %s to %t", commentId, hidden)
+
+	return comment, nil
+}
+
+// ReportComment stores the report of the user about the comment. This doesn't check any permissions, the caller has to
+// make sure the reporter is allowed to see the comment.
+func (s *Service) ReportComment(commentId string, reportDto *ReportDto, reporterId string) error {
+	reason := strings.TrimSpace(reportDto.Reason)
+	if utf8.RuneCountInString(reason) > MaxReportReasonLength {
+		return errors.New(fmt.Sprintf("Reason too long. Maximum allowed are %d characters but found %d.", MaxReportReasonLength, utf8.RuneCountInString(reason)))
+	}
+
+	err := s.store.addReport(commentId, reporterId, reason, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	s.Log("User %s reported comment %s", reporterId, commentId)
+
+	return nil
+}
+
+// DismissReports removes all reports of the comment, e.g. because they turned out to be unjustified.
+func (s *Service) DismissReports(commentId string) error {
+	err := s.store.deleteReports(commentId)
+	if err != nil {
+		return err
+	}
+	s.Log("Dismissed reports of comment %s", commentId)
+
+	return nil
+}
+
+// GetReports returns the reports of all comments in the given comment lists, the oldest first.
+func (s *Service) GetReports(listIds []string) ([]Report, error) {
+	return s.store.getReportsOfLists(listIds)
+}
+
+// CountOpenIssues returns the number of open issues in the given threads including all replies.
+func CountOpenIssues(threads []Comment) int {
+	count := 0
+	for _, c := range threads {
+		if c.IssueStatus == IssueOpen {
+			count++
+		}
+		count += CountOpenIssues(c.Replies)
+	}
+
+	return count
+}
+
+// DeleteComment removes the comment, its edit history and its attachments. The files of the attachments are kept until
+// RemoveOrphanedAttachmentFiles runs, so that they still exist when the transaction is rolled back. This doesn't check
+// any permissions, the caller has to make sure the requesting user is allowed to delete the comment.
+func (s *Service) DeleteComment(commentId string) error {
+	err := s.store.deleteComment(commentId)
+	if err != nil {
+		return err
+	}
+	s.Log("Deleted comment %s", commentId)
+
+	return nil
+}
+
+// AddAttachment stores the file in the attachment directory and attaches it to the comment. Only images and GeoJSON
+// files up to the configured size are allowed. When the transaction is rolled back, the stored file is removed by
+// RemoveOrphanedAttachmentFiles later on. This doesn't check any permissions, the caller has to make sure the
+// requesting user is allowed to change the comment.
+func (s *Service) AddAttachment(commentId string, fileName string, content []byte) (*Attachment, error) {
+	if len(content) == 0 {
+		return nil, errors.New("Attachment is empty")
+	}
+	if len(content) > config.Conf.MaxAttachmentSize {
+		return nil, errors.New(fmt.Sprintf("Attachment too large. Allowed are %d bytes but found %d.", config.Conf.MaxAttachmentSize, len(content)))
+	}
+
+	fileName = strings.TrimSpace(filepath.Base(filepath.Clean("/" + fileName)))
+	if fileName == "" || fileName == "/" || utf8.RuneCountInString(fileName) > MaxAttachmentNameLength {
+		return nil, errors.New(fmt.Sprintf("Invalid file name '%s'", fileName))
 	}
 
-	return s.store.addComment(listId, commentDraft.Text, authorId, time.Now().UTC())
+	contentType, err := getAttachmentType(fileName, content)
+	if err != nil {
+		return nil, err
+	}
+
+	existingAttachments, err := s.store.getAttachmentsOfComment(commentId)
+	if err != nil {
+		return nil, err
+	}
+	if len(existingAttachments) >= MaxAttachmentsPerComment {
+		return nil, errors.New(fmt.Sprintf("Comment %s already has the maximum number of %d attachments", commentId, MaxAttachmentsPerComment))
+	}
+
+	storageName, err := util.GetRandomString()
+	if err != nil {
+		return nil, err
+	}
+
+	attachment, err := s.store.addAttachment(commentId, fileName, contentType, len(content), storageName, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.MkdirAll(config.Conf.AttachmentDirectory, 0750)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create attachment directory")
+	}
+
+	err = os.WriteFile(attachmentPath(attachment), content, 0640)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not store file of attachment %s", attachment.Id)
+	}
+	s.Log("Added attachment %s with %d bytes to comment %s", attachment.Id, len(content), commentId)
+
+	return attachment, nil
+}
+
+// GetAttachment returns the attachment with its content. This doesn't check any permissions, the caller has to make
+// sure the requesting user is allowed to see the comment of the attachment.
+func (s *Service) GetAttachment(attachmentId string) (*Attachment, []byte, error) {
+	attachment, err := s.store.getAttachment(attachmentId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := os.ReadFile(attachmentPath(attachment))
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "could not read file of attachment %s", attachmentId)
+	}
+
+	return attachment, content, nil
+}
+
+// GetAttachmentMetadata returns the attachment without reading its content.
+func (s *Service) GetAttachmentMetadata(attachmentId string) (*Attachment, error) {
+	return s.store.getAttachment(attachmentId)
+}
+
+// RemoveOrphanedAttachmentFiles removes all files of the attachment directory that don't belong to any attachment.
+// These remain when comments are deleted (also together with their project or task) or when a transaction with a new
+// attachment is rolled back. Files younger than the given age are kept, since their attachment might belong to a
+// transaction that hasn't been committed yet. This is not bound to any user and therefore only meant to be used for
+// regular maintenance.
+func (s *Service) RemoveOrphanedAttachmentFiles(minAge time.Duration) error {
+	entries, err := os.ReadDir(config.Conf.AttachmentDirectory)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "could not read attachment directory")
+	}
+
+	// Read after listing the files, so that files of attachments committed in the meantime are known
+	storageNames, err := s.store.getAttachmentStorageNames()
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || storageNames[entry.Name()] {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) < minAge {
+			continue
+		}
+
+		err = os.Remove(filepath.Join(config.Conf.AttachmentDirectory, entry.Name()))
+		if err != nil && !os.IsNotExist(err) {
+			s.Err("Unable to remove orphaned attachment file %s: %s", entry.Name(), err)
+			continue
+		}
+		s.Log("Removed orphaned attachment file %s", entry.Name())
+	}
+
+	return nil
+}
+
+func attachmentPath(attachment *Attachment) string {
+	return filepath.Join(config.Conf.AttachmentDirectory, attachment.storageName)
+}
+
+// getAttachmentType determines the content type by the content itself and not by the information of the client.
+// Images are detected by their content, GeoJSON files by their extension and a valid GeoJSON "type".
+func getAttachmentType(fileName string, content []byte) (string, error) {
+	contentType := http.DetectContentType(content)
+	for _, allowedType := range AllowedImageTypes {
+		if contentType == allowedType {
+			return contentType, nil
+		}
+	}
+
+	extension := strings.ToLower(filepath.Ext(fileName))
+	if extension == ".geojson" || extension == ".json" {
+		var geoJsonObject struct {
+			Type string `json:"type"`
+		}
+		err := json.Unmarshal(content, &geoJsonObject)
+		if err == nil && contains(geoJsonTypes, geoJsonObject.Type) {
+			return GeoJsonContentType, nil
+		}
+	}
+
+	return "", errors.New(fmt.Sprintf("Type of file '%s' not allowed. Only images (%s) and GeoJSON files are allowed.", fileName, strings.Join(AllowedImageTypes, ", ")))
+}
+
+// GetCommentPage returns one page of the threads of the comment list, the oldest first. This doesn't check any
+// permissions.
+func (s *Service) GetCommentPage(listId string, filter *PageFilterDto) (*Page, error) {
+	if filter.PageSize < 1 || filter.PageSize > MaxPageSize {
+		return nil, errors.New(fmt.Sprintf("Page size must be between 1 and %d (%d)", MaxPageSize, filter.PageSize))
+	}
+
+	afterId := 0
+	if filter.Cursor != "" {
+		var err error
+		afterId, err = strconv.Atoi(filter.Cursor)
+		if err != nil || afterId < 0 {
+			return nil, errors.New(fmt.Sprintf("Invalid cursor '%s'", filter.Cursor))
+		}
+	}
+
+	// Load one additional thread to know whether there's a next page
+	threads, err := s.store.getThreads(listId, afterId, filter.Since, filter.PageSize+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page{
+		Comments: threads,
+	}
+	if len(threads) > filter.PageSize {
+		page.Comments = threads[:filter.PageSize]
+		page.NextCursor = page.Comments[filter.PageSize-1].Id
+	}
+
+	return page, nil
+}
+
+// GetEdits returns the previous versions of the comment, the oldest first. This doesn't check any permissions.
+func (s *Service) GetEdits(commentId string) ([]Edit, error) {
+	return s.store.getEdits(commentId)
+}
+
+func validateDraft(commentDraft *DraftDto) error {
+	if utf8.RuneCountInString(commentDraft.Text) > config.Conf.MaxCommentLength {
+		return errors.New(fmt.Sprintf("Comment too long. Allowed are %d characters but found %d.", config.Conf.MaxCommentLength, utf8.RuneCountInString(commentDraft.Text)))
+	}
+
+	return nil
+}
+
+// getMentions returns the members mentioned in the text, each member only once, and everything else that looks like a
+// mention (e.g. "@all" or a display name) as unknown mentions. A mention is an "@" at the beginning of the text or after
+// a whitespace, directly followed by the user-ID of a member, which must not be followed by a letter, digit, "_" or "-".
+// The user-IDs are compared as plain prefixes of the text after the "@" and the longest matching one is used, so for
+// "@12345" the member "12345" is mentioned and not the member "123".
+//
+// Members are identified by their user-ID and the server doesn't know the display names of the users.
+// Clients therefore have to resolve a display name to the user-ID of the member (e.g. via an autocompletion of the
+// members) and insert "@<user-ID>" into the text. The display name can be shown again when rendering the comment.
+func getMentions(text string, members []string) ([]string, []string) {
+	mentions := make([]string, 0)
+	unknownMentions := make([]string, 0)
+
+	for i, r := range text {
+		if r != '@' || (i > 0 && !unicode.IsSpace(lastRune(text[:i]))) {
+			continue
+		}
+
+		rest := text[i+1:]
+		if rest == "" || unicode.IsSpace(firstRune(rest)) {
+			// A single "@" is no mention
+			continue
+		}
+
+		mentionedMember := ""
+		for _, member := range members {
+			if len(member) > len(mentionedMember) && strings.HasPrefix(rest, member) && !isUserIdRune(firstRune(rest[len(member):])) {
+				mentionedMember = member
+			}
+		}
+
+		if mentionedMember == "" {
+			unknownUser := strings.TrimRightFunc(strings.Fields(rest)[0], unicode.IsPunct)
+			if unknownUser == "" {
+				// Only punctuation like "@!" is no mention
+				continue
+			}
+			if !contains(unknownMentions, unknownUser) {
+				unknownMentions = append(unknownMentions, unknownUser)
+			}
+			continue
+		}
+
+		if !contains(mentions, mentionedMember) {
+			mentions = append(mentions, mentionedMember)
+		}
+	}
+
+	return mentions, unknownMentions
+}
+
+func toMentionWarnings(unknownMentions []string) []string {
+	warnings := make([]string, len(unknownMentions))
+	for i, unknownMention := range unknownMentions {
+		warnings[i] = fmt.Sprintf("Mentioned user '%s' is not a member of the project and has not been notified", unknownMention)
+	}
+	return warnings
+}
+
+// isUserIdRune returns true when the rune could continue a user-ID, so that "@Maria" isn't a mention of "Mari".
+func isUserIdRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-'
+}
+
+func contains(users []string, user string) bool {
+	for _, u := range users {
+		if u == user {
+			return true
+		}
+	}
+
+	return false
+}
+
+func firstRune(text string) rune {
+	r, _ := utf8.DecodeRuneInString(text)
+	return r
+}
+
+func lastRune(text string) rune {
+	r, _ := utf8.DecodeLastRuneInString(text)
+	return r
 }
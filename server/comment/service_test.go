@@ -3,10 +3,15 @@ package comment
 import (
 	"database/sql"
 	"github.com/hauke96/sigolo"
+	"github.com/pkg/errors"
+	"os"
+	"path/filepath"
 	"stm/config"
 	"stm/test"
 	"stm/util"
+	"strings"
 	"testing"
+	"time"
 )
 
 var (
@@ -53,3 +58,189 @@ func setup() {
 //		return nil
 //	})
 //}
+
+func TestToThreads(t *testing.T) {
+	comments := []Comment{
+		{Id: "1"},
+		{Id: "2", ParentId: "1"},
+		{Id: "3"},
+		{Id: "4", ParentId: "2"},
+		{Id: "5", ParentId: "1"},
+	}
+
+	threads := toThreads(comments)
+
+	if len(threads) != 2 || threads[0].Id != "1" || threads[1].Id != "3" {
+		t.Fatalf("Wrong top-level comments: %#v", threads)
+	}
+	if len(threads[0].Replies) != 2 || threads[0].Replies[0].Id != "2" || threads[0].Replies[1].Id != "5" {
+		t.Errorf("Wrong replies of comment 1: %#v", threads[0].Replies)
+	}
+	if len(threads[0].Replies[0].Replies) != 1 || threads[0].Replies[0].Replies[0].Id != "4" {
+		t.Errorf("Wrong replies of comment 2: %#v", threads[0].Replies[0].Replies)
+	}
+	if threads[1].Replies == nil || len(threads[1].Replies) != 0 {
+		t.Errorf("Comment 3 should have an empty list of replies: %#v", threads[1].Replies)
+	}
+}
+
+func TestGetMentions(t *testing.T) {
+	members := []string{"Maria", "Mari", "Hans Meier", "John", "123", "12345"}
+
+	cases := []struct {
+		text     string
+		expected []string
+	}{
+		{"No mention at all", []string{}},
+		{"@Maria please fix the roads", []string{"Maria"}},
+		{"@Mari and @Maria, see @Maria's comment", []string{"Mari", "Maria"}},
+		{"Thanks @Hans Meier!", []string{"Hans Meier"}},
+		{"Write to john@example.com or @ me", []string{}},
+		{"Only punctuation @! is fine", []string{}},
+		{"@John\n@Maria", []string{"John", "Maria"}},
+		{"@12345 and @123", []string{"12345", "123"}},
+	}
+
+	for i, c := range cases {
+		mentions, unknownMentions := getMentions(c.text, members)
+		if len(unknownMentions) != 0 {
+			t.Errorf("Case %d: unexpected unknown mentions %v", i, unknownMentions)
+			continue
+		}
+
+		if len(mentions) != len(c.expected) {
+			t.Errorf("Case %d: expected mentions %v but got %v", i, c.expected, mentions)
+			continue
+		}
+		for j := range mentions {
+			if mentions[j] != c.expected[j] {
+				t.Errorf("Case %d: expected mentions %v but got %v", i, c.expected, mentions)
+			}
+		}
+	}
+
+	unknownCases := []struct {
+		text            string
+		expected        []string
+		expectedUnknown []string
+	}{
+		{"@Peter please fix this", []string{}, []string{"Peter"}},
+		{"Hey @Marianne!", []string{}, []string{"Marianne"}},
+		{"@all @John @all", []string{"John"}, []string{"all"}},
+		{"@Hans", []string{}, []string{"Hans"}},
+	}
+
+	for i, c := range unknownCases {
+		mentions, unknownMentions := getMentions(c.text, members)
+		if strings.Join(mentions, ",") != strings.Join(c.expected, ",") || strings.Join(unknownMentions, ",") != strings.Join(c.expectedUnknown, ",") {
+			t.Errorf("Unknown case %d: expected mentions %v and unknown mentions %v but got %v and %v", i, c.expected, c.expectedUnknown, mentions, unknownMentions)
+		}
+	}
+}
+
+func TestCountOpenIssues(t *testing.T) {
+	threads := []Comment{
+		{Id: "1", IssueStatus: IssueOpen, Replies: []Comment{
+			{Id: "2", IssueStatus: IssueResolved},
+			{Id: "3", IssueStatus: IssueOpen, Replies: []Comment{{Id: "4", IssueStatus: IssueOpen}}},
+		}},
+		{Id: "5", IssueStatus: IssueNone},
+	}
+
+	count := CountOpenIssues(threads)
+	if count != 3 {
+		t.Errorf("Expected 3 open issues but found %d", count)
+	}
+}
+
+func TestGetAttachmentType(t *testing.T) {
+	png := []byte("\x89PNG\x0D\x0A\x1A\x0A\x00\x00\x00\x0DIHDR")
+	geoJson := []byte(`{"type": "FeatureCollection", "features": []}`)
+
+	cases := []struct {
+		fileName string
+		content  []byte
+		expected string
+	}{
+		{"image.png", png, "image/png"},
+		{"image.txt", png, "image/png"},
+		{"roads.geojson", geoJson, GeoJsonContentType},
+		{"roads.JSON", geoJson, GeoJsonContentType},
+	}
+
+	for i, c := range cases {
+		contentType, err := getAttachmentType(c.fileName, c.content)
+		if err != nil {
+			t.Errorf("Case %d: unexpected error: %s", i, err)
+			continue
+		}
+		if contentType != c.expected {
+			t.Errorf("Case %d: expected type %s but got %s", i, c.expected, contentType)
+		}
+	}
+
+	invalidCases := []struct {
+		fileName string
+		content  []byte
+	}{
+		{"page.html", []byte("<html><script>alert(1)</script></html>")},
+		{"roads.txt", geoJson},
+		{"roads.geojson", []byte(`{"type": "Something"}`)},
+		{"roads.geojson", []byte("not json")},
+		{"document.pdf", []byte("%PDF-1.4")},
+	}
+
+	for i, c := range invalidCases {
+		_, err := getAttachmentType(c.fileName, c.content)
+		if err == nil {
+			t.Errorf("Invalid case %d: file '%s' should not be accepted", i, c.fileName)
+		}
+	}
+}
+
+func TestRemoveOrphanedAttachmentFiles(t *testing.T) {
+	h.Run(t, func() error {
+		config.Conf.AttachmentDirectory = t.TempDir()
+
+		attachment, err := s.AddAttachment("1", "point.geojson", []byte(`{"type": "Point", "coordinates": [9.9, 53.5]}`))
+		if err != nil {
+			return err
+		}
+		oldDate := time.Now().Add(-2 * time.Hour)
+		err = os.Chtimes(attachmentPath(attachment), oldDate, oldDate)
+		if err != nil {
+			return err
+		}
+
+		// Files of deleted or rolled back attachments, one of them from a possibly still running transaction
+		orphanedFile := filepath.Join(config.Conf.AttachmentDirectory, "orphaned")
+		recentFile := filepath.Join(config.Conf.AttachmentDirectory, "recent")
+		for _, file := range []string{orphanedFile, recentFile} {
+			err = os.WriteFile(file, []byte("foo"), 0640)
+			if err != nil {
+				return err
+			}
+		}
+		err = os.Chtimes(orphanedFile, oldDate, oldDate)
+		if err != nil {
+			return err
+		}
+
+		err = s.RemoveOrphanedAttachmentFiles(time.Hour)
+		if err != nil {
+			return err
+		}
+
+		if _, err = os.Stat(orphanedFile); !os.IsNotExist(err) {
+			return errors.New("Orphaned file should be removed")
+		}
+		if _, err = os.Stat(recentFile); err != nil {
+			return errors.New("Recent file should be kept")
+		}
+		if _, err = os.Stat(attachmentPath(attachment)); err != nil {
+			return errors.New("File of existing attachment should be kept")
+		}
+
+		return nil
+	})
+}
@@ -3,6 +3,7 @@ package comment
 import (
 	"database/sql"
 	"fmt"
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 	"stm/util"
 	"strconv"
@@ -15,13 +16,26 @@ type commentRow struct {
 	text          string
 	authorId      string
 	creationDate  *time.Time
+	lastEditDate  *time.Time
+	parentId      *int
+	mentions      []string
+	issueStatus   IssueStatus
+	hidden        bool
 }
 
+var (
+	returnValues           = "id, comment_list_id, text, author_id, creation_date, last_edit_date, parent_id, mentions, issue_status, hidden"
+	attachmentReturnValues = "id, comment_id, file_name, content_type, size, storage_name"
+)
+
 type Store struct {
 	*util.Logger
 	tx               *sql.Tx
 	commentListTable string
 	commentTable     string
+	commentEditTable string
+	attachmentTable  string
+	reportTable      string
 }
 
 func GetStore(tx *sql.Tx, logger *util.Logger) *Store {
@@ -30,21 +44,143 @@ func GetStore(tx *sql.Tx, logger *util.Logger) *Store {
 		tx:               tx,
 		commentListTable: "comment_lists",
 		commentTable:     "comments",
+		commentEditTable: "comment_edits",
+		attachmentTable:  "comment_attachments",
+		reportTable:      "comment_reports",
 	}
 }
 
 func (s *Store) GetComments(listId string) ([]Comment, error) {
-	rawQueryString := `
-SELECT comment.*
-FROM %s comment_list, %s comment
-WHERE
-	comment_list.id = $1 AND
-	comment_list.id = comment.comment_list_id;`
+	commentsOfLists, err := s.GetCommentsOfLists([]string{listId})
+	if err != nil {
+		return nil, err
+	}
+
+	return commentsOfLists[listId], nil
+}
+
+// GetCommentsOfLists loads the comments of all given comment lists with one single query. The returned map contains an
+// entry (which might be an empty list) for each given list ID. The comments of each list are threads, which means only
+// top-level comments are in the list and the replies are nested into their parent comments.
+func (s *Store) GetCommentsOfLists(listIds []string) (map[string][]Comment, error) {
+	return s.GetLatestThreadsOfLists(listIds, -1)
+}
+
+// GetLatestThreadsOfLists is like GetCommentsOfLists but only loads the latest "maxThreads" threads (including all
+// their replies) of each comment list. The threads are still ordered from the oldest to the latest one. A negative
+// number loads all threads.
+func (s *Store) GetLatestThreadsOfLists(listIds []string, maxThreads int) (map[string][]Comment, error) {
+	commentsOfLists := make(map[string][]Comment)
+	for _, listId := range listIds {
+		commentsOfLists[listId] = make([]Comment, 0)
+	}
+
+	if len(listIds) == 0 {
+		return commentsOfLists, nil
+	}
+
+	var rows *sql.Rows
+	var err error
+	if maxThreads < 0 {
+		query := fmt.Sprintf("SELECT %s FROM %s WHERE comment_list_id = ANY($1) ORDER BY id;", returnValues, s.commentTable)
+		s.LogQuery(query, listIds)
+
+		rows, err = s.tx.Query(query, pq.Array(listIds))
+	} else {
+		query := fmt.Sprintf(`WITH RECURSIVE roots AS (
+	SELECT id FROM (
+		SELECT id, ROW_NUMBER() OVER (PARTITION BY comment_list_id ORDER BY id DESC) AS thread_number FROM %s WHERE comment_list_id = ANY($1) AND parent_id IS NULL
+	) r WHERE thread_number <= $2
+), thread AS (
+	SELECT c.* FROM %s c WHERE c.id IN (SELECT id FROM roots)
+	UNION ALL
+	SELECT c.* FROM %s c JOIN thread t ON c.parent_id = t.id
+)
+SELECT %s FROM thread ORDER BY id;`, s.commentTable, s.commentTable, s.commentTable, returnValues)
+		s.LogQuery(query, listIds, maxThreads)
+
+		rows, err = s.tx.Query(query, pq.Array(listIds), maxThreads)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "error executing query")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		comment, row, err := rowToComment(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, "error converting row into comment")
+		}
+
+		listId := strconv.Itoa(row.commentListId)
+		commentsOfLists[listId] = append(commentsOfLists[listId], *comment)
+	}
+
+	for listId, comments := range commentsOfLists {
+		err = s.addAttachments(comments)
+		if err != nil {
+			return nil, err
+		}
+
+		commentsOfLists[listId] = toThreads(comments)
+	}
+
+	return commentsOfLists, nil
+}
+
+// CountOpenIssuesOfLists returns the number of open issues (including replies) of each given comment list. The returned
+// map contains an entry for each given list ID.
+func (s *Store) CountOpenIssuesOfLists(listIds []string) (map[string]int, error) {
+	countsOfLists := make(map[string]int)
+	for _, listId := range listIds {
+		countsOfLists[listId] = 0
+	}
+
+	if len(listIds) == 0 {
+		return countsOfLists, nil
+	}
+
+	query := fmt.Sprintf("SELECT comment_list_id, COUNT(*) FROM %s WHERE comment_list_id = ANY($1) AND issue_status = $2 GROUP BY comment_list_id;", s.commentTable)
+	s.LogQuery(query, listIds, IssueOpen)
+
+	rows, err := s.tx.Query(query, pq.Array(listIds), IssueOpen)
+	if err != nil {
+		return nil, errors.Wrap(err, "error executing query")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var listId, count int
+		err = rows.Scan(&listId, &count)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not scan rows")
+		}
+
+		countsOfLists[strconv.Itoa(listId)] = count
+	}
+
+	return countsOfLists, nil
+}
 
-	query := fmt.Sprintf(rawQueryString, s.commentListTable, s.commentTable)
-	s.LogQuery(query, listId)
+// getThreads returns at most "limit" threads of the comment list whose top-level comment has an ID larger than
+// "afterId". When "since" is not NULL, only threads with at least one comment created or edited after this time are
+// returned. The threads are ordered by the ID of their top-level comment.
+func (s *Store) getThreads(listId string, afterId int, since *time.Time, limit int) ([]Comment, error) {
+	query := fmt.Sprintf(`WITH RECURSIVE thread AS (
+	SELECT c.*, c.id AS root_id FROM %s c WHERE c.comment_list_id = $1 AND c.parent_id IS NULL
+	UNION ALL
+	SELECT c.*, t.root_id FROM %s c JOIN thread t ON c.parent_id = t.id
+), roots AS (
+	SELECT root_id FROM thread
+	GROUP BY root_id
+	HAVING root_id > $2 AND ($3::TIMESTAMP IS NULL OR MAX(GREATEST(creation_date, last_edit_date)) > $3::TIMESTAMP)
+	ORDER BY root_id
+	LIMIT $4
+)
+SELECT %s FROM thread WHERE root_id IN (SELECT root_id FROM roots) ORDER BY id;`, s.commentTable, s.commentTable, returnValues)
+	s.LogQuery(query, listId, afterId, since, limit)
 
-	rows, err := s.tx.Query(query, listId)
+	rows, err := s.tx.Query(query, listId, afterId, since, limit)
 	if err != nil {
 		return nil, errors.Wrap(err, "error executing query")
 	}
@@ -52,7 +188,7 @@ WHERE
 
 	comments := make([]Comment, 0)
 	for rows.Next() {
-		comment, err := rowToComment(rows)
+		comment, _, err := rowToComment(rows)
 		if err != nil {
 			return nil, errors.Wrap(err, "error converting row into comment")
 		}
@@ -60,11 +196,72 @@ WHERE
 		comments = append(comments, *comment)
 	}
 
-	rows.Close()
+	err = s.addAttachments(comments)
+	if err != nil {
+		return nil, err
+	}
+
+	return toThreads(comments), nil
+}
+
+// getRawComments returns the comments with the given IDs by their ID. In contrast to all other functions, this includes
+// the content of hidden comments.
+func (s *Store) getRawComments(commentIds []string) (map[string]*Comment, error) {
+	comments := make(map[string]*Comment)
+	if len(commentIds) == 0 {
+		return comments, nil
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id = ANY($1);", returnValues, s.commentTable)
+	s.LogQuery(query, commentIds)
+
+	rows, err := s.tx.Query(query, pq.Array(commentIds))
+	if err != nil {
+		return nil, errors.Wrap(err, "error executing query")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		comment, _, err := rowToRawComment(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, "error converting row into comment")
+		}
+
+		comments[comment.Id] = comment
+	}
 
 	return comments, nil
 }
 
+// toThreads returns the top-level comments of the given flat list of comments with all replies nested into their
+// parent comments. The order of the given comments is kept on each level.
+func toThreads(comments []Comment) []Comment {
+	repliesOfComments := make(map[string][]Comment)
+	for _, c := range comments {
+		if c.ParentId != "" {
+			repliesOfComments[c.ParentId] = append(repliesOfComments[c.ParentId], c)
+		}
+	}
+
+	threads := make([]Comment, 0)
+	for _, c := range comments {
+		if c.ParentId == "" {
+			threads = append(threads, addReplies(c, repliesOfComments))
+		}
+	}
+
+	return threads
+}
+
+func addReplies(c Comment, repliesOfComments map[string][]Comment) Comment {
+	c.Replies = make([]Comment, 0)
+	for _, reply := range repliesOfComments[c.Id] {
+		c.Replies = append(c.Replies, addReplies(reply, repliesOfComments))
+	}
+
+	return c
+}
+
 func (s *Store) NewCommentList() (string, error) {
 	query := fmt.Sprintf("INSERT INTO %s DEFAULT VALUES RETURNING id", s.commentListTable)
 	s.LogQuery(query)
@@ -92,10 +289,321 @@ func (s *Store) NewCommentList() (string, error) {
 	return commentListId, nil
 }
 
-func (s *Store) addComment(listId string, text string, authorId string, creationDate time.Time) error {
-	query := fmt.Sprintf("INSERT INTO %s (comment_list_id, text, author_id, creation_date) VALUES($1, $2, $3, $4) RETURNING *", s.commentTable)
-	_, err := s.execQuery(query, listId, text, authorId, creationDate)
-	return err
+// NewCommentLists creates the given number of new and empty comment lists with one single query.
+func (s *Store) NewCommentLists(count int) ([]string, error) {
+	query := fmt.Sprintf("INSERT INTO %s (id) SELECT nextval('%s_id_seq') FROM generate_series(1, $1) RETURNING id", s.commentListTable, s.commentListTable)
+	s.LogQuery(query, count)
+
+	rows, err := s.tx.Query(query, count)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not run query")
+	}
+	defer rows.Close()
+
+	commentListIds := make([]string, 0, count)
+	for rows.Next() {
+		commentListId := ""
+		err = rows.Scan(&commentListId)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not scan row for comment list id")
+		}
+
+		commentListIds = append(commentListIds, commentListId)
+	}
+
+	if len(commentListIds) != count {
+		return nil, errors.New(fmt.Sprintf("expected %d new comment lists but got %d", count, len(commentListIds)))
+	}
+
+	return commentListIds, nil
+}
+
+// addComment adds a new comment to the list. The parent ID is optional and might be empty.
+func (s *Store) addComment(listId string, text string, authorId string, parentId string, mentions []string, creationDate time.Time) (*Comment, error) {
+	query := fmt.Sprintf("INSERT INTO %s (comment_list_id, text, author_id, creation_date, parent_id, mentions) VALUES($1, $2, $3, $4, NULLIF($5, '')::INT, $6) RETURNING %s", s.commentTable, returnValues)
+	return s.execQuery(query, listId, text, authorId, creationDate, parentId, pq.Array(mentions))
+}
+
+// importComment adds the comment with all its data (e.g. author and dates) as it is. The parent ID is optional and
+// might be empty.
+func (s *Store) importComment(listId string, c *Comment, parentId string) (*Comment, error) {
+	query := fmt.Sprintf("INSERT INTO %s (comment_list_id, text, author_id, creation_date, last_edit_date, parent_id, mentions, issue_status, hidden) VALUES($1, $2, $3, $4, $5, NULLIF($6, '')::INT, $7, $8, $9) RETURNING %s", s.commentTable, returnValues)
+	return s.execQuery(query, listId, c.Text, c.AuthorId, c.CreationDate, c.LastEditDate, parentId, pq.Array(c.Mentions), c.IssueStatus, c.Hidden)
+}
+
+func (s *Store) getComment(commentId string) (*Comment, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id=$1", returnValues, s.commentTable)
+	return s.execQuery(query, commentId)
+}
+
+// getCommentListIdOfComment returns the ID of the comment list the given comment belongs to.
+func (s *Store) getCommentListIdOfComment(commentId string) (string, error) {
+	query := fmt.Sprintf("SELECT comment_list_id FROM %s WHERE id = $1;", s.commentTable)
+	s.LogQuery(query, commentId)
+
+	rows, err := s.tx.Query(query, commentId)
+	if err != nil {
+		return "", errors.Wrapf(err, "error executing query to get comment list id of comment %s", commentId)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return "", errors.New(fmt.Sprintf("comment %s does not exist", commentId))
+	}
+
+	commentListId := ""
+	err = rows.Scan(&commentListId)
+	if err != nil {
+		return "", errors.Wrap(err, "could not scan row for comment list id")
+	}
+
+	return commentListId, nil
+}
+
+// updateComment replaces the text of the comment and keeps the previous text in the edit history.
+func (s *Store) updateComment(commentId string, text string, mentions []string, editDate time.Time) (*Comment, error) {
+	query := fmt.Sprintf("INSERT INTO %s (comment_id, text, edit_date) SELECT id, text, $2 FROM %s WHERE id=$1;", s.commentEditTable, s.commentTable)
+	s.LogQuery(query, commentId, editDate)
+
+	result, err := s.tx.Exec(query, commentId, editDate)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not store previous version of comment %s", commentId)
+	}
+
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get number of affected rows")
+	}
+	if affectedRows != 1 {
+		return nil, errors.New(fmt.Sprintf("comment %s does not exist", commentId))
+	}
+
+	query = fmt.Sprintf("UPDATE %s SET text=$2, mentions=$3, last_edit_date=$4 WHERE id=$1 RETURNING %s", s.commentTable, returnValues)
+	return s.execQuery(query, commentId, text, pq.Array(mentions), editDate)
+}
+
+func (s *Store) updateIssueStatus(commentId string, status IssueStatus) (*Comment, error) {
+	query := fmt.Sprintf("UPDATE %s SET issue_status=$2 WHERE id=$1 RETURNING %s", s.commentTable, returnValues)
+	return s.execQuery(query, commentId, status)
+}
+
+func (s *Store) updateHidden(commentId string, hidden bool) (*Comment, error) {
+	query := fmt.Sprintf("UPDATE %s SET hidden=$2 WHERE id=$1 RETURNING %s", s.commentTable, returnValues)
+	return s.execQuery(query, commentId, hidden)
+}
+
+// addReport stores the report of the user. When the user already reported the comment, the reason of the existing
+// report is replaced.
+func (s *Store) addReport(commentId string, reporterId string, reason string, creationDate time.Time) error {
+	query := fmt.Sprintf("INSERT INTO %s (comment_id, reporter_id, reason, creation_date) VALUES($1, $2, $3, $4) ON CONFLICT (comment_id, reporter_id) DO UPDATE SET reason=$3, creation_date=$4;", s.reportTable)
+	s.LogQuery(query, commentId, reporterId, reason, creationDate)
+
+	_, err := s.tx.Exec(query, commentId, reporterId, reason, creationDate)
+	if err != nil {
+		return errors.Wrapf(err, "could not add report of comment %s", commentId)
+	}
+
+	return nil
+}
+
+// deleteReports removes all reports of the comment.
+func (s *Store) deleteReports(commentId string) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE comment_id=$1;", s.reportTable)
+	s.LogQuery(query, commentId)
+
+	_, err := s.tx.Exec(query, commentId)
+	if err != nil {
+		return errors.Wrapf(err, "could not delete reports of comment %s", commentId)
+	}
+
+	return nil
+}
+
+// getReportsOfLists returns the reports of all comments in the given comment lists, the oldest first.
+func (s *Store) getReportsOfLists(listIds []string) ([]Report, error) {
+	query := fmt.Sprintf("SELECT r.id, r.comment_id, c.text, c.author_id, c.hidden, r.reporter_id, r.reason, r.creation_date FROM %s r JOIN %s c ON c.id = r.comment_id WHERE c.comment_list_id = ANY($1) ORDER BY r.creation_date, r.id;", s.reportTable, s.commentTable)
+	s.LogQuery(query, listIds)
+
+	rows, err := s.tx.Query(query, pq.Array(listIds))
+	if err != nil {
+		return nil, errors.Wrap(err, "error executing query")
+	}
+	defer rows.Close()
+
+	reports := make([]Report, 0)
+	for rows.Next() {
+		var report Report
+		var id, commentId int
+		var creationDate time.Time
+
+		err = rows.Scan(&id, &commentId, &report.CommentText, &report.CommentAuthorId, &report.CommentHidden, &report.ReporterId, &report.Reason, &creationDate)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not scan rows")
+		}
+
+		report.Id = strconv.Itoa(id)
+		report.CommentId = strconv.Itoa(commentId)
+		creationDate = creationDate.UTC()
+		report.CreationDate = &creationDate
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+// deleteComment removes the comment including its edit history.
+func (s *Store) deleteComment(commentId string) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id=$1;", s.commentTable)
+	s.LogQuery(query, commentId)
+
+	_, err := s.tx.Exec(query, commentId)
+	if err != nil {
+		return errors.Wrapf(err, "could not delete comment %s", commentId)
+	}
+
+	return nil
+}
+
+// getEdits returns the previous versions of the comment, the oldest first.
+func (s *Store) getEdits(commentId string) ([]Edit, error) {
+	query := fmt.Sprintf("SELECT text, edit_date FROM %s WHERE comment_id=$1 ORDER BY edit_date, id;", s.commentEditTable)
+	s.LogQuery(query, commentId)
+
+	rows, err := s.tx.Query(query, commentId)
+	if err != nil {
+		return nil, errors.Wrap(err, "error executing query")
+	}
+	defer rows.Close()
+
+	edits := make([]Edit, 0)
+	for rows.Next() {
+		var edit Edit
+		var editDate time.Time
+
+		err = rows.Scan(&edit.Text, &editDate)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not scan rows")
+		}
+
+		editDate = editDate.UTC()
+		edit.EditDate = &editDate
+		edits = append(edits, edit)
+	}
+
+	return edits, nil
+}
+
+func (s *Store) addAttachment(commentId string, fileName string, contentType string, size int, storageName string, creationDate time.Time) (*Attachment, error) {
+	query := fmt.Sprintf("INSERT INTO %s (comment_id, file_name, content_type, size, storage_name, creation_date) VALUES($1, $2, $3, $4, $5, $6) RETURNING %s", s.attachmentTable, attachmentReturnValues)
+	attachments, err := s.execAttachmentQuery(query, commentId, fileName, contentType, size, storageName, creationDate)
+	if err != nil {
+		return nil, err
+	}
+
+	return attachments[0], nil
+}
+
+func (s *Store) getAttachment(attachmentId string) (*Attachment, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id=$1", attachmentReturnValues, s.attachmentTable)
+	attachments, err := s.execAttachmentQuery(query, attachmentId)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(attachments) == 0 {
+		return nil, errors.New(fmt.Sprintf("attachment %s does not exist", attachmentId))
+	}
+
+	return attachments[0], nil
+}
+
+func (s *Store) getAttachmentsOfComment(commentId string) ([]*Attachment, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE comment_id=$1 ORDER BY id", attachmentReturnValues, s.attachmentTable)
+	return s.execAttachmentQuery(query, commentId)
+}
+
+// getAttachmentStorageNames returns the storage names of all attachments as set.
+func (s *Store) getAttachmentStorageNames() (map[string]bool, error) {
+	query := fmt.Sprintf("SELECT storage_name FROM %s", s.attachmentTable)
+	s.LogQuery(query)
+
+	rows, err := s.tx.Query(query)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get storage names of attachments")
+	}
+	defer rows.Close()
+
+	storageNames := make(map[string]bool)
+	for rows.Next() {
+		var storageName string
+		err = rows.Scan(&storageName)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not scan storage name of attachment")
+		}
+		storageNames[storageName] = true
+	}
+
+	return storageNames, nil
+}
+
+// addAttachments loads the attachments of all given comments with one query.
+func (s *Store) addAttachments(comments []Comment) error {
+	if len(comments) == 0 {
+		return nil
+	}
+
+	commentIds := make([]string, 0, len(comments))
+	for _, c := range comments {
+		// Attachments of hidden comments are not shown, just like their text
+		if !c.Hidden {
+			commentIds = append(commentIds, c.Id)
+		}
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE comment_id = ANY($1) ORDER BY id", attachmentReturnValues, s.attachmentTable)
+	attachments, err := s.execAttachmentQuery(query, pq.Array(commentIds))
+	if err != nil {
+		return err
+	}
+
+	attachmentsOfComments := make(map[string][]Attachment)
+	for _, a := range attachments {
+		attachmentsOfComments[a.CommentId] = append(attachmentsOfComments[a.CommentId], *a)
+	}
+
+	for i, c := range comments {
+		if attachmentsOfComments[c.Id] != nil {
+			comments[i].Attachments = attachmentsOfComments[c.Id]
+		}
+	}
+
+	return nil
+}
+
+func (s *Store) execAttachmentQuery(query string, params ...interface{}) ([]*Attachment, error) {
+	s.LogQuery(query, params...)
+	rows, err := s.tx.Query(query, params...)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not run query")
+	}
+	defer rows.Close()
+
+	attachments := make([]*Attachment, 0)
+	for rows.Next() {
+		var a Attachment
+		var id, commentId int
+
+		err = rows.Scan(&id, &commentId, &a.FileName, &a.ContentType, &a.Size, &a.storageName)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not scan rows")
+		}
+
+		a.Id = strconv.Itoa(id)
+		a.CommentId = strconv.Itoa(commentId)
+		attachments = append(attachments, &a)
+	}
+
+	return attachments, nil
 }
 
 // execQuery executed the given query, turns the result into a Comment object and closes the query.
@@ -111,7 +619,7 @@ func (s *Store) execQuery(query string, params ...interface{}) (*Comment, error)
 		return nil, errors.New("there is no next row or an error happened")
 	}
 
-	t, err := rowToComment(rows)
+	t, _, err := rowToComment(rows)
 
 	if t == nil && err == nil {
 		return nil, errors.New(fmt.Sprintf("Task does not exist"))
@@ -120,24 +628,62 @@ func (s *Store) execQuery(query string, params ...interface{}) (*Comment, error)
 	return t, err
 }
 
-// rowToComment turns the current row into a Project object. This does not close the row.
-func rowToComment(rows *sql.Rows) (*Comment, error) {
+// rowToComment turns the current row into a Comment object without the content of hidden comments. This does not
+// close the row.
+func rowToComment(rows *sql.Rows) (*Comment, *commentRow, error) {
+	result, c, err := rowToRawComment(rows)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The content of hidden comments is only available in the reports and exports for the owner of the project
+	if result.Hidden {
+		result.Text = ""
+		result.Html = ""
+		result.Mentions = make([]string, 0)
+	}
+
+	return result, c, nil
+}
+
+// rowToRawComment turns the current row into a Comment object including the content of hidden comments. This does not
+// close the row.
+func rowToRawComment(rows *sql.Rows) (*Comment, *commentRow, error) {
 	var c commentRow
-	err := rows.Scan(&c.commentListId, &c.id, &c.text, &c.creationDate, &c.authorId)
+	err := rows.Scan(&c.id, &c.commentListId, &c.text, &c.authorId, &c.creationDate, &c.lastEditDate, &c.parentId, pq.Array(&c.mentions), &c.issueStatus, &c.hidden)
 	if err != nil {
-		return nil, errors.Wrap(err, "could not scan rows")
+		return nil, nil, errors.Wrap(err, "could not scan rows")
 	}
 
 	result := Comment{}
 
 	result.Id = strconv.Itoa(c.id)
 	result.Text = c.text
+	result.Html = util.RenderMarkdown(c.text)
+	result.IssueStatus = c.issueStatus
+	result.Attachments = make([]Attachment, 0)
 	result.AuthorId = c.authorId
+	result.Replies = make([]Comment, 0)
+	result.Mentions = c.mentions
+	if result.Mentions == nil {
+		result.Mentions = make([]string, 0)
+	}
+
+	result.Hidden = c.hidden
+
+	if c.parentId != nil {
+		result.ParentId = strconv.Itoa(*c.parentId)
+	}
 
 	if c.creationDate != nil {
 		t := c.creationDate.UTC()
 		result.CreationDate = &t
 	}
 
-	return &result, nil
+	if c.lastEditDate != nil {
+		t := c.lastEditDate.UTC()
+		result.LastEditDate = &t
+	}
+
+	return &result, &c, nil
 }
@@ -4,6 +4,7 @@ type Dto struct {
 	SourceRepoURL        string `json:"sourceRepoUrl"`        // URL to the source code repository.
 	MaxTasksPerProject   int    `json:"maxTasksPerProject"`   // Maximum amount of tasks allowed for a project.
 	MaxDescriptionLength int    `json:"maxDescriptionLength"` // Maximum length for the project description in characters. Default: 1000.
+	MaxAttachmentSize    int    `json:"maxAttachmentSize"`    // Maximum size of files attached to comments in bytes.
 	TestEnvironment      bool   `json:"testEnvironment"`      // True when the server runs in an test environment
 	OsmApiUrl            string `json:"osmApiUrl"`            // The base-URL to the OSM server.
 }
@@ -13,6 +14,7 @@ func GetConfigDto() *Dto {
 		SourceRepoURL:        Conf.SourceRepoURL,
 		MaxTasksPerProject:   Conf.MaxTasksPerProject,
 		MaxDescriptionLength: Conf.MaxDescriptionLength,
+		MaxAttachmentSize:    Conf.MaxAttachmentSize,
 		TestEnvironment:      Conf.TestEnvironment,
 		OsmApiUrl:            Conf.OsmApiUrl,
 	}
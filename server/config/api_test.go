@@ -12,6 +12,7 @@ func TestGetConfigDto(t *testing.T) {
 		Conf.SourceRepoURL = "https://some.url/my/repo"
 		Conf.MaxDescriptionLength = 200
 		Conf.MaxTasksPerProject = 345
+		Conf.MaxAttachmentSize = 4096
 
 		dto := GetConfigDto()
 
@@ -24,6 +25,9 @@ func TestGetConfigDto(t *testing.T) {
 		if dto.MaxTasksPerProject != Conf.MaxTasksPerProject {
 			return errors.New(fmt.Sprintf("Dto value of 'MaxTasksPerProject' wrong: Wanted %d but was %d", Conf.MaxTasksPerProject, dto.MaxTasksPerProject))
 		}
+		if dto.MaxAttachmentSize != Conf.MaxAttachmentSize {
+			return errors.New(fmt.Sprintf("Dto value of 'MaxAttachmentSize' wrong: Wanted %d but was %d", Conf.MaxAttachmentSize, dto.MaxAttachmentSize))
+		}
 
 		return nil
 	})
@@ -26,6 +26,8 @@ const (
 	EnvVarMaxTasksPerProject    = "STM_MAX_TASKS_PER_PROJECT"
 	EnvVarMaxDescriptionLength  = "STM_MAX_DESCRIPTION_LENGTH"
 	EnvVarMaxCommentLength      = "STM_MAX_COMMENT_LENGTH"
+	EnvVarAttachmentDirectory   = "STM_ATTACHMENT_DIRECTORY"
+	EnvVarMaxAttachmentSize     = "STM_MAX_ATTACHMENT_SIZE"
 
 	EnvVarSslCertFile = "STM_SSL_CERT_FILE"
 	EnvVarSslKeyFile  = "STM_SSL_KEY_FILE"
@@ -51,6 +53,8 @@ const (
 	DefaultMaxTaskPerProject       = 1000
 	DefaultMaxDescriptionLength    = 1000
 	DefaultMaxCommentLength        = 1000
+	DefaultAttachmentDirectory     = "./attachments"
+	DefaultMaxAttachmentSize       = 2 * 1024 * 1024
 
 	DefaultDbUsername = "stm"
 	DefaultDbPassword = "secret"
@@ -72,6 +76,8 @@ type Config struct {
 	MaxTasksPerProject    int    `json:"max-task-per-project"`   // Maximum amount of tasks allowed for a project.
 	MaxDescriptionLength  int    `json:"max-description-length"` // Maximum length for the project description in characters.
 	MaxCommentLength      int    `json:"max-comment-length"`     // Maximum length for comments in characters.
+	AttachmentDirectory   string `json:"attachment-directory"`   // Directory in which the files attached to comments are stored.
+	MaxAttachmentSize     int    `json:"max-attachment-size"`    // Maximum size of files attached to comments in bytes.
 
 	SslCertFile string `json:"ssl-cert-file"`
 	SslKeyFile  string `json:"ssl-key-file"`
@@ -114,6 +120,8 @@ func LoadConfig(file string) {
 	Conf.MaxTasksPerProject = getConfigEntryInt(EnvVarMaxTasksPerProject, Conf.MaxTasksPerProject)
 	Conf.MaxDescriptionLength = getConfigEntryInt(EnvVarMaxDescriptionLength, Conf.MaxDescriptionLength)
 	Conf.MaxCommentLength = getConfigEntryInt(EnvVarMaxCommentLength, Conf.MaxCommentLength)
+	Conf.AttachmentDirectory = getConfigEntry(EnvVarAttachmentDirectory, Conf.AttachmentDirectory)
+	Conf.MaxAttachmentSize = getConfigEntryInt(EnvVarMaxAttachmentSize, Conf.MaxAttachmentSize)
 
 	// SSL configs
 	Conf.SslCertFile = getConfigEntry(EnvVarSslCertFile, Conf.SslCertFile)
@@ -148,6 +156,8 @@ func initDefaultConfig() {
 	Conf.MaxTasksPerProject = DefaultMaxTaskPerProject
 	Conf.MaxDescriptionLength = DefaultMaxDescriptionLength
 	Conf.MaxCommentLength = DefaultMaxCommentLength
+	Conf.AttachmentDirectory = DefaultAttachmentDirectory
+	Conf.MaxAttachmentSize = DefaultMaxAttachmentSize
 
 	Conf.DbUsername = DefaultDbUsername
 	Conf.DbPassword = DefaultDbPassword
@@ -0,0 +1,20 @@
+BEGIN TRANSACTION;
+
+CREATE TABLE project_invitations
+(
+	id              SERIAL PRIMARY KEY NOT NULL,
+	project_id      INT                NOT NULL,
+	code            TEXT               NOT NULL UNIQUE,
+	creation_date   TIMESTAMP          NOT NULL,
+	-- Can be null when the invitation never expires
+	expiration_date TIMESTAMP,
+	-- Zero means unlimited uses
+	max_uses        INT                NOT NULL DEFAULT 0,
+	uses            INT                NOT NULL DEFAULT 0
+);
+
+ALTER TABLE project_invitations ADD FOREIGN KEY (project_id) REFERENCES projects ON DELETE CASCADE;
+
+INSERT INTO db_versions VALUES ('014');
+
+END TRANSACTION;
\ No newline at end of file
@@ -0,0 +1,9 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE projects ADD COLUMN is_public BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE projects ADD COLUMN public_users_visible BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE projects ADD COLUMN public_comments_visible BOOLEAN NOT NULL DEFAULT false;
+
+INSERT INTO db_versions VALUES ('015');
+
+END TRANSACTION;
\ No newline at end of file
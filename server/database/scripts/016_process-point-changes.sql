@@ -0,0 +1,18 @@
+BEGIN TRANSACTION;
+
+-- Every change of process points is recorded to be able to tell who contributed what to a project.
+CREATE TABLE process_point_changes
+(
+	id             SERIAL PRIMARY KEY NOT NULL,
+	task_id        INT                NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+	user_id        TEXT               NOT NULL,
+	old_points     INT                NOT NULL,
+	new_points     INT                NOT NULL,
+	creation_date  TIMESTAMP          NOT NULL
+);
+
+CREATE INDEX process_point_changes_task_id_index ON process_point_changes (task_id);
+
+INSERT INTO db_versions VALUES ('016');
+
+END TRANSACTION;
\ No newline at end of file
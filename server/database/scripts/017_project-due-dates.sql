@@ -0,0 +1,10 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE projects ADD COLUMN start_date TIMESTAMP;
+ALTER TABLE projects ADD COLUMN due_date TIMESTAMP;
+-- Whether the members have already been notified that the due date passed. Reset when the due date changes.
+ALTER TABLE projects ADD COLUMN due_date_notified BOOLEAN NOT NULL DEFAULT false;
+
+INSERT INTO db_versions VALUES ('017');
+
+END TRANSACTION;
\ No newline at end of file
@@ -0,0 +1,18 @@
+BEGIN TRANSACTION;
+
+CREATE TABLE campaigns
+(
+	id            SERIAL PRIMARY KEY NOT NULL,
+	name          TEXT               NOT NULL,
+	description   TEXT               NOT NULL DEFAULT '',
+	owner         TEXT               NOT NULL,
+	users         TEXT[]             NOT NULL,
+	creation_date TIMESTAMP          NOT NULL
+);
+
+-- A project belongs to at most one campaign.
+ALTER TABLE projects ADD COLUMN campaign_id INT REFERENCES campaigns (id) ON DELETE SET NULL;
+
+INSERT INTO db_versions VALUES ('018');
+
+END TRANSACTION;
\ No newline at end of file
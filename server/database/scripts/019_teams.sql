@@ -0,0 +1,29 @@
+BEGIN TRANSACTION;
+
+CREATE TABLE teams
+(
+	id            SERIAL PRIMARY KEY NOT NULL,
+	name          TEXT               NOT NULL,
+	admins        TEXT[]             NOT NULL,
+	users         TEXT[]             NOT NULL,
+	creation_date TIMESTAMP          NOT NULL
+);
+
+CREATE TABLE project_teams
+(
+	project_id INT NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+	team_id    INT NOT NULL REFERENCES teams (id) ON DELETE CASCADE,
+	PRIMARY KEY (project_id, team_id)
+);
+
+-- All members of a project: The direct members and the members of all teams added to the project.
+CREATE VIEW project_members AS
+	SELECT p.id AS project_id, u.user_id
+	FROM projects p, UNNEST(p.users) AS u(user_id)
+UNION
+	SELECT pt.project_id, u.user_id
+	FROM project_teams pt JOIN teams t ON t.id = pt.team_id, UNNEST(t.users) AS u(user_id);
+
+INSERT INTO db_versions VALUES ('019');
+
+END TRANSACTION;
\ No newline at end of file
@@ -0,0 +1,12 @@
+BEGIN TRANSACTION;
+
+-- One of ALWAYS, NEVER and ABOVE_MEMBER_COUNT. The default matches the previous behavior: Assignments are needed as
+-- soon as there's more than one member.
+ALTER TABLE projects ADD COLUMN assignment_policy TEXT NOT NULL DEFAULT 'ABOVE_MEMBER_COUNT';
+ALTER TABLE projects ADD COLUMN assignment_member_threshold INT NOT NULL DEFAULT 1;
+-- Maximum number of tasks a user can be assigned to at the same time. 0 means no limit.
+ALTER TABLE projects ADD COLUMN max_assigned_tasks INT NOT NULL DEFAULT 0;
+
+INSERT INTO db_versions VALUES ('020');
+
+END TRANSACTION;
\ No newline at end of file
@@ -0,0 +1,12 @@
+BEGIN TRANSACTION;
+
+-- Overpass query template used with the data source OVERPASS. Empty means that the default query is used.
+ALTER TABLE projects ADD COLUMN overpass_query TEXT NOT NULL DEFAULT '';
+-- Download URL used with the data source CUSTOM.
+ALTER TABLE projects ADD COLUMN josm_download_url TEXT NOT NULL DEFAULT '';
+-- List of imagery layers (name, url, type, attribution) JOSM should load.
+ALTER TABLE projects ADD COLUMN imagery JSONB NOT NULL DEFAULT '[]';
+
+INSERT INTO db_versions VALUES ('021');
+
+END TRANSACTION;
\ No newline at end of file
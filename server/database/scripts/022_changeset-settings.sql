@@ -0,0 +1,9 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE projects ADD COLUMN changeset_comment TEXT NOT NULL DEFAULT '';
+ALTER TABLE projects ADD COLUMN changeset_source TEXT NOT NULL DEFAULT '';
+ALTER TABLE projects ADD COLUMN changeset_hashtags TEXT[] NOT NULL DEFAULT '{}';
+
+INSERT INTO db_versions VALUES ('022');
+
+END TRANSACTION;
\ No newline at end of file
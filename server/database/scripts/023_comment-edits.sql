@@ -0,0 +1,16 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE comments ADD COLUMN last_edit_date TIMESTAMP;
+
+-- Previous versions of edited comments. They are removed together with their comment.
+CREATE TABLE comment_edits
+(
+	id         SERIAL PRIMARY KEY NOT NULL,
+	comment_id INT                NOT NULL REFERENCES comments (id) ON DELETE CASCADE,
+	text       TEXT               NOT NULL,
+	edit_date  TIMESTAMP          NOT NULL
+);
+
+INSERT INTO db_versions VALUES ('023');
+
+END TRANSACTION;
\ No newline at end of file
@@ -0,0 +1,8 @@
+BEGIN TRANSACTION;
+
+-- Replies reference their parent comment within the same comment list. Replies of deleted comments become top-level comments.
+ALTER TABLE comments ADD COLUMN parent_id INT REFERENCES comments (id) ON DELETE SET NULL;
+
+INSERT INTO db_versions VALUES ('024');
+
+END TRANSACTION;
\ No newline at end of file
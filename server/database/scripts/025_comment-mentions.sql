@@ -0,0 +1,8 @@
+BEGIN TRANSACTION;
+
+-- User-IDs of the project members mentioned via "@<user>" in the comment text.
+ALTER TABLE comments ADD COLUMN mentions TEXT[] NOT NULL DEFAULT '{}';
+
+INSERT INTO db_versions VALUES ('025');
+
+END TRANSACTION;
\ No newline at end of file
@@ -0,0 +1,8 @@
+BEGIN TRANSACTION;
+
+-- Comments can be used as issues, which are either open or resolved. Normal comments have the status 'NONE'.
+ALTER TABLE comments ADD COLUMN issue_status TEXT NOT NULL DEFAULT 'NONE';
+
+INSERT INTO db_versions VALUES ('026');
+
+END TRANSACTION;
\ No newline at end of file
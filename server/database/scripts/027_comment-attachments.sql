@@ -0,0 +1,17 @@
+BEGIN TRANSACTION;
+
+-- Files attached to comments. The content is stored in the attachment directory under the storage name.
+CREATE TABLE comment_attachments
+(
+	id            SERIAL PRIMARY KEY NOT NULL,
+	comment_id    INT                NOT NULL REFERENCES comments (id) ON DELETE CASCADE,
+	file_name     TEXT               NOT NULL,
+	content_type  TEXT               NOT NULL,
+	size          INT                NOT NULL,
+	storage_name  TEXT               NOT NULL UNIQUE,
+	creation_date TIMESTAMP          NOT NULL
+);
+
+INSERT INTO db_versions VALUES ('027');
+
+END TRANSACTION;
\ No newline at end of file
@@ -0,0 +1,22 @@
+BEGIN TRANSACTION;
+
+-- Hidden comments stay in their threads but their content is not shown anymore.
+ALTER TABLE comments ADD COLUMN hidden BOOLEAN NOT NULL DEFAULT false;
+
+-- Reports of members about inappropriate comments. Each member can report a comment once.
+CREATE TABLE comment_reports
+(
+	id            SERIAL PRIMARY KEY NOT NULL,
+	comment_id    INT                NOT NULL REFERENCES comments (id) ON DELETE CASCADE,
+	reporter_id   TEXT               NOT NULL,
+	reason        TEXT               NOT NULL,
+	creation_date TIMESTAMP          NOT NULL,
+	UNIQUE (comment_id, reporter_id)
+);
+
+-- Members of the project who are not allowed to write comments anymore.
+ALTER TABLE projects ADD COLUMN read_only_users TEXT[] NOT NULL DEFAULT '{}';
+
+INSERT INTO db_versions VALUES ('028');
+
+END TRANSACTION;
\ No newline at end of file
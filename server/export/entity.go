@@ -1,21 +1,68 @@
 package export
 
-import "time"
+import (
+	"stm/comment"
+	"stm/project"
+	"time"
+)
+
+const (
+	// LegacyFormatVersion is the version of exports without "formatVersion". They contain no comments, task IDs and
+	// JOSM configuration.
+	LegacyFormatVersion = 1
+	// CurrentFormatVersion is the version of exports created by this server.
+	CurrentFormatVersion = 2
+)
+
+type Format string
+
+const (
+	FormatStm     Format = "stm"     // The ProjectExport, which can be imported again.
+	FormatGeoJson Format = "geojson" // A GeoJSON FeatureCollection of the tasks for GIS applications.
+)
 
 type ProjectExport struct {
-	Name         string        `json:"name"`
-	Users        []string      `json:"users"`
-	Owner        string        `json:"owner"`
-	Description  string        `json:"description"`
-	CreationDate *time.Time    `json:"creationDate"`
-	Tasks        []*TaskExport `json:"tasks"`
+	FormatVersion int           `json:"formatVersion"` // Version of the export format, see CurrentFormatVersion. Missing in exports of the legacy format.
+	Name          string        `json:"name"`
+	Users         []string      `json:"users"` // Direct members of the project, the members of its teams are only part of the teams.
+	Teams         []string      `json:"teams"` // IDs of the teams of the project. Since format version 2.
+	Owner         string        `json:"owner"`
+	Description   string        `json:"description"`
+	CreationDate  *time.Time    `json:"creationDate"`
+	Tasks         []*TaskExport `json:"tasks"`
+
+	ChangesetComment  string   `json:"changesetComment"`
+	ChangesetSource   string   `json:"changesetSource"`
+	ChangesetHashtags []string `json:"changesetHashtags"`
+
+	JosmDataSource  project.JosmDataSource `json:"josmDataSource"`  // Since format version 2.
+	OverpassQuery   string                 `json:"overpassQuery"`   // Since format version 2.
+	JosmDownloadUrl string                 `json:"josmDownloadUrl"` // Since format version 2.
+	Imagery         []project.ImageryLayer `json:"imagery"`         // Since format version 2.
+	Comments        []*CommentExport       `json:"comments"`        // Comment threads of the project. Since format version 2.
 }
 
 type TaskExport struct {
+	Id               string `json:"id"` // The ID of the task in the exported project. Since format version 2.
 	Name             string `json:"name"`
 	ProcessPoints    int    `json:"processPoints"`
 	MaxProcessPoints int    `json:"maxProcessPoints"`
-	Geometry         string `json:"geometry"`
+	Geometry         string `json:"geometry"` // GeoJSON feature including the properties of the task (e.g. the name).
 	// TODO Use "Id" as suffix?
-	AssignedUser string `json:"assignedUser"`
+	AssignedUser string           `json:"assignedUser"`
+	Comments     []*CommentExport `json:"comments"` // Comment threads of the task. Since format version 2.
+}
+
+// CommentExport is a comment with its replies. Attachments and the edit history are not part of the export. Hidden
+// comments only contain their content in exports of the owner of the project.
+type CommentExport struct {
+	Id           string              `json:"id"`
+	Text         string              `json:"text"`
+	AuthorId     string              `json:"authorId"`
+	CreationDate *time.Time          `json:"creationDate"`
+	LastEditDate *time.Time          `json:"lastEditDate"`
+	Mentions     []string            `json:"mentions"`
+	IssueStatus  comment.IssueStatus `json:"issueStatus"`
+	Hidden       bool                `json:"hidden"`
+	Replies      []*CommentExport    `json:"replies"`
 }
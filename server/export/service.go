@@ -1,6 +1,10 @@
 package export
 
 import (
+	"fmt"
+	geojson "github.com/paulmach/go.geojson"
+	"github.com/pkg/errors"
+	"stm/comment"
 	"stm/project"
 	"stm/task"
 	"stm/util"
@@ -9,25 +13,59 @@ import (
 type Service struct {
 	*util.Logger
 	projectService *project.Service
+	taskService    *task.Service
 }
 
-func Init(logger *util.Logger, projectService *project.Service) *Service {
+func Init(logger *util.Logger, projectService *project.Service, taskService *task.Service) *Service {
 	return &Service{
 		Logger:         logger,
 		projectService: projectService,
+		taskService:    taskService,
 	}
 }
 
+// ExportProject returns the project in the export format. Only exports of the owner contain the content of hidden
+// comments, exports of other members contain them without text and mentions.
 func (s *Service) ExportProject(projectId string, potentialMemberId string) (*ProjectExport, error) {
 	project, err := s.projectService.GetProject(projectId, potentialMemberId)
 	if err != nil {
 		return nil, err
 	}
 
+	if project.Owner == potentialMemberId {
+		err = s.projectService.RevealHiddenComments(project, potentialMemberId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return toProjectExport(project), nil
 }
 
+// ExportProjectAsGeoJson returns the tasks of the project as GeoJSON features. Besides the original properties of the
+// task geometries, each feature contains the ID, name, process points, completion (between 0 and 1) and assigned user
+// of its task.
+func (s *Service) ExportProjectAsGeoJson(projectId string, potentialMemberId string) (*geojson.FeatureCollection, error) {
+	project, err := s.projectService.GetProject(projectId, potentialMemberId)
+	if err != nil {
+		return nil, err
+	}
+
+	return toFeatureCollection(project.Tasks)
+}
+
+// ImportProject creates a new project from the export with the requesting user as owner. Exports of the current and
+// of the legacy format (without "formatVersion") are supported. The tasks get new IDs, assignments are restored for
+// users that are members of the imported project. Only teams the requesting user is a member of are added again.
 func (s *Service) ImportProject(projectExport *ProjectExport, requestingUserId string) (*project.Project, error) {
+	formatVersion := projectExport.FormatVersion
+	if formatVersion == 0 {
+		formatVersion = LegacyFormatVersion
+	}
+	if formatVersion > CurrentFormatVersion {
+		return nil, errors.New(fmt.Sprintf("Export format version %d not supported, the latest supported version is %d", formatVersion, CurrentFormatVersion))
+	}
+
 	// Determine if the requesting user is part of this project. If not, then add him/her. It wouldn't make much sense
 	//if the requesting user won't be part of the project
 	alreadyContainsUser := false
@@ -41,11 +79,23 @@ func (s *Service) ImportProject(projectExport *ProjectExport, requestingUserId s
 		projectExport.Users = append(projectExport.Users, requestingUserId)
 	}
 
+	// Legacy exports don't contain the data source
+	josmDataSource := projectExport.JosmDataSource
+	if josmDataSource == "" {
+		josmDataSource = project.OSM
+	}
+
 	projectDraftDto := &project.DraftDto{
-		Name:        projectExport.Name,
-		Description: projectExport.Description,
-		Users:       projectExport.Users,
-		Owner:       requestingUserId,
+		Name:           projectExport.Name,
+		Description:    projectExport.Description,
+		Users:          projectExport.Users,
+		Owner:          requestingUserId,
+		JosmDataSource: josmDataSource,
+		ChangesetDto: project.ChangesetDto{
+			ChangesetComment:  projectExport.ChangesetComment,
+			ChangesetSource:   projectExport.ChangesetSource,
+			ChangesetHashtags: projectExport.ChangesetHashtags,
+		},
 	}
 
 	taskDraftDtos := make([]task.DraftDto, len(projectExport.Tasks))
@@ -58,17 +108,81 @@ func (s *Service) ImportProject(projectExport *ProjectExport, requestingUserId s
 		}
 	}
 
-	return s.projectService.AddProjectWithTasks(projectDraftDto, taskDraftDtos)
+	addedProject, err := s.projectService.AddProjectWithTasks(projectDraftDto, taskDraftDtos)
+	if err != nil {
+		return nil, err
+	}
+
+	// Teams are added as teams again, so that their members don't become direct members
+	members := addedProject.Users
+	for _, teamId := range projectExport.Teams {
+		projectWithTeam, err := s.projectService.AddTeam(addedProject.Id, teamId, requestingUserId)
+		if err != nil {
+			s.Err("Unable to add team %s to imported project %s: %s", teamId, addedProject.Id, err)
+			continue
+		}
+		members = projectWithTeam.Users
+	}
+
+	if projectExport.OverpassQuery != "" || projectExport.JosmDownloadUrl != "" || len(projectExport.Imagery) > 0 {
+		_, err = s.projectService.UpdateJosmConfig(addedProject.Id, &project.JosmConfigDto{
+			JosmDataSource:  josmDataSource,
+			OverpassQuery:   projectExport.OverpassQuery,
+			JosmDownloadUrl: projectExport.JosmDownloadUrl,
+			Imagery:         projectExport.Imagery,
+		}, requestingUserId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.projectService.ImportComments(addedProject.Id, toComments(projectExport.Comments))
+	if err != nil {
+		return nil, err
+	}
+
+	// The tasks are added in the order of the export, so the i-th added task belongs to the i-th exported task
+	for i, addedTask := range addedProject.Tasks {
+		exportedTask := projectExport.Tasks[i]
+
+		if exportedTask.AssignedUser != "" && containsUser(members, exportedTask.AssignedUser) {
+			_, err = s.taskService.AssignUser(addedTask.Id, exportedTask.AssignedUser)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		err = s.taskService.ImportComments(addedTask.Id, toComments(exportedTask.Comments))
+		if err != nil {
+			return nil, err
+		}
+	}
+	s.Log("Imported project %s of export format version %d", addedProject.Id, formatVersion)
+
+	// Get the project again to have the assignments, comments and JOSM configuration in it
+	return s.projectService.GetProject(addedProject.Id, requestingUserId)
 }
 
 func toProjectExport(project *project.Project) *ProjectExport {
 	return &ProjectExport{
-		Name:         project.Name,
-		Users:        project.Users,
-		Owner:        project.Owner,
-		Description:  project.Description,
-		CreationDate: project.CreationDate,
-		Tasks:        toTaskExport(project.Tasks),
+		FormatVersion: CurrentFormatVersion,
+		Name:          project.Name,
+		Users:         project.DirectUsers,
+		Teams:         project.Teams,
+		Owner:         project.Owner,
+		Description:   project.Description,
+		CreationDate:  project.CreationDate,
+		Tasks:         toTaskExport(project.Tasks),
+
+		ChangesetComment:  project.ChangesetComment,
+		ChangesetSource:   project.ChangesetSource,
+		ChangesetHashtags: project.ChangesetHashtags,
+
+		JosmDataSource:  project.JosmDataSource,
+		OverpassQuery:   project.OverpassQuery,
+		JosmDownloadUrl: project.JosmDownloadUrl,
+		Imagery:         project.Imagery,
+		Comments:        toCommentExport(project.Comments),
 	}
 }
 
@@ -78,13 +192,92 @@ func toTaskExport(tasks []*task.Task) []*TaskExport {
 	for i := 0; i < len(tasks); i++ {
 		task := tasks[i]
 		taskExport[i] = &TaskExport{
+			Id:               task.Id,
 			Name:             task.Name,
 			ProcessPoints:    task.ProcessPoints,
 			MaxProcessPoints: task.MaxProcessPoints,
 			Geometry:         task.Geometry,
 			AssignedUser:     task.AssignedUser,
+			Comments:         toCommentExport(task.Comments),
 		}
 	}
 
 	return taskExport
 }
+
+func toCommentExport(comments []comment.Comment) []*CommentExport {
+	commentExport := make([]*CommentExport, len(comments))
+
+	for i, c := range comments {
+		commentExport[i] = &CommentExport{
+			Id:           c.Id,
+			Text:         c.Text,
+			AuthorId:     c.AuthorId,
+			CreationDate: c.CreationDate,
+			LastEditDate: c.LastEditDate,
+			Mentions:     c.Mentions,
+			IssueStatus:  c.IssueStatus,
+			Hidden:       c.Hidden,
+			Replies:      toCommentExport(c.Replies),
+		}
+	}
+
+	return commentExport
+}
+
+func toFeatureCollection(tasks []*task.Task) (*geojson.FeatureCollection, error) {
+	featureCollection := geojson.NewFeatureCollection()
+
+	for _, t := range tasks {
+		feature, err := geojson.UnmarshalFeature([]byte(t.Geometry))
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not unmarshal geometry of task %s", t.Id)
+		}
+
+		completion := 0.0
+		if t.MaxProcessPoints > 0 {
+			completion = float64(t.ProcessPoints) / float64(t.MaxProcessPoints)
+		}
+
+		feature.ID = t.Id
+		feature.SetProperty("id", t.Id)
+		feature.SetProperty("name", t.Name)
+		feature.SetProperty("processPoints", t.ProcessPoints)
+		feature.SetProperty("maxProcessPoints", t.MaxProcessPoints)
+		feature.SetProperty("completion", completion)
+		feature.SetProperty("assignedUser", t.AssignedUser)
+
+		featureCollection.AddFeature(feature)
+	}
+
+	return featureCollection, nil
+}
+
+func toComments(commentExport []*CommentExport) []comment.Comment {
+	comments := make([]comment.Comment, len(commentExport))
+
+	for i, c := range commentExport {
+		comments[i] = comment.Comment{
+			Id:           c.Id,
+			Text:         c.Text,
+			AuthorId:     c.AuthorId,
+			CreationDate: c.CreationDate,
+			LastEditDate: c.LastEditDate,
+			Mentions:     c.Mentions,
+			IssueStatus:  c.IssueStatus,
+			Hidden:       c.Hidden,
+			Replies:      toComments(c.Replies),
+		}
+	}
+
+	return comments
+}
+
+func containsUser(users []string, user string) bool {
+	for _, u := range users {
+		if u == user {
+			return true
+		}
+	}
+	return false
+}
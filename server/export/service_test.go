@@ -2,6 +2,8 @@ package export
 
 import (
 	"database/sql"
+	"encoding/json"
+	"fmt"
 	"github.com/hauke96/sigolo"
 	"github.com/pkg/errors"
 	"stm/comment"
@@ -11,6 +13,7 @@ import (
 	"stm/task"
 	"stm/test"
 	"stm/util"
+	"strings"
 	"testing"
 	"time"
 )
@@ -40,7 +43,7 @@ func setup() {
 	taskService := task.Init(tx, logger, permissionStore, commentService, commentStore)
 	projectService := project.Init(tx, logger, taskService, permissionStore, commentService, commentStore)
 
-	s = Init(logger, projectService)
+	s = Init(logger, projectService, taskService)
 }
 
 func TestGetProjectExport(t *testing.T) {
@@ -92,6 +95,10 @@ func TestImportProject(t *testing.T) {
 			Description:  "foo",
 			CreationDate: &time,
 			Tasks:        []*TaskExport{task},
+
+			ChangesetComment:  "Mapping buildings",
+			ChangesetSource:   "Bing",
+			ChangesetHashtags: []string{"#stm", "#buildings"},
 		}
 
 		// Act
@@ -120,6 +127,18 @@ func TestImportProject(t *testing.T) {
 		if len(result.Tasks) != 1 {
 			return errors.New("Number of tasks not matching")
 		}
+		if result.ChangesetComment != "Mapping buildings" || result.ChangesetSource != "Bing" || len(result.ChangesetHashtags) != 2 || result.ChangesetHashtags[1] != "#buildings" {
+			return errors.New(fmt.Sprintf("Changeset settings not matching: %#v", result))
+		}
+
+		// The changeset settings are part of the export again
+		exported, err := s.ExportProject(result.Id, "123")
+		if err != nil {
+			return err
+		}
+		if exported.ChangesetComment != "Mapping buildings" || exported.ChangesetSource != "Bing" || len(exported.ChangesetHashtags) != 2 {
+			return errors.New(fmt.Sprintf("Exported changeset settings not matching: %#v", exported))
+		}
 
 		return nil
 	})
@@ -165,3 +184,257 @@ func TestImportProjectByDifferentUser(t *testing.T) {
 		return nil
 	})
 }
+
+func TestExportAndImportWithComments(t *testing.T) {
+	h.Run(t, func() error {
+		// Arrange
+		parent, err := s.projectService.AddComment("2", &comment.DraftDto{Text: "Please check the roads"}, "Anna")
+		if err != nil {
+			return err
+		}
+		reply, err := s.projectService.AddComment("2", &comment.DraftDto{Text: "@Anna done", ParentId: parent.Id}, "John")
+		if err != nil {
+			return err
+		}
+		_, err = s.projectService.SetCommentIssueStatus(parent.Id, comment.IssueOpen, "Anna")
+		if err != nil {
+			return err
+		}
+
+		exported, err := s.ExportProject("2", "Maria")
+		if err != nil {
+			return err
+		}
+		if exported.FormatVersion != CurrentFormatVersion || exported.JosmDataSource != project.OSM {
+			return errors.New(fmt.Sprintf("Unexpected format version %d or data source %s", exported.FormatVersion, exported.JosmDataSource))
+		}
+		if len(exported.Comments) != 1 || len(exported.Comments[0].Replies) != 1 || exported.Comments[0].Replies[0].Id != reply.Id {
+			return errors.New(fmt.Sprintf("Unexpected exported comments: %#v", exported.Comments))
+		}
+
+		// Act
+		result, err := s.ImportProject(exported, "Maria")
+
+		// Assert
+		if err != nil {
+			return err
+		}
+
+		if result.Id == "2" || result.JosmDataSource != project.OSM || len(result.Tasks) != len(exported.Tasks) {
+			return errors.New(fmt.Sprintf("Unexpected imported project: %#v", result))
+		}
+
+		for i, task := range result.Tasks {
+			exportedTask := exported.Tasks[i]
+			if task.Id == exportedTask.Id || task.AssignedUser != exportedTask.AssignedUser || task.ProcessPoints != exportedTask.ProcessPoints || len(task.Comments) != len(exportedTask.Comments) {
+				return errors.New(fmt.Sprintf("Imported task %s does not match exported task %s", task.Id, exportedTask.Id))
+			}
+		}
+
+		// Task 3 is assigned to Maria and has one comment by Maria
+		importedTask := result.Tasks[1]
+		if exported.Tasks[1].Id != "3" || importedTask.AssignedUser != "Maria" || len(importedTask.Comments) != 1 {
+			return errors.New(fmt.Sprintf("Unexpected imported task 3: %#v", importedTask))
+		}
+		taskComment := importedTask.Comments[0]
+		if taskComment.Text != "Almost done here" || taskComment.AuthorId != "Maria" || *taskComment.CreationDate != time.Date(2021, 2, 17, 9, 30, 0, 0, time.UTC) {
+			return errors.New(fmt.Sprintf("Unexpected imported task comment: %#v", taskComment))
+		}
+
+		if len(result.Comments) != 1 || len(result.Comments[0].Replies) != 1 {
+			return errors.New(fmt.Sprintf("Unexpected imported project comments: %#v", result.Comments))
+		}
+		importedParent := result.Comments[0]
+		importedReply := importedParent.Replies[0]
+		if importedParent.Id == parent.Id || importedParent.AuthorId != "Anna" || importedParent.IssueStatus != comment.IssueOpen || *importedParent.CreationDate != *parent.CreationDate {
+			return errors.New(fmt.Sprintf("Unexpected imported comment: %#v", importedParent))
+		}
+		if importedReply.ParentId != importedParent.Id || importedReply.AuthorId != "John" || len(importedReply.Mentions) != 1 || importedReply.Mentions[0] != "Anna" {
+			return errors.New(fmt.Sprintf("Unexpected imported reply: %#v", importedReply))
+		}
+		if result.OpenIssueCount != 1 {
+			return errors.New(fmt.Sprintf("Imported project should have one open issue but has %d", result.OpenIssueCount))
+		}
+
+		return nil
+	})
+}
+
+func TestExportAndImportHiddenComments(t *testing.T) {
+	h.Run(t, func() error {
+		// Comment 3 is written by Maria on task 3 of project 2, which is owned by Maria
+		_, err := s.projectService.SetCommentHidden("3", true, "Maria")
+		if err != nil {
+			return err
+		}
+
+		// Other members don't see the content of hidden comments
+		exported, err := s.ExportProject("2", "Anna")
+		if err != nil {
+			return err
+		}
+		if hiddenComment := exported.Tasks[1].Comments[0]; !hiddenComment.Hidden || hiddenComment.Text != "" {
+			return errors.New(fmt.Sprintf("Export of member should not contain hidden content: %#v", hiddenComment))
+		}
+
+		exported, err = s.ExportProject("2", "Maria")
+		if err != nil {
+			return err
+		}
+		if hiddenComment := exported.Tasks[1].Comments[0]; !hiddenComment.Hidden || hiddenComment.Text != "Almost done here" {
+			return errors.New(fmt.Sprintf("Export of owner should contain hidden content: %#v", hiddenComment))
+		}
+
+		imported, err := s.ImportProject(exported, "Maria")
+		if err != nil {
+			return err
+		}
+		if importedComment := imported.Tasks[1].Comments[0]; !importedComment.Hidden || importedComment.Text != "" {
+			return errors.New(fmt.Sprintf("Imported comment should still be hidden: %#v", importedComment))
+		}
+
+		// The content survives the import
+		reexported, err := s.ExportProject(imported.Id, "Maria")
+		if err != nil {
+			return err
+		}
+		if reexportedComment := reexported.Tasks[1].Comments[0]; !reexportedComment.Hidden || reexportedComment.Text != "Almost done here" {
+			return errors.New(fmt.Sprintf("Imported hidden comment should keep its content: %#v", reexportedComment))
+		}
+
+		return nil
+	})
+}
+
+func TestExportAndImportWithTeams(t *testing.T) {
+	h.Run(t, func() error {
+		// Team 1 consists of Anna, Otto and Maria
+		_, err := s.projectService.AddTeam("2", "1", "Maria")
+		if err != nil {
+			return err
+		}
+
+		exported, err := s.ExportProject("2", "Maria")
+		if err != nil {
+			return err
+		}
+		if len(exported.Users) != 6 || containsUser(exported.Users, "Otto") || len(exported.Teams) != 1 || exported.Teams[0] != "1" {
+			return errors.New(fmt.Sprintf("Only direct members should be exported as users: %v, %v", exported.Users, exported.Teams))
+		}
+
+		result, err := s.ImportProject(exported, "Maria")
+		if err != nil {
+			return err
+		}
+
+		if len(result.Teams) != 1 || result.Teams[0] != "1" {
+			return errors.New(fmt.Sprintf("Team should be added to imported project: %v", result.Teams))
+		}
+		if containsUser(result.DirectUsers, "Otto") || !containsUser(result.Users, "Otto") {
+			return errors.New(fmt.Sprintf("Otto should only be a member via the team: %v, %v", result.DirectUsers, result.Users))
+		}
+
+		return nil
+	})
+}
+
+func TestImportLegacyFormat(t *testing.T) {
+	h.Run(t, func() error {
+		// Arrange
+		legacyExport := `{
+	"name": "Legacy project",
+	"users": ["123", "345"],
+	"owner": "123",
+	"description": "foo",
+	"creationDate": "2021-02-13T05:16:55.150015Z",
+	"tasks": [{
+		"name": "task 1",
+		"processPoints": 33,
+		"maxProcessPoints": 120,
+		"geometry": "{\"type\":\"Feature\",\"geometry\":{\"type\":\"Polygon\",\"coordinates\":[[[0.0,0.0],[0.0,1.0],[1.0,1.0],[1.0,0.0],[0.0,0.0]]]},\"properties\":{\"name\":\"task 1\"}}",
+		"assignedUser": "345"
+	}]
+}`
+		var projectExport ProjectExport
+		err := json.Unmarshal([]byte(legacyExport), &projectExport)
+		if err != nil {
+			return err
+		}
+
+		// Act
+		result, err := s.ImportProject(&projectExport, "123")
+
+		// Assert
+		if err != nil {
+			return err
+		}
+
+		if result.JosmDataSource != project.OSM || len(result.Comments) != 0 {
+			return errors.New(fmt.Sprintf("Unexpected imported legacy project: %#v", result))
+		}
+		if len(result.Tasks) != 1 || result.Tasks[0].Name != "task 1" || result.Tasks[0].AssignedUser != "345" || result.Tasks[0].ProcessPoints != 33 {
+			return errors.New(fmt.Sprintf("Unexpected imported legacy task: %#v", result.Tasks[0]))
+		}
+
+		// Newer formats are not supported
+		projectExport.FormatVersion = CurrentFormatVersion + 1
+		_, err = s.ImportProject(&projectExport, "123")
+		if err == nil {
+			return errors.New("Unknown format version should not be accepted")
+		}
+
+		return nil
+	})
+}
+
+func TestToFeatureCollection(t *testing.T) {
+	tasks := []*task.Task{
+		{
+			Id:               "7",
+			Name:             "Harbour",
+			ProcessPoints:    25,
+			MaxProcessPoints: 100,
+			Geometry:         "{\"type\":\"Feature\",\"geometry\":{\"type\":\"Polygon\",\"coordinates\":[[[0.0,0.0],[0.0,1.0],[1.0,1.0],[0.0,0.0]]]},\"properties\":{\"name\":\"Harbour\",\"landuse\":\"port\"}}",
+			AssignedUser:     "Maria",
+		},
+		{
+			Id:               "8",
+			MaxProcessPoints: 10,
+			Geometry:         "{\"type\":\"Feature\",\"geometry\":{\"type\":\"Polygon\",\"coordinates\":[[[0.0,0.0],[0.0,1.0],[1.0,1.0],[0.0,0.0]]]},\"properties\":null}",
+		},
+	}
+
+	featureCollection, err := toFeatureCollection(tasks)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(featureCollection.Features) != 2 {
+		t.Fatalf("Expected 2 features but got %d", len(featureCollection.Features))
+	}
+
+	feature := featureCollection.Features[0]
+	if feature.ID != "7" || feature.Properties["id"] != "7" || feature.Properties["name"] != "Harbour" || feature.Properties["assignedUser"] != "Maria" {
+		t.Errorf("Unexpected properties of first feature: %#v", feature.Properties)
+	}
+	if feature.Properties["processPoints"] != 25 || feature.Properties["maxProcessPoints"] != 100 || feature.Properties["completion"] != 0.25 {
+		t.Errorf("Unexpected process points of first feature: %#v", feature.Properties)
+	}
+	if feature.Properties["landuse"] != "port" || feature.Geometry == nil || !feature.Geometry.IsPolygon() {
+		t.Errorf("Original properties and geometry of first feature should be kept: %#v", feature)
+	}
+
+	feature = featureCollection.Features[1]
+	if feature.Properties["completion"] != 0.0 || feature.Properties["assignedUser"] != "" || feature.Properties["name"] != "" {
+		t.Errorf("Unexpected properties of second feature: %#v", feature.Properties)
+	}
+
+	// The geometry is a real GeoJSON object and not an escaped string
+	encoded, err := json.Marshal(featureCollection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(encoded), `"type":"FeatureCollection"`) || !strings.Contains(string(encoded), `"coordinates":[[[0,0],[0,1],[1,1],[0,0]]]`) {
+		t.Errorf("Unexpected encoded feature collection: %s", encoded)
+	}
+}
@@ -0,0 +1,8 @@
+package invitation
+
+import "time"
+
+type DraftDto struct {
+	ExpirationDate *time.Time `json:"expirationDate"` // UTC Date in RFC 3339 format. Can be NULL for an invitation that never expires.
+	MaxUses        int        `json:"maxUses"`        // Maximum number of users that can join with this invitation. Zero means unlimited. Must not be negative.
+}
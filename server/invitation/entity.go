@@ -0,0 +1,13 @@
+package invitation
+
+import "time"
+
+type Invitation struct {
+	Id             string     `json:"id"`             // The ID of the invitation.
+	ProjectId      string     `json:"projectId"`      // The ID of the project this invitation is for.
+	Code           string     `json:"code"`           // The secret code used to join the project. Will never be NULL or empty.
+	CreationDate   *time.Time `json:"creationDate"`   // UTC Date in RFC 3339 format.
+	ExpirationDate *time.Time `json:"expirationDate"` // UTC Date in RFC 3339 format after which the invitation cannot be used anymore. NULL means that it never expires.
+	MaxUses        int        `json:"maxUses"`        // Maximum number of users that can join with this invitation. Zero means unlimited.
+	Uses           int        `json:"uses"`           // Number of users that already joined with this invitation.
+}
@@ -0,0 +1,114 @@
+package invitation
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/pkg/errors"
+	"stm/permission"
+	"stm/project"
+	"stm/util"
+	"time"
+)
+
+type Service struct {
+	*util.Logger
+	store           *store
+	permissionStore *permission.Store
+	projectService  *project.Service
+}
+
+func Init(tx *sql.Tx, logger *util.Logger, permissionStore *permission.Store, projectService *project.Service) *Service {
+	return &Service{
+		Logger:          logger,
+		store:           getStore(tx, logger),
+		permissionStore: permissionStore,
+		projectService:  projectService,
+	}
+}
+
+// GetInvitations returns all invitations of the given project. Only the owner is allowed to see them, because they
+// contain the secret codes.
+func (s *Service) GetInvitations(projectId string, requestingUserId string) ([]*Invitation, error) {
+	err := s.permissionStore.VerifyOwnership(projectId, requestingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.store.getInvitationsOfProject(projectId)
+}
+
+// AddInvitation creates a new invitation with a random secret code for the given project. The requesting user must be
+// the owner of the project.
+func (s *Service) AddInvitation(projectId string, draft *DraftDto, requestingUserId string) (*Invitation, error) {
+	err := s.permissionStore.VerifyOwnership(projectId, requestingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if draft.MaxUses < 0 {
+		return nil, errors.New(fmt.Sprintf("Maximum number of uses must not be negative (%d)", draft.MaxUses))
+	}
+
+	now := time.Now().UTC()
+	if draft.ExpirationDate != nil && !draft.ExpirationDate.After(now) {
+		return nil, errors.New(fmt.Sprintf("Expiration date %s must be in the future", draft.ExpirationDate))
+	}
+
+	code, err := util.GetRandomString()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to generate invitation code")
+	}
+
+	invitation, err := s.store.addInvitation(projectId, code, draft, now)
+	if err != nil {
+		return nil, err
+	}
+	s.Log("Added invitation %s to project %s", invitation.Id, projectId)
+
+	return invitation, nil
+}
+
+// DeleteInvitation revokes the invitation so that nobody can join the project with it anymore. The requesting user must
+// be the owner of the project.
+func (s *Service) DeleteInvitation(projectId string, invitationId string, requestingUserId string) error {
+	err := s.permissionStore.VerifyOwnership(projectId, requestingUserId)
+	if err != nil {
+		return err
+	}
+
+	err = s.store.delete(projectId, invitationId)
+	if err != nil {
+		return err
+	}
+	s.Log("Deleted invitation %s of project %s", invitationId, projectId)
+
+	return nil
+}
+
+// RedeemInvitation adds the requesting user to the project of the invitation with the given code. This fails when the
+// invitation is expired, used up or when the user is already a member of the project.
+func (s *Service) RedeemInvitation(code string, requestingUserId string) (*project.Project, error) {
+	invitation, err := s.store.getInvitation(code)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check before using the invitation, so that members don't use up the invitation
+	err = s.permissionStore.VerifyMembershipProject(invitation.ProjectId, requestingUserId)
+	if err == nil {
+		return nil, errors.New(fmt.Sprintf("user %s is already a member of project %s", requestingUserId, invitation.ProjectId))
+	}
+
+	invitation, err = s.store.useInvitation(code, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := s.projectService.JoinProject(invitation.ProjectId, requestingUserId)
+	if err != nil {
+		return nil, err
+	}
+	s.Log("User %s joined project %s with invitation %s", requestingUserId, p.Id, invitation.Id)
+
+	return p, nil
+}
@@ -0,0 +1,255 @@
+package invitation
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/hauke96/sigolo"
+	"github.com/pkg/errors"
+	"stm/comment"
+	"stm/config"
+	"stm/permission"
+	"stm/project"
+	"stm/task"
+	"stm/test"
+	"stm/util"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq" // Make driver "postgres" usable
+)
+
+var (
+	tx *sql.Tx
+	s  *Service
+	h  *test.Helper
+)
+
+func TestMain(m *testing.M) {
+	h = test.NewTestHelper(setup)
+	m.Run()
+}
+
+func setup() {
+	sigolo.LogLevel = sigolo.LOG_DEBUG
+	config.LoadConfig("../test/test-config.json")
+	h.InitWithDummyData(config.Conf.DbUsername, config.Conf.DbPassword, config.Conf.DbDatabase)
+	tx = h.NewTransaction()
+
+	logger := util.NewLogger()
+
+	permissionStore := permission.Init(tx, logger)
+	commentStore := comment.GetStore(tx, logger)
+	commentService := comment.Init(logger, commentStore)
+	taskService := task.Init(tx, logger, permissionStore, commentService, commentStore)
+	projectService := project.Init(tx, logger, taskService, permissionStore, commentService, commentStore)
+
+	s = Init(tx, logger, permissionStore, projectService)
+}
+
+func TestGetInvitations(t *testing.T) {
+	h.Run(t, func() error {
+		invitations, err := s.GetInvitations("2", "Maria")
+		if err != nil {
+			return err
+		}
+
+		if len(invitations) != 3 {
+			return errors.New(fmt.Sprintf("Expected 3 invitations but found %d", len(invitations)))
+		}
+		if invitations[0].Code != "valid-code" || invitations[0].Uses != 3 || invitations[0].ExpirationDate != nil {
+			return errors.New(fmt.Sprintf("Invitation not matching: %#v", invitations[0]))
+		}
+
+		// Non-owner is not allowed to see the codes
+		_, err = s.GetInvitations("2", "John")
+		if err == nil {
+			return errors.New("Non-owner should not be able to get invitations")
+		}
+
+		return nil
+	})
+}
+
+func TestAddInvitation(t *testing.T) {
+	h.Run(t, func() error {
+		expirationDate := time.Now().Add(time.Hour).UTC()
+		draft := &DraftDto{
+			ExpirationDate: &expirationDate,
+			MaxUses:        10,
+		}
+
+		invitation, err := s.AddInvitation("1", draft, "Peter")
+		if err != nil {
+			return err
+		}
+
+		if invitation.ProjectId != "1" {
+			return errors.New(fmt.Sprintf("Project ID should be 1 but was %s", invitation.ProjectId))
+		}
+		if invitation.Code == "" {
+			return errors.New("Code should be set")
+		}
+		if invitation.MaxUses != 10 || invitation.Uses != 0 {
+			return errors.New(fmt.Sprintf("Uses not matching: %#v", invitation))
+		}
+		if invitation.ExpirationDate == nil || !invitation.ExpirationDate.Round(time.Second).Equal(expirationDate.Round(time.Second)) {
+			return errors.New(fmt.Sprintf("Expiration date should be %s but was %s", expirationDate, invitation.ExpirationDate))
+		}
+
+		// Two invitations should never have the same code
+		otherInvitation, err := s.AddInvitation("1", &DraftDto{}, "Peter")
+		if err != nil {
+			return err
+		}
+		if otherInvitation.Code == invitation.Code {
+			return errors.New("Codes of two invitations should differ")
+		}
+
+		return nil
+	})
+}
+
+func TestAddInvitationWithInvalidParameters(t *testing.T) {
+	h.Run(t, func() error {
+		// Non-owner
+		_, err := s.AddInvitation("1", &DraftDto{}, "Maria")
+		if err == nil {
+			return errors.New("Non-owner should not be able to add invitation")
+		}
+
+		// Negative maximum uses
+		_, err = s.AddInvitation("1", &DraftDto{MaxUses: -1}, "Peter")
+		if err == nil {
+			return errors.New("Negative maximum number of uses should not be allowed")
+		}
+
+		// Expiration date in the past
+		expirationDate := time.Now().Add(-time.Hour).UTC()
+		_, err = s.AddInvitation("1", &DraftDto{ExpirationDate: &expirationDate}, "Peter")
+		if err == nil {
+			return errors.New("Expiration date in the past should not be allowed")
+		}
+
+		return nil
+	})
+}
+
+func TestRedeemInvitation(t *testing.T) {
+	h.Run(t, func() error {
+		p, err := s.RedeemInvitation("valid-code", "Nina")
+		if err != nil {
+			return err
+		}
+
+		if p.Id != "2" {
+			return errors.New(fmt.Sprintf("Joined project should be 2 but was %s", p.Id))
+		}
+		if !containsUser(p.Users, "Nina") {
+			return errors.New("Nina should be a member of project 2")
+		}
+
+		invitations, err := s.GetInvitations("2", "Maria")
+		if err != nil {
+			return err
+		}
+		if invitations[0].Uses != 4 {
+			return errors.New(fmt.Sprintf("Uses should have been increased to 4 but was %d", invitations[0].Uses))
+		}
+
+		// Already a member
+		_, err = s.RedeemInvitation("valid-code", "Nina")
+		if err == nil {
+			return errors.New("Joining a project twice should not work")
+		}
+
+		// The failed attempt doesn't use the invitation
+		invitations, err = s.GetInvitations("2", "Maria")
+		if err != nil {
+			return err
+		}
+		if invitations[0].Uses != 4 {
+			return errors.New(fmt.Sprintf("Uses should still be 4 but was %d", invitations[0].Uses))
+		}
+
+		return nil
+	})
+}
+
+func TestRedeemInvalidInvitation(t *testing.T) {
+	h.Run(t, func() error {
+		_, err := s.RedeemInvitation("expired-code", "Nina")
+		if err == nil {
+			return errors.New("Expired invitation should not be usable")
+		}
+
+		_, err = s.RedeemInvitation("used-up-code", "Nina")
+		if err == nil {
+			return errors.New("Used up invitation should not be usable")
+		}
+
+		_, err = s.RedeemInvitation("not-existing-code", "Nina")
+		if err == nil {
+			return errors.New("Not existing invitation should not be usable")
+		}
+
+		return nil
+	})
+}
+
+func TestRedeemInvitationUntilUsedUp(t *testing.T) {
+	h.Run(t, func() error {
+		invitation, err := s.AddInvitation("1", &DraftDto{MaxUses: 1}, "Peter")
+		if err != nil {
+			return err
+		}
+
+		_, err = s.RedeemInvitation(invitation.Code, "Nina")
+		if err != nil {
+			return err
+		}
+
+		_, err = s.RedeemInvitation(invitation.Code, "Otto")
+		if err == nil {
+			return errors.New("Invitation with only one use should not be usable twice")
+		}
+
+		return nil
+	})
+}
+
+func TestDeleteInvitation(t *testing.T) {
+	h.Run(t, func() error {
+		// Non-owner
+		err := s.DeleteInvitation("2", "1", "John")
+		if err == nil {
+			return errors.New("Non-owner should not be able to delete invitation")
+		}
+
+		// Invitation of different project
+		err = s.DeleteInvitation("1", "1", "Peter")
+		if err == nil {
+			return errors.New("Deleting invitation of different project should not work")
+		}
+
+		err = s.DeleteInvitation("2", "1", "Maria")
+		if err != nil {
+			return err
+		}
+
+		_, err = s.RedeemInvitation("valid-code", "Nina")
+		if err == nil {
+			return errors.New("Deleted invitation should not be usable")
+		}
+
+		return nil
+	})
+}
+
+func containsUser(users []string, userToFind string) bool {
+	for _, u := range users {
+		if u == userToFind {
+			return true
+		}
+	}
+	return false
+}
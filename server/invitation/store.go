@@ -0,0 +1,143 @@
+package invitation
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/pkg/errors"
+	"stm/util"
+	"strconv"
+	"time"
+)
+
+type invitationRow struct {
+	id             int
+	projectId      int
+	code           string
+	creationDate   *time.Time
+	expirationDate *time.Time
+	maxUses        int
+	uses           int
+}
+
+type store struct {
+	*util.Logger
+	tx    *sql.Tx
+	table string
+}
+
+var (
+	returnValues = "id, project_id, code, creation_date, expiration_date, max_uses, uses"
+)
+
+func getStore(tx *sql.Tx, logger *util.Logger) *store {
+	return &store{
+		Logger: logger,
+		tx:     tx,
+		table:  "project_invitations",
+	}
+}
+
+func (s *store) getInvitationsOfProject(projectId string) ([]*Invitation, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE project_id=$1 ORDER BY id;", returnValues, s.table)
+	s.LogQuery(query, projectId)
+
+	rows, err := s.tx.Query(query, projectId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error executing query to get invitations of project %s", projectId)
+	}
+	defer rows.Close()
+
+	invitations := make([]*Invitation, 0)
+	for rows.Next() {
+		invitation, err := rowToInvitation(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, "error converting row into invitation")
+		}
+
+		invitations = append(invitations, invitation)
+	}
+
+	return invitations, nil
+}
+
+func (s *store) addInvitation(projectId string, code string, draft *DraftDto, creationDate time.Time) (*Invitation, error) {
+	query := fmt.Sprintf("INSERT INTO %s (project_id, code, creation_date, expiration_date, max_uses) VALUES($1, $2, $3, $4, $5) RETURNING %s;", s.table, returnValues)
+	return s.execQuery(query, projectId, code, creationDate, draft.ExpirationDate, draft.MaxUses)
+}
+
+func (s *store) getInvitation(code string) (*Invitation, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE code=$1;", returnValues, s.table)
+	return s.execQuery(query, code)
+}
+
+// useInvitation increases the number of uses of the invitation with the given code. This only works for invitations,
+// which are neither expired nor used up. The check and the update are done in one statement so that concurrent
+// requests can't exceed the maximum number of uses.
+func (s *store) useInvitation(code string, now time.Time) (*Invitation, error) {
+	query := fmt.Sprintf("UPDATE %s SET uses=uses+1 WHERE code=$1 AND (expiration_date IS NULL OR expiration_date > $2) AND (max_uses = 0 OR uses < max_uses) RETURNING %s;", s.table, returnValues)
+	return s.execQuery(query, code, now)
+}
+
+func (s *store) delete(projectId string, invitationId string) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id=$1 AND project_id=$2;", s.table)
+	s.LogQuery(query, invitationId, projectId)
+
+	result, err := s.tx.Exec(query, invitationId, projectId)
+	if err != nil {
+		return errors.Wrapf(err, "error deleting invitation %s of project %s", invitationId, projectId)
+	}
+
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "unable to get number of deleted invitations")
+	}
+	if affectedRows == 0 {
+		return errors.New(fmt.Sprintf("invitation %s does not exist in project %s", invitationId, projectId))
+	}
+
+	return nil
+}
+
+// execQuery executed the given query, turns the result into an Invitation object and closes the query.
+func (s *store) execQuery(query string, params ...interface{}) (*Invitation, error) {
+	s.LogQuery(query, params...)
+	rows, err := s.tx.Query(query, params...)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not run query")
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, errors.New("invitation does not exist or cannot be used anymore")
+	}
+
+	return rowToInvitation(rows)
+}
+
+// rowToInvitation turns the current row into an Invitation object. This does not close the row.
+func rowToInvitation(rows *sql.Rows) (*Invitation, error) {
+	var row invitationRow
+	err := rows.Scan(&row.id, &row.projectId, &row.code, &row.creationDate, &row.expirationDate, &row.maxUses, &row.uses)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not scan rows")
+	}
+
+	result := Invitation{}
+
+	result.Id = strconv.Itoa(row.id)
+	result.ProjectId = strconv.Itoa(row.projectId)
+	result.Code = row.code
+	result.MaxUses = row.maxUses
+	result.Uses = row.uses
+
+	if row.creationDate != nil {
+		t := row.creationDate.UTC()
+		result.CreationDate = &t
+	}
+	if row.expirationDate != nil {
+		t := row.expirationDate.UTC()
+		result.ExpirationDate = &t
+	}
+
+	return &result, nil
+}
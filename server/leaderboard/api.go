@@ -0,0 +1,24 @@
+package leaderboard
+
+import "time"
+
+type SortField string
+
+const (
+	SortByTasks  SortField = "tasks"
+	SortByPoints SortField = "points"
+)
+
+const (
+	DefaultWindow = 30 * 24 * time.Hour // Time window before the end date, when no start date is given.
+	DefaultLimit  = 10
+	MaxLimit      = 100
+)
+
+type FilterDto struct {
+	From       *time.Time // Start of the time window. When NULL, the window starts "DefaultWindow" before its end.
+	To         *time.Time // End of the time window. When NULL, the current time is used.
+	ProjectIds []string   // When not empty, only these projects are considered, otherwise all projects of the requesting user.
+	SortBy     SortField  // The value the users are ranked by.
+	Limit      int        // Maximum number of entries.
+}
@@ -0,0 +1,17 @@
+package leaderboard
+
+import "time"
+
+type Leaderboard struct {
+	From    time.Time `json:"from"`    // UTC date of the start of the time window.
+	To      time.Time `json:"to"`      // UTC date of the end of the time window.
+	SortBy  SortField `json:"sortBy"`  // The value the users are ranked by.
+	Entries []*Entry  `json:"entries"` // The ranked users, best first. Will not be NULL but might be empty.
+}
+
+type Entry struct {
+	Rank              int    `json:"rank"`              // Rank of the user, starting at 1. Users with equal values have the same rank.
+	UserId            string `json:"userId"`            // The ID of the user.
+	FinishedTaskCount int    `json:"finishedTaskCount"` // Number of distinct tasks for which this user set the maximum process points within the time window.
+	ProcessPoints     int    `json:"processPoints"`     // Sum of all process points added by this user within the time window. Reductions (e.g. resetting a task) are ignored.
+}
@@ -0,0 +1,73 @@
+package leaderboard
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/pkg/errors"
+	"stm/permission"
+	"stm/util"
+	"time"
+)
+
+type Service struct {
+	*util.Logger
+	store           *store
+	permissionStore *permission.Store
+}
+
+func Init(tx *sql.Tx, logger *util.Logger, permissionStore *permission.Store) *Service {
+	return &Service{
+		Logger:          logger,
+		store:           getStore(tx, logger),
+		permissionStore: permissionStore,
+	}
+}
+
+// GetLeaderboard ranks all users by their process point changes within the time window of the filter. Only projects
+// the requesting user is a member of are considered, explicitly requesting other projects is an error.
+func (s *Service) GetLeaderboard(filter *FilterDto, requestingUserId string) (*Leaderboard, error) {
+	if filter.Limit < 1 || filter.Limit > MaxLimit {
+		return nil, errors.New(fmt.Sprintf("Limit must be between 1 and %d (%d)", MaxLimit, filter.Limit))
+	}
+
+	for _, projectId := range filter.ProjectIds {
+		err := s.permissionStore.VerifyMembershipProject(projectId, requestingUserId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	to := time.Now().UTC()
+	if filter.To != nil {
+		to = filter.To.UTC()
+	}
+
+	from := to.Add(-DefaultWindow)
+	if filter.From != nil {
+		from = filter.From.UTC()
+	}
+
+	if !from.Before(to) {
+		return nil, errors.New(fmt.Sprintf("start date %s must be before end date %s", from, to))
+	}
+
+	entries, err := s.store.getEntries(requestingUserId, filter.ProjectIds, from, to, filter.SortBy, filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Entries are already sorted, so users with the same values as their predecessor share the rank
+	for i, entry := range entries {
+		entry.Rank = i + 1
+		if i > 0 && entry.FinishedTaskCount == entries[i-1].FinishedTaskCount && entry.ProcessPoints == entries[i-1].ProcessPoints {
+			entry.Rank = entries[i-1].Rank
+		}
+	}
+
+	return &Leaderboard{
+		From:    from,
+		To:      to,
+		SortBy:  filter.SortBy,
+		Entries: entries,
+	}, nil
+}
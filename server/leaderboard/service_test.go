@@ -0,0 +1,180 @@
+package leaderboard
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/hauke96/sigolo"
+	"github.com/pkg/errors"
+	"stm/config"
+	"stm/permission"
+	"stm/test"
+	"stm/util"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq" // Make driver "postgres" usable
+)
+
+var (
+	tx *sql.Tx
+	s  *Service
+	h  *test.Helper
+
+	from = time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC)
+	to   = time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func TestMain(m *testing.M) {
+	h = test.NewTestHelper(setup)
+	m.Run()
+}
+
+func setup() {
+	sigolo.LogLevel = sigolo.LOG_DEBUG
+	config.LoadConfig("../test/test-config.json")
+	h.InitWithDummyData(config.Conf.DbUsername, config.Conf.DbPassword, config.Conf.DbDatabase)
+	tx = h.NewTransaction()
+
+	logger := util.NewLogger()
+
+	permissionStore := permission.Init(tx, logger)
+	s = Init(tx, logger, permissionStore)
+}
+
+func TestGetLeaderboard(t *testing.T) {
+	h.Run(t, func() error {
+		leaderboard, err := s.GetLeaderboard(&FilterDto{From: &from, To: &to, SortBy: SortByTasks, Limit: DefaultLimit}, "Maria")
+		if err != nil {
+			return err
+		}
+
+		err = checkEntries(leaderboard.Entries, "John", "Maria", "Donny", "Clara")
+		if err != nil {
+			return err
+		}
+		if leaderboard.Entries[0].FinishedTaskCount != 1 || leaderboard.Entries[0].ProcessPoints != 100 {
+			return errors.New(fmt.Sprintf("Entry of John not matching: %#v", leaderboard.Entries[0]))
+		}
+		if leaderboard.Entries[1].FinishedTaskCount != 0 || leaderboard.Entries[1].ProcessPoints != 50 {
+			return errors.New(fmt.Sprintf("Entry of Maria not matching: %#v", leaderboard.Entries[1]))
+		}
+
+		// Smaller time window without the changes of John
+		laterFrom := time.Date(2021, 2, 15, 0, 0, 0, 0, time.UTC)
+		leaderboard, err = s.GetLeaderboard(&FilterDto{From: &laterFrom, To: &to, SortBy: SortByPoints, Limit: DefaultLimit}, "Maria")
+		if err != nil {
+			return err
+		}
+
+		err = checkEntries(leaderboard.Entries, "Maria", "Donny", "Clara")
+		if err != nil {
+			return err
+		}
+
+		// Limit
+		leaderboard, err = s.GetLeaderboard(&FilterDto{From: &from, To: &to, SortBy: SortByPoints, Limit: 2}, "Maria")
+		if err != nil {
+			return err
+		}
+
+		return checkEntries(leaderboard.Entries, "John", "Maria")
+	})
+}
+
+func TestGetLeaderboardWithResetTask(t *testing.T) {
+	h.Run(t, func() error {
+		// John resets and finishes task 2 again, Maria reduces the points of task 3
+		_, err := tx.Exec(`INSERT INTO process_point_changes (task_id, user_id, old_points, new_points, creation_date) VALUES
+			(2, 'John', 100, 0, '2021-02-20 08:00:00'),
+			(2, 'John', 0, 100, '2021-02-20 10:00:00'),
+			(3, 'Maria', 50, 20, '2021-02-20 12:00:00');`)
+		if err != nil {
+			return err
+		}
+
+		leaderboard, err := s.GetLeaderboard(&FilterDto{From: &from, To: &to, SortBy: SortByTasks, Limit: DefaultLimit}, "Maria")
+		if err != nil {
+			return err
+		}
+
+		err = checkEntries(leaderboard.Entries, "John", "Maria", "Donny", "Clara")
+		if err != nil {
+			return err
+		}
+		if leaderboard.Entries[0].FinishedTaskCount != 1 || leaderboard.Entries[0].ProcessPoints != 200 {
+			return errors.New(fmt.Sprintf("Entry of John not matching: %#v", leaderboard.Entries[0]))
+		}
+		if leaderboard.Entries[1].FinishedTaskCount != 0 || leaderboard.Entries[1].ProcessPoints != 50 {
+			return errors.New(fmt.Sprintf("Entry of Maria not matching: %#v", leaderboard.Entries[1]))
+		}
+
+		return nil
+	})
+}
+
+func TestGetLeaderboardOfOtherProjects(t *testing.T) {
+	h.Run(t, func() error {
+		// Peter is only member of project 1, where nothing happened
+		leaderboard, err := s.GetLeaderboard(&FilterDto{From: &from, To: &to, SortBy: SortByTasks, Limit: DefaultLimit}, "Peter")
+		if err != nil {
+			return err
+		}
+		err = checkEntries(leaderboard.Entries)
+		if err != nil {
+			return err
+		}
+
+		// Restricted to project 1
+		leaderboard, err = s.GetLeaderboard(&FilterDto{From: &from, To: &to, ProjectIds: []string{"1"}, SortBy: SortByTasks, Limit: DefaultLimit}, "Maria")
+		if err != nil {
+			return err
+		}
+		err = checkEntries(leaderboard.Entries)
+		if err != nil {
+			return err
+		}
+
+		// Maria is not a member of project 3
+		_, err = s.GetLeaderboard(&FilterDto{From: &from, To: &to, ProjectIds: []string{"3"}, SortBy: SortByTasks, Limit: DefaultLimit}, "Maria")
+		if err == nil {
+			return errors.New("Getting leaderboard of foreign project should not work")
+		}
+
+		return nil
+	})
+}
+
+func TestGetLeaderboardWithInvalidFilter(t *testing.T) {
+	h.Run(t, func() error {
+		_, err := s.GetLeaderboard(&FilterDto{From: &from, To: &to, SortBy: "comments", Limit: DefaultLimit}, "Maria")
+		if err == nil {
+			return errors.New("Unknown sort field should not be allowed")
+		}
+
+		_, err = s.GetLeaderboard(&FilterDto{From: &from, To: &to, SortBy: SortByTasks, Limit: 0}, "Maria")
+		if err == nil {
+			return errors.New("Limit of 0 should not be allowed")
+		}
+
+		_, err = s.GetLeaderboard(&FilterDto{From: &to, To: &from, SortBy: SortByTasks, Limit: DefaultLimit}, "Maria")
+		if err == nil {
+			return errors.New("Start date after end date should not be allowed")
+		}
+
+		return nil
+	})
+}
+
+func checkEntries(entries []*Entry, expectedUserIds ...string) error {
+	if len(entries) != len(expectedUserIds) {
+		return errors.New(fmt.Sprintf("Expected %d entries but got %d", len(expectedUserIds), len(entries)))
+	}
+
+	for i, entry := range entries {
+		if entry.UserId != expectedUserIds[i] || entry.Rank != i+1 {
+			return errors.New(fmt.Sprintf("Expected %s on rank %d but got %#v", expectedUserIds[i], i+1, entry))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package leaderboard
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"stm/util"
+	"time"
+)
+
+type store struct {
+	*util.Logger
+	tx                      *sql.Tx
+	processPointChangeTable string
+	taskTable               string
+	projectMemberTable      string
+}
+
+var (
+	// The secondary sort expression makes the order (and therefore the ranks) of users with equal primary values stable
+	sortExpressions = map[SortField]string{
+		SortByTasks:  "finished_tasks DESC, points DESC",
+		SortByPoints: "points DESC, finished_tasks DESC",
+	}
+)
+
+func getStore(tx *sql.Tx, logger *util.Logger) *store {
+	return &store{
+		Logger:                  logger,
+		tx:                      tx,
+		processPointChangeTable: "process_point_changes",
+		taskTable:               "tasks",
+		projectMemberTable:      "project_members",
+	}
+}
+
+// getEntries returns the sorted but not yet ranked entries of all users who changed process points in the given time
+// window. Only projects the given user is a member of are considered. An empty list of project IDs means all projects.
+// The points only sum up the increases, so reducing or resetting a task doesn't lower the score of a user. A task that
+// has been finished several times by the same user (e.g. after a reset) counts as one finished task.
+func (s *store) getEntries(userId string, projectIds []string, from time.Time, to time.Time, sortBy SortField, limit int) ([]*Entry, error) {
+	sortExpression, ok := sortExpressions[sortBy]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("unknown sort field '%s'", sortBy))
+	}
+
+	rawQueryString := `
+SELECT c.user_id,
+	COUNT(DISTINCT c.task_id) FILTER (WHERE c.new_points = t.max_process_points AND c.old_points < t.max_process_points) AS finished_tasks,
+	SUM(GREATEST(c.new_points - c.old_points, 0)) AS points
+FROM %s c
+	JOIN %s t ON t.id = c.task_id
+	JOIN %s m ON m.project_id = t.project_id
+WHERE m.user_id = $1 AND (CARDINALITY($2::INT[]) = 0 OR t.project_id = ANY($2)) AND c.creation_date >= $3 AND c.creation_date < $4
+GROUP BY c.user_id
+ORDER BY %s, c.user_id
+LIMIT $5;`
+
+	// The sort expression comes from fixed values above and never from the user, so no SQL injection possible
+	query := fmt.Sprintf(rawQueryString, s.processPointChangeTable, s.taskTable, s.projectMemberTable, sortExpression)
+	s.LogQuery(query, userId, projectIds, from, to, limit)
+
+	rows, err := s.tx.Query(query, userId, pq.Array(projectIds), from, to, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "error executing query")
+	}
+	defer rows.Close()
+
+	entries := make([]*Entry, 0)
+	for rows.Next() {
+		entry := &Entry{}
+
+		err = rows.Scan(&entry.UserId, &entry.FinishedTaskCount, &entry.ProcessPoints)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not scan rows")
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,23 @@
+package permission
+
+// AssignmentPolicy determines whether users have to be assigned to a task before they can set its process points.
+type AssignmentPolicy string
+
+const (
+	AssignmentAlways           AssignmentPolicy = "ALWAYS"
+	AssignmentNever            AssignmentPolicy = "NEVER"
+	AssignmentAboveMemberCount AssignmentPolicy = "ABOVE_MEMBER_COUNT" // Only needed when the project has more members than the threshold.
+)
+
+// AssignmentNeeded applies the assignment policy of a project. The default policy ABOVE_MEMBER_COUNT with a threshold
+// of 1 means that tasks in a project with only one user (the owner) don't need an assignment.
+func AssignmentNeeded(policy AssignmentPolicy, memberThreshold int, memberCount int) bool {
+	switch policy {
+	case AssignmentAlways:
+		return true
+	case AssignmentNever:
+		return false
+	default:
+		return memberCount > memberThreshold
+	}
+}
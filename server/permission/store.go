@@ -14,8 +14,15 @@ type Store struct {
 }
 
 var (
-	taskTable    = "tasks"
-	projectTable = "projects"
+	taskTable          = "tasks"
+	projectTable       = "projects"
+	projectMemberTable = "project_members"
+	campaignTable      = "campaigns"
+	teamTable          = "teams"
+	commentTable       = "comments"
+
+	// The assignment policy, member threshold and number of members of the project "p" as needed by AssignmentNeeded.
+	assignmentPolicyColumns = fmt.Sprintf("p.assignment_policy, p.assignment_member_threshold, (SELECT COUNT(*) FROM %s m WHERE m.project_id = p.id)", projectMemberTable)
 )
 
 // Init the permission store for the project and task table.
@@ -45,9 +52,10 @@ func (s *Store) VerifyOwnership(projectId string, user string) error {
 	return nil
 }
 
-// VerifyMembershipProject checks if "user" is a member of the project "id".
+// VerifyMembershipProject checks if "user" is a member of the project "id". This is the case when the user is a direct
+// member of the project or a member of a team added to the project.
 func (s *Store) VerifyMembershipProject(projectId string, user string) error {
-	query := fmt.Sprintf("SELECT * FROM %s WHERE id=$1 AND $2=ANY(users)", projectTable)
+	query := fmt.Sprintf("SELECT project_id FROM %s WHERE project_id=$1 AND user_id=$2", projectMemberTable)
 
 	s.LogQuery(query, projectId, user)
 	rows, err := s.tx.Query(query, projectId, user)
@@ -66,7 +74,7 @@ func (s *Store) VerifyMembershipProject(projectId string, user string) error {
 
 // VerifyMembershipTask checks if "user" is a member of the project, where the given task with "id" is in.
 func (s *Store) VerifyMembershipTask(taskId string, user string) error {
-	query := fmt.Sprintf("SELECT * FROM %s p, %s t WHERE t.project_id = p.id AND t.id = $1 AND $2=ANY(p.users);", projectTable, taskTable)
+	query := fmt.Sprintf("SELECT t.id FROM %s m, %s t WHERE t.project_id = m.project_id AND t.id = $1 AND m.user_id = $2;", projectMemberTable, taskTable)
 
 	s.LogQuery(query, taskId, user)
 	rows, err := s.tx.Query(query, taskId, user)
@@ -85,7 +93,7 @@ func (s *Store) VerifyMembershipTask(taskId string, user string) error {
 
 // VerifyMembershipTask checks if "user" is a member of the projects, where the given tasks are in.
 func (s *Store) VerifyMembershipTasks(taskIds []string, user string) error {
-	query := fmt.Sprintf("SELECT COUNT(*) FROM %s p, %s t WHERE t.project_id = p.id AND t.id = ANY($1) AND $2=ANY(p.users);", projectTable, taskTable)
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s m, %s t WHERE t.project_id = m.project_id AND t.id = ANY($1) AND m.user_id = $2;", projectMemberTable, taskTable)
 
 	s.LogQuery(query, pq.Array(taskIds), user)
 	rows, err := s.tx.Query(query, pq.Array(taskIds), user)
@@ -112,6 +120,125 @@ func (s *Store) VerifyMembershipTasks(taskIds []string, user string) error {
 	return nil
 }
 
+// VerifyCampaignOwnership checks if the given user is the owner (campaign manager) of the given campaign.
+func (s *Store) VerifyCampaignOwnership(campaignId string, user string) error {
+	query := fmt.Sprintf("SELECT id FROM %s WHERE id=$1 AND owner=$2", campaignTable)
+
+	s.LogQuery(query, campaignId, user)
+	rows, err := s.tx.Query(query, campaignId, user)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("error verifying ownership of user %s in campaign %s", user, campaignId))
+	}
+	defer rows.Close()
+
+	// If there's a next row, then the user "user" is the owner of the campaign "campaignId"
+	if !rows.Next() {
+		return errors.New(fmt.Sprintf("user %s is not the owner of campaign %s", user, campaignId))
+	}
+
+	return nil
+}
+
+// VerifyMembershipCampaign checks if "user" is a member of the campaign "campaignId". Being a member of a campaign does
+// not make the user a member of the projects of the campaign.
+func (s *Store) VerifyMembershipCampaign(campaignId string, user string) error {
+	query := fmt.Sprintf("SELECT id FROM %s WHERE id=$1 AND $2=ANY(users)", campaignTable)
+
+	s.LogQuery(query, campaignId, user)
+	rows, err := s.tx.Query(query, campaignId, user)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("error verifying membership of user %s in campaign %s", user, campaignId))
+	}
+	defer rows.Close()
+
+	// If there's a next row, then the user "user" is in the list of members of campaign "campaignId"
+	if !rows.Next() {
+		return errors.New(fmt.Sprintf("user %s is not a member of campaign %s", user, campaignId))
+	}
+
+	return nil
+}
+
+// VerifyTeamAdmin checks if the given user is an admin of the given team.
+func (s *Store) VerifyTeamAdmin(teamId string, user string) error {
+	query := fmt.Sprintf("SELECT id FROM %s WHERE id=$1 AND $2=ANY(admins)", teamTable)
+
+	s.LogQuery(query, teamId, user)
+	rows, err := s.tx.Query(query, teamId, user)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("error verifying admin permission of user %s in team %s", user, teamId))
+	}
+	defer rows.Close()
+
+	// If there's a next row, then the user "user" is in the list of admins of team "teamId"
+	if !rows.Next() {
+		return errors.New(fmt.Sprintf("user %s is not an admin of team %s", user, teamId))
+	}
+
+	return nil
+}
+
+// VerifyMembershipTeam checks if "user" is a member of the team "teamId".
+func (s *Store) VerifyMembershipTeam(teamId string, user string) error {
+	query := fmt.Sprintf("SELECT id FROM %s WHERE id=$1 AND $2=ANY(users)", teamTable)
+
+	s.LogQuery(query, teamId, user)
+	rows, err := s.tx.Query(query, teamId, user)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("error verifying membership of user %s in team %s", user, teamId))
+	}
+	defer rows.Close()
+
+	// If there's a next row, then the user "user" is in the list of members of team "teamId"
+	if !rows.Next() {
+		return errors.New(fmt.Sprintf("user %s is not a member of team %s", user, teamId))
+	}
+
+	return nil
+}
+
+// VerifyCommentAuthor checks if "user" is the author of the comment "commentId".
+func (s *Store) VerifyCommentAuthor(commentId string, user string) error {
+	query := fmt.Sprintf("SELECT id FROM %s WHERE id=$1 AND author_id=$2", commentTable)
+
+	s.LogQuery(query, commentId, user)
+	rows, err := s.tx.Query(query, commentId, user)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("error verifying authorship of user %s for comment %s", user, commentId))
+	}
+	defer rows.Close()
+
+	// If there's a next row, then the user "user" wrote the comment "commentId"
+	if !rows.Next() {
+		return errors.New(fmt.Sprintf("user %s is not the author of comment %s", user, commentId))
+	}
+
+	return nil
+}
+
+// VerifyCanChangeIssueStatus returns an error when the given user is neither the author of the comment, the user
+// assigned to the task of the comment nor the owner of the project the comment belongs to.
+func (s *Store) VerifyCanChangeIssueStatus(commentId string, user string) error {
+	query := fmt.Sprintf(`SELECT c.id FROM %s c
+	LEFT JOIN %s t ON t.comment_list_id = c.comment_list_id
+	LEFT JOIN %s p ON p.comment_list_id = c.comment_list_id OR p.id = t.project_id
+WHERE c.id = $1 AND (c.author_id = $2 OR t.assigned_user = $2 OR p.owner = $2);`, commentTable, taskTable, projectTable)
+
+	s.LogQuery(query, commentId, user)
+	rows, err := s.tx.Query(query, commentId, user)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("error verifying permission of user %s to change issue status of comment %s", user, commentId))
+	}
+	defer rows.Close()
+
+	// If there's a next row, then the user is the author, assignee or owner
+	if !rows.Next() {
+		return errors.New(fmt.Sprintf("user %s is not allowed to change the issue status of comment %s", user, commentId))
+	}
+
+	return nil
+}
+
 // VerifyCanUnassign returns an error when the given user is not allowed to unassign the current user of the given task.
 func (s *Store) VerifyCanUnassign(taskId string, user string) error {
 	// Get task only if the given user is assigned OR the given user is the owner of the project.
@@ -132,9 +259,39 @@ func (s *Store) VerifyCanUnassign(taskId string, user string) error {
 	return nil
 }
 
+// VerifyAssignmentLimit returns an error when the given user is already assigned to the maximum number of tasks allowed
+// in the project of the given task. Finished tasks the user is still assigned to don't count.
+func (s *Store) VerifyAssignmentLimit(taskId string, user string) error {
+	query := fmt.Sprintf("SELECT p.max_assigned_tasks, (SELECT COUNT(*) FROM %s a WHERE a.project_id = p.id AND a.assigned_user = $2 AND a.process_points < a.max_process_points) FROM %s p, %s t WHERE t.id = $1 AND t.project_id = p.id;", taskTable, projectTable, taskTable)
+
+	s.LogQuery(query, taskId, user)
+	rows, err := s.tx.Query(query, taskId, user)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("error getting assignment limit for task %s", taskId))
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return errors.New(fmt.Sprintf("no row to get assignment limit for task %s", taskId))
+	}
+
+	var maxAssignedTasks, assignedTasks int
+	err = rows.Scan(&maxAssignedTasks, &assignedTasks)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("error reading row to get assignment limit for task %s", taskId))
+	}
+
+	// A limit of 0 means that there's no limit
+	if maxAssignedTasks > 0 && assignedTasks >= maxAssignedTasks {
+		return errors.New(fmt.Sprintf("user %s is already assigned to %d unfinished tasks, which is the maximum in the project of task %s", user, assignedTasks, taskId))
+	}
+
+	return nil
+}
+
 // AssignmentInProjectNeeded determines whether a user needs to be assigned to tasks in this project.
 func (s *Store) AssignmentInProjectNeeded(projectId string) (bool, error) {
-	query := fmt.Sprintf("SELECT ARRAY_LENGTH(users, 1) FROM %s WHERE id=$1;", projectTable)
+	query := fmt.Sprintf("SELECT %s FROM %s p WHERE p.id=$1;", assignmentPolicyColumns, projectTable)
 
 	s.LogQuery(query, projectId)
 	rows, err := s.tx.Query(query, projectId)
@@ -147,19 +304,19 @@ func (s *Store) AssignmentInProjectNeeded(projectId string) (bool, error) {
 		return true, errors.New(fmt.Sprintf("no row to get assignment requirement for project %s", projectId))
 	}
 
-	var userCount int
-	err = rows.Scan(&userCount)
+	var policy AssignmentPolicy
+	var memberThreshold, memberCount int
+	err = rows.Scan(&policy, &memberThreshold, &memberCount)
 	if err != nil {
 		return true, errors.Wrap(err, fmt.Sprintf("error reading row to get assignment requirement for project %s", projectId))
 	}
 
-	// Tasks in a project with only one user (the owner) don't need an assignment
-	return userCount != 1, nil
+	return AssignmentNeeded(policy, memberThreshold, memberCount), nil
 }
 
 // AssignmentInTaskNeeded determines whether a user needs to be assigned to this task.
 func (s *Store) AssignmentInTaskNeeded(taskId string) (bool, error) {
-	query := fmt.Sprintf("SELECT ARRAY_LENGTH(p.users, 1) FROM %s p, %s t WHERE $1 = t.id AND t.project_id = p.id;", projectTable, taskTable)
+	query := fmt.Sprintf("SELECT %s FROM %s p, %s t WHERE $1 = t.id AND t.project_id = p.id;", assignmentPolicyColumns, projectTable, taskTable)
 
 	s.LogQuery(query, taskId)
 	rows, err := s.tx.Query(query, taskId)
@@ -172,12 +329,12 @@ func (s *Store) AssignmentInTaskNeeded(taskId string) (bool, error) {
 		return true, errors.New(fmt.Sprintf("no row to get assignment requirement for task %s", taskId))
 	}
 
-	var userCount int
-	err = rows.Scan(&userCount)
+	var policy AssignmentPolicy
+	var memberThreshold, memberCount int
+	err = rows.Scan(&policy, &memberThreshold, &memberCount)
 	if err != nil {
 		return true, errors.Wrap(err, fmt.Sprintf("error reading row to get assignment requirement for task %s", taskId))
 	}
 
-	// Tasks in a project with only one user (the owner) don't need an assignment
-	return userCount != 1, nil
+	return AssignmentNeeded(policy, memberThreshold, memberCount), nil
 }
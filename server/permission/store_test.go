@@ -94,6 +94,114 @@ func TestVerifyMembershipProject(t *testing.T) {
 			return fmt.Errorf("Not existing project, this should not work")
 		}
 
+		// member through a team
+
+		err = s.VerifyMembershipProject("1", "Otto")
+		if err == nil {
+			return fmt.Errorf("Otto is not a member yet")
+		}
+
+		_, err = tx.Exec("INSERT INTO project_teams (project_id, team_id) VALUES (1, 1);")
+		if err != nil {
+			return err
+		}
+
+		err = s.VerifyMembershipProject("1", "Otto")
+		if err != nil {
+			return fmt.Errorf("Otto is a member through team 1: %s", err.Error())
+		}
+
+		err = s.VerifyMembershipTask("1", "Otto")
+		if err != nil {
+			return fmt.Errorf("Otto is a member of the project of task 1 through team 1: %s", err.Error())
+		}
+
+		return nil
+	})
+}
+
+func TestVerifyTeamAdmin(t *testing.T) {
+	h.Run(t, func() error {
+		err := s.VerifyTeamAdmin("1", "Anna")
+		if err != nil {
+			return fmt.Errorf("Anna is indeed an admin: %s", err.Error())
+		}
+
+		// member but not admin
+
+		err = s.VerifyTeamAdmin("1", "Otto")
+		if err == nil {
+			return fmt.Errorf("Otto is not an admin")
+		}
+
+		// not existing team
+
+		err = s.VerifyTeamAdmin("1345436", "Anna")
+		if err == nil {
+			return fmt.Errorf("Not existing team, this should not work")
+		}
+
+		return nil
+	})
+}
+
+func TestVerifyMembershipTeam(t *testing.T) {
+	h.Run(t, func() error {
+		err := s.VerifyMembershipTeam("1", "Otto")
+		if err != nil {
+			return fmt.Errorf("Otto is indeed a member: %s", err.Error())
+		}
+
+		// not a member
+
+		err = s.VerifyMembershipTeam("1", "Peter")
+		if err == nil {
+			return fmt.Errorf("Peter is not a member")
+		}
+
+		return nil
+	})
+}
+
+func TestVerifyCampaignOwnership(t *testing.T) {
+	h.Run(t, func() error {
+		err := s.VerifyCampaignOwnership("1", "Maria")
+		if err != nil {
+			return fmt.Errorf("Maria is indeed the owner: %s", err.Error())
+		}
+
+		// member but not owner
+
+		err = s.VerifyCampaignOwnership("1", "John")
+		if err == nil {
+			return fmt.Errorf("John is not the owner")
+		}
+
+		// not existing campaign
+
+		err = s.VerifyCampaignOwnership("1345436", "Maria")
+		if err == nil {
+			return fmt.Errorf("Not existing campaign, this should not work")
+		}
+
+		return nil
+	})
+}
+
+func TestVerifyMembershipCampaign(t *testing.T) {
+	h.Run(t, func() error {
+		err := s.VerifyMembershipCampaign("1", "John")
+		if err != nil {
+			return fmt.Errorf("John is indeed a member: %s", err.Error())
+		}
+
+		// member of a project of the campaign but not of the campaign itself
+
+		err = s.VerifyMembershipCampaign("1", "Anna")
+		if err == nil {
+			return fmt.Errorf("Anna is not a member")
+		}
+
 		return nil
 	})
 }
@@ -184,6 +292,44 @@ func TestVerifyAssignment(t *testing.T) {
 	})
 }
 
+func TestVerifyAssignmentLimit(t *testing.T) {
+	h.Run(t, func() error {
+		// no limit by default
+		err := s.VerifyAssignmentLimit("4", "Maria")
+		if err != nil {
+			return fmt.Errorf("There should be no limit: %s", err.Error())
+		}
+
+		_, err = tx.Exec("UPDATE projects SET max_assigned_tasks = 1 WHERE id = 2;")
+		if err != nil {
+			return err
+		}
+
+		// Maria is already assigned to task 3
+		err = s.VerifyAssignmentLimit("4", "Maria")
+		if err == nil {
+			return fmt.Errorf("Maria already reached the limit")
+		}
+
+		err = s.VerifyAssignmentLimit("4", "John")
+		if err != nil {
+			return fmt.Errorf("John is not assigned to any task yet: %s", err.Error())
+		}
+
+		// Finished tasks don't count
+		_, err = tx.Exec("UPDATE tasks SET process_points = max_process_points WHERE id = 3;")
+		if err != nil {
+			return err
+		}
+		err = s.VerifyAssignmentLimit("4", "Maria")
+		if err != nil {
+			return fmt.Errorf("Maria is only assigned to a finished task: %s", err.Error())
+		}
+
+		return nil
+	})
+}
+
 func TestAssignmentInProjectNeeded(t *testing.T) {
 	h.Run(t, func() error {
 		assignmentNeeded, err := s.AssignmentInProjectNeeded("3")
@@ -206,6 +352,36 @@ func TestAssignmentInProjectNeeded(t *testing.T) {
 			return fmt.Errorf("Should need assignments")
 		}
 
+		// explicit policies
+
+		_, err = tx.Exec("UPDATE projects SET assignment_policy = 'NEVER' WHERE id = 2;")
+		if err != nil {
+			return err
+		}
+		assignmentNeeded, err = s.AssignmentInProjectNeeded("2")
+		if err != nil || assignmentNeeded {
+			return fmt.Errorf("Should not need assignments with policy NEVER")
+		}
+
+		_, err = tx.Exec("UPDATE projects SET assignment_policy = 'ALWAYS' WHERE id = 3;")
+		if err != nil {
+			return err
+		}
+		assignmentNeeded, err = s.AssignmentInProjectNeeded("3")
+		if err != nil || !assignmentNeeded {
+			return fmt.Errorf("Should need assignments with policy ALWAYS")
+		}
+
+		// project 2 has six members
+		_, err = tx.Exec("UPDATE projects SET assignment_policy = 'ABOVE_MEMBER_COUNT', assignment_member_threshold = 6 WHERE id = 2;")
+		if err != nil {
+			return err
+		}
+		assignmentNeeded, err = s.AssignmentInProjectNeeded("2")
+		if err != nil || assignmentNeeded {
+			return fmt.Errorf("Should not need assignments with six members and threshold 6")
+		}
+
 		return nil
 	})
 }
@@ -242,3 +418,24 @@ func TestAssignmentInTaskNeeded(t *testing.T) {
 		return nil
 	})
 }
+
+func TestAssignmentNeeded(t *testing.T) {
+	cases := []struct {
+		policy          AssignmentPolicy
+		memberThreshold int
+		memberCount     int
+		expected        bool
+	}{
+		{AssignmentAboveMemberCount, 1, 1, false},
+		{AssignmentAboveMemberCount, 1, 2, true},
+		{AssignmentAboveMemberCount, 2, 2, false},
+		{AssignmentAlways, 1, 1, true},
+		{AssignmentNever, 1, 2, false},
+	}
+
+	for i, c := range cases {
+		if AssignmentNeeded(c.policy, c.memberThreshold, c.memberCount) != c.expected {
+			t.Errorf("Case %d: expected AssignmentNeeded to be %t", i, c.expected)
+		}
+	}
+}
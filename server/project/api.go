@@ -1,6 +1,54 @@
 package project
 
-import "stm/task"
+import (
+	"stm/comment"
+	"stm/task"
+	"time"
+)
+
+type SummarySortField string
+
+const (
+	SortByCreationDate SummarySortField = "creationDate"
+	SortByName         SummarySortField = "name"
+	SortByProgress     SummarySortField = "progress"
+)
+
+const (
+	OverpassBboxPlaceholder = "{{bbox}}" // Placeholder in Overpass query templates, which is replaced by the bounding box of the task.
+	MaxOverpassQueryLength  = 10000
+	MaxImageryLayers        = 10
+)
+
+const (
+	MaxChangesetTagLength = 255 // Maximum length of a changeset tag value in OSM.
+)
+
+const (
+	JosmRemoteControlUrl = "http://localhost:8111"
+	OverpassApiUrl       = "https://overpass-api.de/api/interpreter"
+	IdEditorUrl          = "https://www.openstreetmap.org/edit?editor=id"
+	IdViewportWidth      = 1280 // Assumed size of the iD map in pixels to determine the zoom level for the task area.
+	IdViewportHeight     = 720
+)
+
+const (
+	DefaultSummaryPageSize = 20
+	MaxSummaryPageSize     = 100
+)
+
+type ProgressInterval string
+
+const (
+	IntervalHour ProgressInterval = "hour"
+	IntervalDay  ProgressInterval = "day"
+	IntervalWeek ProgressInterval = "week"
+)
+
+const (
+	MaxProgressPoints = 1000               // Maximum number of points in one progress time series.
+	VelocityWindow    = 7 * 24 * time.Hour // Time span before now that is used to determine the current velocity of a project.
+)
 
 type AddDto struct {
 	Project DraftDto        `json:"project"`
@@ -13,10 +61,60 @@ type DraftDto struct {
 	Users          []string       `json:"users"`          // A non-empty list of user-IDs. At least the owner should be in here.
 	Owner          string         `json:"owner"`          // The user-ID who created this project. Must not be NULL or empty.
 	JosmDataSource JosmDataSource `json:"josmDataSource"` // The source JOSM should load the data from when opening a task in JOSM.
+	ChangesetDto
 }
 
 type UpdateDto struct {
 	Name           string         `json:"name"`           // Name of the project. Must not be NULL or empty.
 	Description    string         `json:"description"`    // Description of the project. Must not be NULL but cam be empty.
 	JosmDataSource JosmDataSource `json:"josmDataSource"` // The source JOSM should load the data from when opening a task in JOSM.
+	StartDate      *time.Time     `json:"startDate"`      // UTC Date in RFC 3339 format when the work on the project starts. Can be NULL to remove the date, stays unchanged when omitted. Since API v2.10.
+	DueDate        *time.Time     `json:"dueDate"`        // UTC Date in RFC 3339 format when the project should be finished. Must be after the start date. Can be NULL to remove the date, stays unchanged when omitted. Since API v2.10.
+	ChangesetDto                  // Omitted changeset settings stay unchanged. Since API v2.10.
+}
+
+// ChangesetDto contains the changeset tags mappers should use for a project. All fields can be empty.
+type ChangesetDto struct {
+	ChangesetComment  string   `json:"changesetComment"`  // Changeset comment, at most 255 characters.
+	ChangesetSource   string   `json:"changesetSource"`   // Changeset source, at most 255 characters.
+	ChangesetHashtags []string `json:"changesetHashtags"` // Hashtags, each starting with "#" and without whitespaces, commas and semicolons. Can be NULL.
+}
+
+type VisibilityDto struct {
+	IsPublic              bool `json:"isPublic"`              // When "true", everyone (also people without login) can see a sanitised read-only view of the project.
+	PublicUsersVisible    bool `json:"publicUsersVisible"`    // When "true", the public view contains the user-IDs of the members and assigned users.
+	PublicCommentsVisible bool `json:"publicCommentsVisible"` // When "true", the public view contains the comments (incl. their author-IDs).
+}
+
+type JosmConfigDto struct {
+	JosmDataSource  JosmDataSource `json:"josmDataSource"`  // The source JOSM should load the data from when opening a task in JOSM.
+	OverpassQuery   string         `json:"overpassQuery"`   // Optional query template for data source "OVERPASS", which must contain the placeholder "{{bbox}}". Must be empty for other data sources.
+	JosmDownloadUrl string         `json:"josmDownloadUrl"` // HTTP(S) URL to load the data from. Required for data source "CUSTOM" and must be empty for other data sources.
+	Imagery         []ImageryLayer `json:"imagery"`         // Imagery layers JOSM should load. Can be NULL or empty.
+}
+
+type AssignmentDto struct {
+	Policy           AssignmentPolicy `json:"policy"`           // One of "ALWAYS", "NEVER" and "ABOVE_MEMBER_COUNT".
+	Threshold        int              `json:"threshold"`        // Number of members above which assignments are needed. Only used for policy "ABOVE_MEMBER_COUNT". Must not be negative.
+	MaxAssignedTasks int              `json:"maxAssignedTasks"` // Maximum number of tasks a user can be assigned to at the same time, finished tasks don't count. 0 means no limit. Must not be negative.
+}
+
+type SummaryFilterDto struct {
+	Page       int              // Number of the page to get, starting at 1.
+	PageSize   int              // Maximum number of summaries per page.
+	SortBy     SummarySortField // The field to sort the summaries by.
+	Descending bool             // When "true", the summaries are sorted in descending order.
+	Search     string           // When not empty, only projects containing this text in their name (case-insensitive) are returned.
+}
+
+type ProgressFilterDto struct {
+	From     *time.Time       // Start of the time series. When NULL, the creation date of the project (but at most MaxProgressPoints intervals before the end) is used.
+	To       *time.Time       // End of the time series. When NULL, the current time is used.
+	Interval ProgressInterval // The time between two points of the time series.
+}
+
+// CommentResultDto is the result of adding or editing a comment.
+type CommentResultDto struct {
+	Project *Project         `json:"project"` // The project the comment belongs to, incl. the changed comment.
+	Comment *comment.Comment `json:"comment"` // The added or edited comment incl. possible warnings.
 }
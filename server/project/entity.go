@@ -2,6 +2,7 @@ package project
 
 import (
 	"stm/comment"
+	"stm/permission"
 	"stm/task"
 	"time"
 )
@@ -11,6 +12,32 @@ type JosmDataSource string
 const (
 	OSM      JosmDataSource = "OSM"
 	Overpass JosmDataSource = "OVERPASS"
+	Custom   JosmDataSource = "CUSTOM" // Data is loaded from the download URL of the project.
+)
+
+type ImageryType string
+
+const (
+	ImageryTms ImageryType = "TMS"
+	ImageryWms ImageryType = "WMS"
+)
+
+// ImageryLayer is an imagery JOSM should load when opening a task of the project.
+type ImageryLayer struct {
+	Name        string      `json:"name"`        // Name of the layer shown in JOSM. Will not be NULL or empty.
+	Url         string      `json:"url"`         // TMS or WMS URL in the format JOSM expects, e.g. with "{zoom}", "{x}" and "{y}" placeholders for TMS. Will not be NULL or empty.
+	Type        ImageryType `json:"type"`        // Either "TMS" or "WMS".
+	Attribution string      `json:"attribution"` // Attribution of the imagery. Will not be NULL but might be empty.
+}
+
+// AssignmentPolicy determines whether users have to be assigned to a task before they can set its process points. The
+// policies are defined in the permission package, which applies them as well.
+type AssignmentPolicy = permission.AssignmentPolicy
+
+const (
+	AssignmentAlways           = permission.AssignmentAlways
+	AssignmentNever            = permission.AssignmentNever
+	AssignmentAboveMemberCount = permission.AssignmentAboveMemberCount
 )
 
 type Project struct {
@@ -18,14 +45,141 @@ type Project struct {
 	Name  string       `json:"name"`  // The name of the project. Will not be NULL or empty.
 	Tasks []*task.Task `json:"tasks"` // List of tasks of the project. Will not be NULL or empty.
 	// TODO Use "Ids" as suffix?
-	Users []string `json:"users"` // Array of user-IDs (=members of this project). Will not be NULL or empty.
+	Users []string `json:"users"` // Array of user-IDs (=members of this project incl. the members of its teams). Will not be NULL or empty.
+	// Array of user-IDs of the members added directly to this project, so without the members of its teams. Will not be
+	// NULL but might be empty, when all members are only members via a team.
+	DirectUsers []string `json:"directUsers"`
+	Teams       []string `json:"teams"` // IDs of the teams added to this project. All team members are members of this project. Will not be NULL.
+	// Members who are not allowed to write comments, e.g. because they spammed. Will not be NULL but might be empty.
+	ReadOnlyUsers []string `json:"readOnlyUsers"`
 	// TODO Use "Id" as suffix?
-	Owner              string            `json:"owner"`              // User-ID of the owner/creator of this project. Will not be NULL or empty.
-	Description        string            `json:"description"`        // Some description, can be empty. Will not be NULL but might be empty.
-	NeedsAssignment    bool              `json:"needsAssignment"`    // When "true", the tasks of this project need to have an assigned user.
+	Owner                 string            `json:"owner"`                 // User-ID of the owner/creator of this project. Will not be NULL or empty.
+	Description           string            `json:"description"`           // Some description in Markdown, can be empty. Will not be NULL but might be empty.
+	DescriptionHtml       string            `json:"descriptionHtml"`       // The sanitised HTML of the description, see util.RenderMarkdown. Will not be NULL but might be empty.
+	NeedsAssignment       bool              `json:"needsAssignment"`       // When "true", the tasks of this project need to have an assigned user.
+	TotalProcessPoints    int               `json:"totalProcessPoints"`    // Sum of all maximum process points of all tasks.
+	DoneProcessPoints     int               `json:"doneProcessPoints"`     // Sum of all process points that have been set. It applies "0 <= doneProcessPoints <= totalProcessPoints".
+	OpenIssueCount        int               `json:"openIssueCount"`        // Number of comments (incl. replies) on the project and all its tasks which are open issues.
+	CreationDate          *time.Time        `json:"creationDate"`          // UTC Date in RFC 3339 format, can be NIL because of old data in the database. Example: "2006-01-02 15:04:05.999999999 -0700 MST"
+	Comments              []comment.Comment `json:"comments"`              // The comment on the project.
+	JosmDataSource        JosmDataSource    `json:"josmDataSource"`        // The source JOSM should load the data from when opening a task in JOSM.
+	OverpassQuery         string            `json:"overpassQuery"`         // Overpass query template used with data source "OVERPASS". Empty when the default query should be used.
+	JosmDownloadUrl       string            `json:"josmDownloadUrl"`       // URL to load the data from with data source "CUSTOM". Empty for all other data sources.
+	Imagery               []ImageryLayer    `json:"imagery"`               // Imagery layers JOSM should load when opening a task in JOSM. Will not be NULL but might be empty.
+	ChangesetComment      string            `json:"changesetComment"`      // Changeset comment mappers should use. Will not be NULL but might be empty, then a default comment is used in editor links.
+	ChangesetSource       string            `json:"changesetSource"`       // Changeset source mappers should use. Will not be NULL but might be empty.
+	ChangesetHashtags     []string          `json:"changesetHashtags"`     // Hashtags (each starting with "#") mappers should use. Will not be NULL but might be empty, then default hashtags are used in editor links.
+	IsPublic              bool              `json:"isPublic"`              // When "true", everyone (also people without login) can see a sanitised read-only view of this project.
+	PublicUsersVisible    bool              `json:"publicUsersVisible"`    // When "true", the public view contains the user-IDs of the members and assigned users.
+	PublicCommentsVisible bool              `json:"publicCommentsVisible"` // When "true", the public view contains the comments (incl. their author-IDs) of the project and its tasks.
+	StartDate             *time.Time        `json:"startDate"`             // UTC Date in RFC 3339 format when the work on the project starts. Can be NULL.
+	DueDate               *time.Time        `json:"dueDate"`               // UTC Date in RFC 3339 format when the project should be finished. Can be NULL.
+	IsOverdue             bool              `json:"isOverdue"`             // When "true", the due date passed but the project isn't finished yet.
+	IsAtRisk              bool              `json:"isAtRisk"`              // When "true", the project isn't overdue yet but less work has been done than time passed between start and due date.
+	AssignmentPolicy      AssignmentPolicy  `json:"assignmentPolicy"`      // Determines the value of "needsAssignment".
+	AssignmentThreshold   int               `json:"assignmentThreshold"`   // Number of members above which assignments are needed. Only used for policy "ABOVE_MEMBER_COUNT".
+	MaxAssignedTasks      int               `json:"maxAssignedTasks"`      // Maximum number of tasks of this project a user can be assigned to at the same time. 0 means no limit.
+}
+
+// EditorLinks contains ready-to-use URLs to edit a task. The JOSM URLs are remote-control calls, which have to be
+// requested in the given order: Task polygon, data and then the imagery layers.
+type EditorLinks struct {
+	TaskId      string   `json:"taskId"`      // The ID of the task.
+	JosmTask    string   `json:"josmTask"`    // Loads the polygon of the task into a new layer that can't be uploaded.
+	JosmData    string   `json:"josmData"`    // Loads the data of the task area from the data source of the project into a new layer.
+	JosmImagery []string `json:"josmImagery"` // Loads the imagery layers of the project. Will not be NULL but might be empty.
+	Id          string   `json:"id"`          // Opens the iD editor at the task area with the changeset comment and hashtags of the project.
+}
+
+// PublicProject is the sanitised read-only view of a public project. User-IDs and comments are only set when the owner
+// allows it, otherwise these fields are NULL.
+type PublicProject struct {
+	Id                 string            `json:"id"`                 // The ID of the project.
+	Name               string            `json:"name"`               // The name of the project. Will not be NULL or empty.
+	Description        string            `json:"description"`        // Some description in Markdown, can be empty. Will not be NULL but might be empty.
+	DescriptionHtml    string            `json:"descriptionHtml"`    // The sanitised HTML of the description, see util.RenderMarkdown. Will not be NULL but might be empty.
+	CreationDate       *time.Time        `json:"creationDate"`       // UTC Date in RFC 3339 format, can be NIL because of old data in the database.
+	Tasks              []*PublicTask     `json:"tasks"`              // List of tasks of the project. Will not be NULL or empty.
 	TotalProcessPoints int               `json:"totalProcessPoints"` // Sum of all maximum process points of all tasks.
-	DoneProcessPoints  int               `json:"doneProcessPoints"`  // Sum of all process points that have been set. It applies "0 <= doneProcessPoints <= totalProcessPoints".
-	CreationDate       *time.Time        `json:"creationDate"`       // UTC Date in RFC 3339 format, can be NIL because of old data in the database. Example: "2006-01-02 15:04:05.999999999 -0700 MST"
-	Comments           []comment.Comment `json:"comments"`           // The comment on the project.
-	JosmDataSource     JosmDataSource    `json:"josmDataSource"`     // The source JOSM should load the data from when opening a task in JOSM.
+	DoneProcessPoints  int               `json:"doneProcessPoints"`  // Sum of all process points that have been set.
+	TaskCount          int               `json:"taskCount"`          // Number of tasks in this project.
+	DoneTaskCount      int               `json:"doneTaskCount"`      // Number of tasks where the maximum process points have been reached.
+	UserCount          int               `json:"userCount"`          // Number of members of this project.
+	Owner              string            `json:"owner,omitempty"`    // User-ID of the owner. Only set when the owner made the users visible.
+	Users              []string          `json:"users,omitempty"`    // Array of user-IDs. Only set when the owner made the users visible.
+	Comments           []comment.Comment `json:"comments,omitempty"` // The comments on the project. Only set when the owner made the comments visible.
+}
+
+// PublicTask is the sanitised read-only view of a task within a public project.
+type PublicTask struct {
+	Id               string            `json:"id"`                     // The ID of the task.
+	Name             string            `json:"name"`                   // The name of the task. Might be empty.
+	ProcessPoints    int               `json:"processPoints"`          // The amount of process points that have been set by the user.
+	MaxProcessPoints int               `json:"maxProcessPoints"`       // The maximum amount of process points of this task.
+	Geometry         string            `json:"geometry"`               // A GeoJson feature of the task wit a polygon or multipolygon geometry.
+	AssignedUser     string            `json:"assignedUser,omitempty"` // The user-ID of the assigned user. Only set when the owner made the users visible.
+	Comments         []comment.Comment `json:"comments,omitempty"`     // The comments on the task. Only set when the owner made the comments visible.
+}
+
+// Summary is a lightweight representation of a project without tasks and comments. All numbers are aggregated over the
+// tasks of the project.
+type Summary struct {
+	Id                 string         `json:"id"`                 // The ID of the project.
+	Name               string         `json:"name"`               // The name of the project. Will not be NULL or empty.
+	Description        string         `json:"description"`        // Some description, can be empty. Will not be NULL but might be empty.
+	Owner              string         `json:"owner"`              // User-ID of the owner/creator of this project. Will not be NULL or empty.
+	CreationDate       *time.Time     `json:"creationDate"`       // UTC Date in RFC 3339 format, can be NIL because of old data in the database.
+	JosmDataSource     JosmDataSource `json:"josmDataSource"`     // The source JOSM should load the data from when opening a task in JOSM.
+	UserCount          int            `json:"userCount"`          // Number of members of this project.
+	TaskCount          int            `json:"taskCount"`          // Number of tasks in this project.
+	DoneTaskCount      int            `json:"doneTaskCount"`      // Number of tasks where the maximum process points have been reached.
+	TotalProcessPoints int            `json:"totalProcessPoints"` // Sum of all maximum process points of all tasks.
+	DoneProcessPoints  int            `json:"doneProcessPoints"`  // Sum of all process points that have been set.
+}
+
+// SummaryPage is one page of project summaries.
+type SummaryPage struct {
+	Summaries  []*Summary `json:"summaries"`  // The summaries on this page. Will not be NULL but might be empty.
+	Page       int        `json:"page"`       // The number of this page, starting at 1.
+	PageSize   int        `json:"pageSize"`   // The maximum number of summaries per page.
+	TotalCount int        `json:"totalCount"` // The number of all projects matching the filter (across all pages).
+}
+
+// Statistics contains aggregated numbers about the progress of a project and the contributions of its members.
+type Statistics struct {
+	ProjectId           string              `json:"projectId"`           // The ID of the project.
+	TaskCount           int                 `json:"taskCount"`           // Number of tasks in this project.
+	FinishedTaskCount   int                 `json:"finishedTaskCount"`   // Number of tasks where the maximum process points have been reached.
+	AssignedTaskCount   int                 `json:"assignedTaskCount"`   // Number of unfinished tasks with an assigned user.
+	UnassignedTaskCount int                 `json:"unassignedTaskCount"` // Number of unfinished tasks without an assigned user.
+	TotalProcessPoints  int                 `json:"totalProcessPoints"`  // Sum of all maximum process points of all tasks.
+	DoneProcessPoints   int                 `json:"doneProcessPoints"`   // Sum of all process points that have been set.
+	MappedArea          float64             `json:"mappedArea"`          // Area of all finished tasks in square kilometers.
+	FirstActivity       *time.Time          `json:"firstActivity"`       // UTC date of the first change of process points or comment. NULL when nothing happened yet.
+	LastActivity        *time.Time          `json:"lastActivity"`        // UTC date of the latest change of process points or comment. NULL when nothing happened yet.
+	Members             []*MemberStatistics `json:"members"`             // Contributions per user. Contains all members and also former members who contributed something.
+}
+
+// MemberStatistics contains the contributions of one user to a project.
+type MemberStatistics struct {
+	UserId            string `json:"userId"`            // The ID of the user.
+	ProcessPointsSet  int    `json:"processPointsSet"`  // Sum of all process points added by this user. Reductions (e.g. resetting a task) are ignored.
+	FinishedTaskCount int    `json:"finishedTaskCount"` // Number of distinct tasks for which this user set the maximum process points.
+	CommentCount      int    `json:"commentCount"`      // Number of comments on the project and its tasks written by this user.
+}
+
+// Progress is a time series of the process points of a project plus an estimation when the project will be finished.
+type Progress struct {
+	ProjectId               string           `json:"projectId"`               // The ID of the project.
+	Interval                ProgressInterval `json:"interval"`                // The time between two points of the time series.
+	Points                  []*ProgressPoint `json:"points"`                  // The time series in chronological order. Will not be NULL or empty.
+	Velocity                float64          `json:"velocity"`                // Process points per day, based on the changes within the last seven days.
+	EstimatedCompletionDate *time.Time       `json:"estimatedCompletionDate"` // UTC date when the project will be finished at the current velocity. NULL when there's no progress or when the project is already finished.
+}
+
+// ProgressPoint is the state of a project at a certain point in time.
+type ProgressPoint struct {
+	Date               time.Time `json:"date"`               // UTC date of this point.
+	DoneProcessPoints  int       `json:"doneProcessPoints"`  // Sum of all process points that have been set at this date.
+	TotalProcessPoints int       `json:"totalProcessPoints"` // Sum of all maximum process points of all tasks.
 }
@@ -3,7 +3,11 @@ package project
 import (
 	"database/sql"
 	"fmt"
+	geojson "github.com/paulmach/go.geojson"
 	"github.com/pkg/errors"
+	"math"
+	"net/url"
+	"sort"
 	"stm/comment"
 	"stm/config"
 	"stm/permission"
@@ -11,6 +15,15 @@ import (
 	"stm/util"
 	"strings"
 	"time"
+	"unicode/utf8"
+)
+
+var (
+	progressIntervalDurations = map[ProgressInterval]time.Duration{
+		IntervalHour: time.Hour,
+		IntervalDay:  24 * time.Hour,
+		IntervalWeek: 7 * 24 * time.Hour,
+	}
 )
 
 type Service struct {
@@ -49,6 +62,30 @@ func (s *Service) GetProjects(userId string) ([]*Project, error) {
 	return projects, nil
 }
 
+// GetProjectSummaries returns one page of lightweight summaries of all projects the user is a member of. In contrast to
+// GetProjects, this doesn't load any tasks or comments.
+func (s *Service) GetProjectSummaries(userId string, filter *SummaryFilterDto) (*SummaryPage, error) {
+	if filter.Page < 1 {
+		return nil, errors.New(fmt.Sprintf("Page must be at least 1 (%d)", filter.Page))
+	}
+	if filter.PageSize < 1 || filter.PageSize > MaxSummaryPageSize {
+		return nil, errors.New(fmt.Sprintf("Page size must be between 1 and %d (%d)", MaxSummaryPageSize, filter.PageSize))
+	}
+
+	summaries, totalCount, err := s.store.getProjectSummariesOfUser(userId, filter)
+	if err != nil {
+		s.Err("Error getting project summaries for user %s", userId)
+		return nil, err
+	}
+
+	return &SummaryPage{
+		Summaries:  summaries,
+		Page:       filter.Page,
+		PageSize:   filter.PageSize,
+		TotalCount: totalCount,
+	}, nil
+}
+
 func (s *Service) GetProjectByTask(taskId string) (*Project, error) {
 	project, err := s.store.getProjectOfTask(taskId)
 	if err != nil {
@@ -116,8 +153,13 @@ func (s *Service) AddProject(projectDraft *DraftDto) (*Project, error) {
 		return nil, errors.New("Project must have a title")
 	}
 
-	if len(projectDraft.Description) > config.Conf.MaxDescriptionLength {
-		return nil, errors.New(fmt.Sprintf("Description too long. Allowed are %d characters but found %d.", config.Conf.MaxDescriptionLength, len(projectDraft.Description)))
+	if utf8.RuneCountInString(projectDraft.Description) > config.Conf.MaxDescriptionLength {
+		return nil, errors.New(fmt.Sprintf("Description too long. Allowed are %d characters but found %d.", config.Conf.MaxDescriptionLength, utf8.RuneCountInString(projectDraft.Description)))
+	}
+
+	err := validateChangesetSettings(&projectDraft.ChangesetDto)
+	if err != nil {
+		return nil, err
 	}
 
 	// Actually add project
@@ -131,12 +173,18 @@ func (s *Service) AddProject(projectDraft *DraftDto) (*Project, error) {
 }
 
 func (s *Service) GetProject(projectId string, potentialMemberId string) (*Project, error) {
+	return s.GetProjectWithLatestComments(projectId, potentialMemberId, -1)
+}
+
+// GetProjectWithLatestComments is like GetProject but only loads the latest "maxComments" comment threads of the project
+// and of each task. A negative number loads all comments. The number of open issues still covers all comments.
+func (s *Service) GetProjectWithLatestComments(projectId string, potentialMemberId string, maxComments int) (*Project, error) {
 	err := s.permissionStore.VerifyMembershipProject(projectId, potentialMemberId)
 	if err != nil {
 		return nil, err
 	}
 
-	project, err := s.store.getProject(projectId)
+	project, err := s.store.getProjectWithLatestComments(projectId, maxComments)
 	if err != nil {
 		return nil, err
 	}
@@ -150,6 +198,178 @@ func (s *Service) GetProject(projectId string, potentialMemberId string) (*Proje
 	return project, nil
 }
 
+// GetStatistics returns the progress of the project and the contributions of all users to it. The requesting user must
+// be a member of the project.
+func (s *Service) GetStatistics(projectId string, potentialMemberId string) (*Statistics, error) {
+	project, err := s.GetProject(projectId, potentialMemberId)
+	if err != nil {
+		return nil, err
+	}
+
+	memberStatistics, firstActivity, lastActivity, err := s.store.getMemberStatistics(projectId)
+	if err != nil {
+		return nil, err
+	}
+
+	statistics := &Statistics{
+		ProjectId:          project.Id,
+		TaskCount:          len(project.Tasks),
+		TotalProcessPoints: project.TotalProcessPoints,
+		DoneProcessPoints:  project.DoneProcessPoints,
+		FirstActivity:      firstActivity,
+		LastActivity:       lastActivity,
+		Members:            make([]*MemberStatistics, 0),
+	}
+
+	for _, t := range project.Tasks {
+		if t.ProcessPoints == t.MaxProcessPoints {
+			statistics.FinishedTaskCount++
+
+			feature, err := geojson.UnmarshalFeature([]byte(t.Geometry))
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not read geometry of task %s", t.Id)
+			}
+			statistics.MappedArea += util.GetArea(feature.Geometry) / 1000000
+		} else if t.AssignedUser != "" {
+			statistics.AssignedTaskCount++
+		} else {
+			statistics.UnassignedTaskCount++
+		}
+	}
+
+	// First all members (also the ones without contributions), then former members who contributed something
+	for _, u := range project.Users {
+		memberStatistic, ok := memberStatistics[u]
+		if !ok {
+			memberStatistic = &MemberStatistics{UserId: u}
+		}
+		statistics.Members = append(statistics.Members, memberStatistic)
+		delete(memberStatistics, u)
+	}
+	formerMembers := make([]*MemberStatistics, 0)
+	for _, memberStatistic := range memberStatistics {
+		formerMembers = append(formerMembers, memberStatistic)
+	}
+	sort.Slice(formerMembers, func(i, j int) bool {
+		return formerMembers[i].UserId < formerMembers[j].UserId
+	})
+	statistics.Members = append(statistics.Members, formerMembers...)
+
+	return statistics, nil
+}
+
+// GetProgress returns the process points of the project over time and an estimation when the project will be finished.
+// The time series is derived from the recorded process point changes, so changes made before these were recorded
+// appear to have happened before the first recorded change. The requesting user must be a member of the project.
+func (s *Service) GetProgress(projectId string, filter *ProgressFilterDto, potentialMemberId string) (*Progress, error) {
+	intervalDuration, ok := progressIntervalDurations[filter.Interval]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("unknown interval '%s'", filter.Interval))
+	}
+
+	project, err := s.GetProject(projectId, potentialMemberId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	to := now
+	if filter.To != nil {
+		to = filter.To.UTC()
+	}
+
+	// Old projects might not have a creation date, so just show the latest points
+	from := to.Add(-30 * intervalDuration)
+	if filter.From != nil {
+		from = filter.From.UTC()
+	} else if project.CreationDate != nil {
+		from = *project.CreationDate
+
+		// Long-running projects would exceed the maximum number of points, so the default only covers the latest ones
+		if countProgressPoints(from, to, intervalDuration) > MaxProgressPoints {
+			from = to.Add(-(MaxProgressPoints - 1) * intervalDuration)
+		}
+	}
+
+	if !from.Before(to) {
+		return nil, errors.New(fmt.Sprintf("start date %s must be before end date %s", from, to))
+	}
+	if countProgressPoints(from, to, intervalDuration) > MaxProgressPoints {
+		return nil, errors.New(fmt.Sprintf("time series would contain more than %d points, use a larger interval", MaxProgressPoints))
+	}
+
+	velocityStart := now.Add(-VelocityWindow)
+	changesStart := from
+	if velocityStart.Before(changesStart) {
+		changesStart = velocityStart
+	}
+
+	changes, err := s.store.getProcessPointChanges(projectId, changesStart)
+	if err != nil {
+		return nil, err
+	}
+
+	// The process points at a certain date are the current ones minus all changes made after that date
+	changedPoints := 0
+	velocityPoints := 0
+	for _, c := range changes {
+		changedPoints += c.delta
+		if c.date.After(velocityStart) {
+			velocityPoints += c.delta
+		}
+	}
+
+	progress := &Progress{
+		ProjectId: project.Id,
+		Interval:  filter.Interval,
+		Points:    make([]*ProgressPoint, 0),
+		Velocity:  float64(velocityPoints) / VelocityWindow.Hours() * 24,
+	}
+
+	changeIndex := 0
+	pointsUntilDate := 0
+	for date := from; ; date = date.Add(intervalDuration) {
+		if date.After(to) {
+			date = to
+		}
+
+		for changeIndex < len(changes) && !changes[changeIndex].date.After(date) {
+			pointsUntilDate += changes[changeIndex].delta
+			changeIndex++
+		}
+
+		progress.Points = append(progress.Points, &ProgressPoint{
+			Date:               date,
+			DoneProcessPoints:  project.DoneProcessPoints - (changedPoints - pointsUntilDate),
+			TotalProcessPoints: project.TotalProcessPoints,
+		})
+
+		if !date.Before(to) {
+			break
+		}
+	}
+
+	remainingPoints := project.TotalProcessPoints - project.DoneProcessPoints
+	if remainingPoints > 0 && progress.Velocity > 0 {
+		remainingDuration := time.Duration(float64(remainingPoints) / progress.Velocity * float64(24*time.Hour))
+		estimatedCompletionDate := now.Add(remainingDuration)
+		progress.EstimatedCompletionDate = &estimatedCompletionDate
+	}
+
+	return progress, nil
+}
+
+// countProgressPoints returns the number of points of a time series from the start until the end date. The last point is
+// always the end date, even when the last interval is shorter.
+func countProgressPoints(from time.Time, to time.Time, intervalDuration time.Duration) int64 {
+	duration := to.Sub(from)
+	intervals := int64(duration / intervalDuration)
+	if duration%intervalDuration != 0 {
+		intervals++
+	}
+	return intervals + 1
+}
+
 // addTasksAndMetadata adds additional metadata for convenience. This includes information about process points as well as permissions.
 func (s *Service) addTasksAndMetadata(project *Project) error {
 	// Collect the overall finish-state of the project
@@ -158,24 +378,62 @@ func (s *Service) addTasksAndMetadata(project *Project) error {
 		project.TotalProcessPoints += t.MaxProcessPoints
 	}
 
-	needsAssignment, err := s.permissionStore.AssignmentInProjectNeeded(project.Id)
-	if err != nil {
-		s.Err("unable to get assignment requirement for project %s", project.Id)
-		return err
-	}
-	project.NeedsAssignment = needsAssignment
+	project.NeedsAssignment = needsAssignment(project)
+
+	addScheduleFlags(project, time.Now().UTC())
 
 	s.Log("Added task metadata to project %s", project.Id)
 
 	return nil
 }
 
+// needsAssignment applies the assignment policy of the project. This is the same rule as in
+// permission.Store.AssignmentInProjectNeeded but without an additional query per project.
+func needsAssignment(project *Project) bool {
+	return permission.AssignmentNeeded(project.AssignmentPolicy, project.AssignmentThreshold, len(project.Users))
+}
+
+// addScheduleFlags determines whether an unfinished project with due date is overdue or at risk. A project is at risk
+// when the share of the passed time between start (or creation) and due date is larger than the share of done process
+// points.
+func addScheduleFlags(project *Project, now time.Time) {
+	project.IsOverdue = false
+	project.IsAtRisk = false
+
+	if project.DueDate == nil || project.TotalProcessPoints == 0 || project.DoneProcessPoints >= project.TotalProcessPoints {
+		return
+	}
+
+	if now.After(*project.DueDate) {
+		project.IsOverdue = true
+		return
+	}
+
+	startDate := project.StartDate
+	if startDate == nil {
+		startDate = project.CreationDate
+	}
+	if startDate == nil || !startDate.Before(*project.DueDate) || now.Before(*startDate) {
+		return
+	}
+
+	passedTimeShare := float64(now.Sub(*startDate)) / float64(project.DueDate.Sub(*startDate))
+	doneShare := float64(project.DoneProcessPoints) / float64(project.TotalProcessPoints)
+	project.IsAtRisk = passedTimeShare > doneShare
+}
+
 func (s *Service) AddUser(projectId, userId, potentialOwnerId string) (*Project, error) {
 	err := s.permissionStore.VerifyOwnership(projectId, potentialOwnerId)
 	if err != nil {
 		return nil, err
 	}
 
+	return s.JoinProject(projectId, userId)
+}
+
+// JoinProject adds the given user to the project without checking any permissions. The caller has to make sure that
+// the user is allowed to join (e.g. because he/she redeemed a valid invitation).
+func (s *Service) JoinProject(projectId, userId string) (*Project, error) {
 	p, err := s.store.getProject(projectId)
 	if err != nil {
 		return nil, err
@@ -230,33 +488,25 @@ func (s *Service) RemoveUser(projectId, requestingUserId, userIdToRemove string)
 		return nil, errors.New(fmt.Sprintf("non-owner user '%s' is not allowed to remove another user", requestingUserId))
 	}
 
+	// A member of a team of the project would still be a member after being removed as direct member
+	isTeamMember, err := s.store.isTeamMember(projectId, userIdToRemove)
+	if err != nil {
+		return nil, err
+	}
+	if isTeamMember {
+		return nil, errors.New(fmt.Sprintf("user '%s' is a member of a team of project %s and must be removed from the team instead", userIdToRemove, projectId))
+	}
+
 	project, err := s.store.removeUser(projectId, userIdToRemove)
 	if err != nil {
 		return nil, err
 	}
 	s.Log("User removed from project %s", project.Id)
 
-	// Unassign removed user from all tasks
-	newTasks := make([]*task.Task, len(project.Tasks))
-	for i, t := range project.Tasks {
-		err := s.permissionStore.VerifyCanUnassign(t.Id, userIdToRemove)
-
-		// err != nil means: The user is assigned to the task 't'
-		if err == nil {
-			updatedTask, err := s.taskService.UnassignUser(t.Id, userIdToRemove)
-			if err != nil {
-				s.Err("Unable to unassign user '%s' from task '%s'", userIdToRemove, t.Id)
-				return nil, err
-			}
-			s.Log("Unassigned user %s from task %s", userIdToRemove, t.Id)
-
-			newTasks[i] = updatedTask
-		} else {
-			newTasks[i] = t
-		}
+	err = s.unassignFormerMembers(project)
+	if err != nil {
+		return nil, err
 	}
-	project.Tasks = newTasks
-	s.Log("Unassigned the removed user %s from all tasks of project %s", userIdToRemove, project.Id)
 
 	// It could happen that someone removes him-/herself, so that we just removed requestingUserId from the project.
 	// Therefore the owner is used here.
@@ -269,6 +519,111 @@ func (s *Service) RemoveUser(projectId, requestingUserId, userIdToRemove string)
 	return project, nil
 }
 
+// AddTeam adds all members of the team to the project. The requesting user must be the owner of the project and a
+// member of the team.
+func (s *Service) AddTeam(projectId, teamId, requestingUserId string) (*Project, error) {
+	err := s.permissionStore.VerifyOwnership(projectId, requestingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.permissionStore.VerifyMembershipTeam(teamId, requestingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := s.store.addTeam(projectId, teamId)
+	if err != nil {
+		return nil, err
+	}
+	s.Log("Added team %s to project %s", teamId, project.Id)
+
+	err = s.addTasksAndMetadata(project)
+	if err != nil {
+		s.Err("Unable to add process point data to project %s", project.Id)
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// RemoveTeam removes the team from the project. Team members who aren't members of the project anymore get unassigned
+// from their tasks. Only the owner is allowed to do this.
+func (s *Service) RemoveTeam(projectId, teamId, requestingUserId string) (*Project, error) {
+	err := s.permissionStore.VerifyOwnership(projectId, requestingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := s.store.removeTeam(projectId, teamId)
+	if err != nil {
+		return nil, err
+	}
+	s.Log("Removed team %s from project %s", teamId, project.Id)
+
+	err = s.unassignFormerMembers(project)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.addTasksAndMetadata(project)
+	if err != nil {
+		s.Err("Unable to add process point data to project %s", project.Id)
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// UnassignFormerMembers unassigns all users from the tasks of the project who aren't members of the project anymore.
+// This is needed after the membership changed outside of this service, e.g. when a user has been removed from a team.
+func (s *Service) UnassignFormerMembers(projectId string) (*Project, error) {
+	project, err := s.store.getProject(projectId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.unassignFormerMembers(project)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.addTasksAndMetadata(project)
+	if err != nil {
+		s.Err("Unable to add process point data to project %s", project.Id)
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// unassignFormerMembers unassigns all users that aren't members of the project anymore from the tasks of the given
+// project and updates the tasks of the project object.
+func (s *Service) unassignFormerMembers(project *Project) error {
+	for i, t := range project.Tasks {
+		if t.AssignedUser == "" {
+			continue
+		}
+
+		err := s.permissionStore.VerifyMembershipProject(project.Id, t.AssignedUser)
+
+		// err == nil means: The assigned user is still a member of the project
+		if err != nil {
+			updatedTask, err := s.taskService.UnassignUser(t.Id, t.AssignedUser)
+			if err != nil {
+				s.Err("Unable to unassign user '%s' from task '%s'", t.AssignedUser, t.Id)
+				return err
+			}
+			s.Log("Unassigned former member %s from task %s", t.AssignedUser, t.Id)
+
+			project.Tasks[i] = updatedTask
+		}
+	}
+	s.Log("Unassigned all former members from the tasks of project %s", project.Id)
+
+	return nil
+}
+
 func (s *Service) DeleteProject(projectId, potentialOwnerId string) error {
 	err := s.permissionStore.VerifyOwnership(projectId, potentialOwnerId)
 	if err != nil {
@@ -285,7 +640,10 @@ func (s *Service) DeleteProject(projectId, potentialOwnerId string) error {
 	return nil
 }
 
-func (s *Service) Update(projectId string, newName string, newDescription string, newJosmDataSource JosmDataSource, requestingUserId string) (*Project, error) {
+// Update sets the name, description and JOSM data source of the project. The data source must fit the Overpass query
+// and download URL of the project. The changeset settings are only changed when they are not NULL. Only the owner is
+// allowed to do this.
+func (s *Service) Update(projectId string, newName string, newDescription string, newJosmDataSource JosmDataSource, changeset *ChangesetDto, requestingUserId string) (*Project, error) {
 	err := s.permissionStore.VerifyOwnership(projectId, requestingUserId)
 	if err != nil {
 		return nil, err
@@ -300,8 +658,29 @@ func (s *Service) Update(projectId string, newName string, newDescription string
 	}
 
 	// Check Description
-	if len(newDescription) > config.Conf.MaxDescriptionLength {
-		return nil, errors.New(fmt.Sprintf("Description too long. Allowed are %d characters but found %d.", config.Conf.MaxDescriptionLength, len(newDescription)))
+	if utf8.RuneCountInString(newDescription) > config.Conf.MaxDescriptionLength {
+		return nil, errors.New(fmt.Sprintf("Description too long. Allowed are %d characters but found %d.", config.Conf.MaxDescriptionLength, utf8.RuneCountInString(newDescription)))
+	}
+
+	if changeset != nil {
+		err = validateChangesetSettings(changeset)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The data source must fit the stored Overpass query and download URL, which are only changed via UpdateJosmConfig
+	currentProject, err := s.store.getProject(projectId)
+	if err != nil {
+		return nil, err
+	}
+	err = validateJosmConfig(&JosmConfigDto{
+		JosmDataSource:  newJosmDataSource,
+		OverpassQuery:   currentProject.OverpassQuery,
+		JosmDownloadUrl: currentProject.JosmDownloadUrl,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "JOSM data source doesn't fit the JOSM configuration of the project, change them together via the JOSM configuration")
 	}
 
 	project, err := s.store.update(projectId, newName, newDescription, newJosmDataSource)
@@ -310,6 +689,14 @@ func (s *Service) Update(projectId string, newName string, newDescription string
 	}
 	s.Log("Updated name of project %s to '%s'", project.Id, newName)
 
+	if changeset != nil {
+		project, err = s.store.updateChangesetSettings(projectId, changeset)
+		if err != nil {
+			return nil, err
+		}
+		s.Log("Updated changeset settings of project %s", project.Id)
+	}
+
 	err = s.addTasksAndMetadata(project)
 	if err != nil {
 		s.Err("Unable to add process point data to project %s", project.Id)
@@ -319,11 +706,849 @@ func (s *Service) Update(projectId string, newName string, newDescription string
 	return project, nil
 }
 
-func (s *Service) AddComment(projectId string, draftDto *comment.DraftDto, authorId string) error {
-	commentListId, err := s.store.getCommentListId(projectId)
+// validateChangesetSettings trims all values and returns an error when they can't be used as changeset tags.
+func validateChangesetSettings(changeset *ChangesetDto) error {
+	changeset.ChangesetComment = strings.TrimSpace(changeset.ChangesetComment)
+	changeset.ChangesetSource = strings.TrimSpace(changeset.ChangesetSource)
+
+	if utf8.RuneCountInString(changeset.ChangesetComment) > MaxChangesetTagLength {
+		return errors.New(fmt.Sprintf("Changeset comment too long. Allowed are %d characters but found %d.", MaxChangesetTagLength, utf8.RuneCountInString(changeset.ChangesetComment)))
+	}
+	if utf8.RuneCountInString(changeset.ChangesetSource) > MaxChangesetTagLength {
+		return errors.New(fmt.Sprintf("Changeset source too long. Allowed are %d characters but found %d.", MaxChangesetTagLength, utf8.RuneCountInString(changeset.ChangesetSource)))
+	}
+
+	hashtags := make([]string, 0)
+	for _, hashtag := range changeset.ChangesetHashtags {
+		hashtag = strings.TrimSpace(hashtag)
+		if len(hashtag) < 2 || !strings.HasPrefix(hashtag, "#") || strings.ContainsAny(hashtag[1:], " \t\n#,;") {
+			return errors.New(fmt.Sprintf("Invalid hashtag '%s'. Hashtags must start with '#' and must not contain whitespaces, commas and semicolons.", hashtag))
+		}
+		hashtags = append(hashtags, hashtag)
+	}
+	changeset.ChangesetHashtags = hashtags
+
+	// The hashtags are stored as one semicolon separated changeset tag
+	if utf8.RuneCountInString(strings.Join(hashtags, ";")) > MaxChangesetTagLength {
+		return errors.New(fmt.Sprintf("Hashtags too long. Allowed are %d characters in total.", MaxChangesetTagLength))
+	}
+
+	return nil
+}
+
+// UpdateSchedule sets the start and due date of the project, both are optional. The requesting user must be the owner
+// of the project.
+func (s *Service) UpdateSchedule(projectId string, startDate *time.Time, dueDate *time.Time, requestingUserId string) (*Project, error) {
+	err := s.permissionStore.VerifyOwnership(projectId, requestingUserId)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return s.commentService.AddComment(commentListId, draftDto, authorId)
+	if startDate != nil && dueDate != nil && !startDate.Before(*dueDate) {
+		return nil, errors.New(fmt.Sprintf("Start date %s must be before due date %s", startDate, dueDate))
+	}
+
+	project, err := s.store.updateSchedule(projectId, startDate, dueDate)
+	if err != nil {
+		return nil, err
+	}
+	s.Log("Updated start date of project %s to %s and due date to %s", project.Id, startDate, dueDate)
+
+	err = s.addTasksAndMetadata(project)
+	if err != nil {
+		s.Err("Unable to add process point data to project %s", project.Id)
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// MarkDueDatePassed returns all projects whose due date passed since the last call. This is not bound to any user and
+// therefore only meant to be used for notifications.
+func (s *Service) MarkDueDatePassed() ([]*Project, error) {
+	projects, err := s.store.markDueDatePassed(time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range projects {
+		err = s.addTasksAndMetadata(p)
+		if err != nil {
+			s.Err("Unable to add process point data to project %s", p.Id)
+			return nil, err
+		}
+	}
+
+	return projects, nil
+}
+
+// UpdateVisibility sets whether the project is publicly visible without login and which data the public view contains.
+// The requesting user must be the owner of the project.
+func (s *Service) UpdateVisibility(projectId string, visibility *VisibilityDto, requestingUserId string) (*Project, error) {
+	err := s.permissionStore.VerifyOwnership(projectId, requestingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := s.store.updateVisibility(projectId, visibility)
+	if err != nil {
+		return nil, err
+	}
+	s.Log("Updated visibility of project %s to public=%t", project.Id, project.IsPublic)
+
+	err = s.addTasksAndMetadata(project)
+	if err != nil {
+		s.Err("Unable to add process point data to project %s", project.Id)
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// UpdateJosmConfig sets where JOSM loads the data from and which imagery it loads. Only the owner is allowed to do this.
+func (s *Service) UpdateJosmConfig(projectId string, josmConfig *JosmConfigDto, requestingUserId string) (*Project, error) {
+	err := s.permissionStore.VerifyOwnership(projectId, requestingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if josmConfig.Imagery == nil {
+		josmConfig.Imagery = []ImageryLayer{}
+	}
+
+	err = validateJosmConfig(josmConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := s.store.updateJosmConfig(projectId, josmConfig)
+	if err != nil {
+		return nil, err
+	}
+	s.Log("Updated JOSM data source of project %s to %s with %d imagery layers", project.Id, project.JosmDataSource, len(project.Imagery))
+
+	err = s.addTasksAndMetadata(project)
+	if err != nil {
+		s.Err("Unable to add process point data to project %s", project.Id)
+		return nil, err
+	}
+
+	return project, nil
+}
+
+func validateJosmConfig(josmConfig *JosmConfigDto) error {
+	switch josmConfig.JosmDataSource {
+	case OSM, Overpass, Custom:
+	default:
+		return errors.New(fmt.Sprintf("unknown JOSM data source '%s'", josmConfig.JosmDataSource))
+	}
+
+	if josmConfig.OverpassQuery != "" {
+		if josmConfig.JosmDataSource != Overpass {
+			return errors.New(fmt.Sprintf("Overpass query can only be used with data source %s", Overpass))
+		}
+		if utf8.RuneCountInString(josmConfig.OverpassQuery) > MaxOverpassQueryLength {
+			return errors.New(fmt.Sprintf("Overpass query too long. Allowed are %d characters but found %d.", MaxOverpassQueryLength, utf8.RuneCountInString(josmConfig.OverpassQuery)))
+		}
+		if !strings.Contains(josmConfig.OverpassQuery, OverpassBboxPlaceholder) {
+			return errors.New(fmt.Sprintf("Overpass query must contain the placeholder %s", OverpassBboxPlaceholder))
+		}
+	}
+
+	if josmConfig.JosmDataSource == Custom {
+		err := validateHttpUrl(josmConfig.JosmDownloadUrl)
+		if err != nil {
+			return errors.Wrap(err, "invalid download URL")
+		}
+	} else if josmConfig.JosmDownloadUrl != "" {
+		return errors.New(fmt.Sprintf("download URL can only be used with data source %s", Custom))
+	}
+
+	if len(josmConfig.Imagery) > MaxImageryLayers {
+		return errors.New(fmt.Sprintf("Too many imagery layers. Allowed are %d but found %d.", MaxImageryLayers, len(josmConfig.Imagery)))
+	}
+	for i, layer := range josmConfig.Imagery {
+		if strings.TrimSpace(layer.Name) == "" {
+			return errors.New(fmt.Sprintf("imagery layer %d has no name", i))
+		}
+		if layer.Type != ImageryTms && layer.Type != ImageryWms {
+			return errors.New(fmt.Sprintf("imagery layer '%s' has unknown type '%s'", layer.Name, layer.Type))
+		}
+		err := validateHttpUrl(layer.Url)
+		if err != nil {
+			return errors.Wrapf(err, "invalid URL of imagery layer '%s'", layer.Name)
+		}
+	}
+
+	return nil
+}
+
+// validateHttpUrl returns an error when the given text is not an absolute HTTP or HTTPS URL.
+func validateHttpUrl(text string) error {
+	parsedUrl, err := url.Parse(text)
+	if err != nil {
+		return err
+	}
+
+	if (parsedUrl.Scheme != "http" && parsedUrl.Scheme != "https") || parsedUrl.Host == "" {
+		return errors.New(fmt.Sprintf("'%s' is not an absolute HTTP(S) URL", text))
+	}
+
+	return nil
+}
+
+// GetEditorLinks returns the JOSM remote-control and iD URLs to edit the given task. The requesting user must be a member
+// of the project of the task.
+func (s *Service) GetEditorLinks(taskId string, requestingUserId string) (*EditorLinks, error) {
+	err := s.permissionStore.VerifyMembershipTask(taskId, requestingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := s.store.getProjectSettingsOfTask(taskId)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := s.taskService.GetTask(taskId)
+	if err != nil {
+		return nil, err
+	}
+
+	return getEditorLinks(project, t)
+}
+
+// getEditorLinks builds all editor URLs for the given task from its geometry and the JOSM configuration of the project.
+func getEditorLinks(project *Project, t *task.Task) (*EditorLinks, error) {
+	feature, err := geojson.UnmarshalFeature([]byte(t.Geometry))
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read geometry of task %s", t.Id)
+	}
+
+	outerRing := util.GetOuterRing(feature.Geometry)
+	boundingBox := util.GetBoundingBox(feature.Geometry)
+	if outerRing == nil || boundingBox == nil {
+		return nil, errors.New(fmt.Sprintf("unsupported geometry of task %s, only polygons and multi-polygons are supported", t.Id))
+	}
+
+	// Defaults for projects without own changeset settings
+	changesetComment := fmt.Sprintf("#stm #stm-project-%s ", project.Id)
+	if project.ChangesetComment != "" {
+		changesetComment = project.ChangesetComment
+	}
+	hashtags := []string{"#stm", "#stm-project-" + project.Id}
+	if len(project.ChangesetHashtags) > 0 {
+		hashtags = project.ChangesetHashtags
+	}
+	changesetHashtags := strings.Join(hashtags, ";")
+
+	links := &EditorLinks{
+		TaskId:      t.Id,
+		JosmTask:    fmt.Sprintf("%s/load_data?new_layer=true&layer_name=%s&upload_policy=never&data=%s", JosmRemoteControlUrl, url.QueryEscape("task "+t.Name), url.QueryEscape(ringToOsm(outerRing))),
+		JosmImagery: make([]string, 0),
+	}
+
+	// The "url" parameter has to be the last one for the "import" and "imagery" commands of JOSM.
+	switch project.JosmDataSource {
+	case OSM:
+		links.JosmData = fmt.Sprintf("%s/load_and_zoom?new_layer=true&left=%f&right=%f&top=%f&bottom=%f&changeset_comment=%s&changeset_hashtags=%s",
+			JosmRemoteControlUrl, boundingBox.MinLon, boundingBox.MaxLon, boundingBox.MaxLat, boundingBox.MinLat, url.QueryEscape(changesetComment), url.QueryEscape(changesetHashtags))
+		if project.ChangesetSource != "" {
+			links.JosmData += "&changeset_source=" + url.QueryEscape(project.ChangesetSource)
+		}
+	case Overpass:
+		query := getOverpassQuery(project.OverpassQuery, outerRing, boundingBox)
+		overpassUrl := OverpassApiUrl + "?data=" + url.QueryEscape(query)
+		links.JosmData = fmt.Sprintf("%s/import?new_layer=true&url=%s", JosmRemoteControlUrl, url.QueryEscape(overpassUrl))
+	case Custom:
+		links.JosmData = fmt.Sprintf("%s/import?new_layer=true&url=%s", JosmRemoteControlUrl, url.QueryEscape(project.JosmDownloadUrl))
+	default:
+		return nil, errors.New(fmt.Sprintf("unknown JOSM data source '%s' of project %s", project.JosmDataSource, project.Id))
+	}
+
+	for _, layer := range project.Imagery {
+		links.JosmImagery = append(links.JosmImagery, fmt.Sprintf("%s/imagery?title=%s&type=%s&url=%s",
+			JosmRemoteControlUrl, url.QueryEscape(layer.Name), strings.ToLower(string(layer.Type)), url.QueryEscape(layer.Url)))
+	}
+
+	centerLat, centerLon, zoom := fitBoundingBox(boundingBox, IdViewportWidth, IdViewportHeight)
+	links.Id = fmt.Sprintf("%s#map=%.2f/%f/%f&comment=%s&hashtags=%s", IdEditorUrl, zoom, centerLat, centerLon,
+		encodeUriComponent(changesetComment), encodeUriComponent(strings.Join(hashtags, ",")))
+	if project.ChangesetSource != "" {
+		links.Id += "&source=" + encodeUriComponent(project.ChangesetSource)
+	}
+
+	return links, nil
+}
+
+// encodeUriComponent escapes the value like "encodeURIComponent" in JavaScript, which iD uses to decode the parameters
+// of its URL hash. In contrast to url.PathEscape, characters like "&" and "=" are escaped as well.
+func encodeUriComponent(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
+
+// getOverpassQuery returns the query of the project with the bounding box of the task (in the Overpass order "south,
+// west,north,east") or the default query loading everything within the task polygon.
+func getOverpassQuery(queryTemplate string, outerRing [][]float64, boundingBox *util.BoundingBox) string {
+	if queryTemplate != "" {
+		bbox := fmt.Sprintf("%f,%f,%f,%f", boundingBox.MinLat, boundingBox.MinLon, boundingBox.MaxLat, boundingBox.MaxLon)
+		return strings.ReplaceAll(queryTemplate, OverpassBboxPlaceholder, bbox)
+	}
+
+	coordinates := make([]string, len(outerRing))
+	for i, c := range outerRing {
+		coordinates[i] = fmt.Sprintf("%f %f", c[1], c[0])
+	}
+
+	return fmt.Sprintf(`[out:json];nwr(poly:"%s");out meta;(<; - rel._;);(._;>;); out meta;`, strings.Join(coordinates, " "))
+}
+
+// ringToOsm turns the given ring into an OSM-XML document with one closed way, which JOSM shows as task polygon.
+func ringToOsm(ring [][]float64) string {
+	osm := `<osm version="0.6" generator="simple-task-dashboard">`
+
+	for i, c := range ring {
+		osm += fmt.Sprintf("<node id='-%d' action='modify' visible='true' lat='%f' lon='%f' />", i+1, c[1], c[0])
+	}
+
+	osm += fmt.Sprintf("<way id='-%d' action='modify' visible='true'>", len(ring)+1)
+	for i := range ring {
+		osm += fmt.Sprintf("<nd ref='-%d' />", i+1)
+	}
+	// Close the ring by adding the first node again
+	osm += "<nd ref='-1' /></way></osm>"
+
+	return osm
+}
+
+// fitBoundingBox determines the center and the zoom level of a web-mercator map with the given size in pixels, so that
+// the bounding box fits into it.
+func fitBoundingBox(boundingBox *util.BoundingBox, mapWidth float64, mapHeight float64) (float64, float64, float64) {
+	const padding = 20.0
+	const tileSize = 256.0
+
+	mercatorY := func(lat float64) float64 {
+		return math.Log(math.Tan(math.Pi/4 + lat*math.Pi/360))
+	}
+
+	centerLon := (boundingBox.MinLon + boundingBox.MaxLon) / 2
+	centerY := (mercatorY(boundingBox.MinLat) + mercatorY(boundingBox.MaxLat)) / 2
+	centerLat := math.Atan(math.Sinh(centerY)) * 180 / math.Pi
+
+	// Zoom levels at which the width or height of the bounding box fills the map. Empty extents don't restrict the zoom.
+	zoom := 19.0
+	deltaLon := boundingBox.MaxLon - boundingBox.MinLon
+	if deltaLon > 0 {
+		zoom = math.Min(zoom, math.Log2(360*(mapWidth-2*padding)/(tileSize*deltaLon)))
+	}
+	deltaY := mercatorY(boundingBox.MaxLat) - mercatorY(boundingBox.MinLat)
+	if deltaY > 0 {
+		zoom = math.Min(zoom, math.Log2(2*math.Pi*(mapHeight-2*padding)/(tileSize*deltaY)))
+	}
+
+	return centerLat, centerLon, math.Max(zoom, 1)
+}
+
+// UpdateAssignmentPolicy sets whether users have to be assigned to tasks and how many tasks a user can be assigned to
+// at the same time. Existing assignments above a new limit are kept. Only the owner is allowed to do this.
+func (s *Service) UpdateAssignmentPolicy(projectId string, assignment *AssignmentDto, requestingUserId string) (*Project, error) {
+	err := s.permissionStore.VerifyOwnership(projectId, requestingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if assignment.Policy != AssignmentAlways && assignment.Policy != AssignmentNever && assignment.Policy != AssignmentAboveMemberCount {
+		return nil, errors.New(fmt.Sprintf("unknown assignment policy '%s'", assignment.Policy))
+	}
+	if assignment.Threshold < 0 {
+		return nil, errors.New(fmt.Sprintf("member threshold must not be negative but was %d", assignment.Threshold))
+	}
+	if assignment.MaxAssignedTasks < 0 {
+		return nil, errors.New(fmt.Sprintf("maximum number of assigned tasks must not be negative but was %d", assignment.MaxAssignedTasks))
+	}
+
+	project, err := s.store.updateAssignmentPolicy(projectId, assignment)
+	if err != nil {
+		return nil, err
+	}
+	s.Log("Updated assignment policy of project %s to %s", project.Id, project.AssignmentPolicy)
+
+	err = s.addTasksAndMetadata(project)
+	if err != nil {
+		s.Err("Unable to add process point data to project %s", project.Id)
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// GetPublicProject returns the sanitised read-only view of the given project. This does not need any membership but
+// only works for projects that have been made public by their owner.
+func (s *Service) GetPublicProject(projectId string) (*PublicProject, error) {
+	project, err := s.store.getProject(projectId)
+	if err != nil || !project.IsPublic {
+		// Same error in both cases to not reveal which private projects exist
+		return nil, errors.New(fmt.Sprintf("project %s does not exist or is not public", projectId))
+	}
+
+	err = s.addTasksAndMetadata(project)
+	if err != nil {
+		s.Err("Unable to add process point data to project %s", project.Id)
+		return nil, err
+	}
+
+	return toPublicProject(project), nil
+}
+
+func toPublicProject(project *Project) *PublicProject {
+	publicProject := &PublicProject{
+		Id:                 project.Id,
+		Name:               project.Name,
+		Description:        project.Description,
+		DescriptionHtml:    project.DescriptionHtml,
+		CreationDate:       project.CreationDate,
+		Tasks:              make([]*PublicTask, len(project.Tasks)),
+		TotalProcessPoints: project.TotalProcessPoints,
+		DoneProcessPoints:  project.DoneProcessPoints,
+		TaskCount:          len(project.Tasks),
+		UserCount:          len(project.Users),
+	}
+
+	if project.PublicUsersVisible {
+		publicProject.Owner = project.Owner
+		publicProject.Users = project.Users
+	}
+	if project.PublicCommentsVisible {
+		publicProject.Comments = project.Comments
+	}
+
+	for i, t := range project.Tasks {
+		publicTask := &PublicTask{
+			Id:               t.Id,
+			Name:             t.Name,
+			ProcessPoints:    t.ProcessPoints,
+			MaxProcessPoints: t.MaxProcessPoints,
+			Geometry:         t.Geometry,
+		}
+
+		if project.PublicUsersVisible {
+			publicTask.AssignedUser = t.AssignedUser
+		}
+		if project.PublicCommentsVisible {
+			publicTask.Comments = t.Comments
+		}
+
+		if t.ProcessPoints == t.MaxProcessPoints {
+			publicProject.DoneTaskCount++
+		}
+
+		publicProject.Tasks[i] = publicTask
+	}
+
+	return publicProject
+}
+
+// GetComments returns one page of the comment threads of the project. Only members of the project are allowed to see
+// the comments.
+func (s *Service) GetComments(projectId string, filter *comment.PageFilterDto, requestingUserId string) (*comment.Page, error) {
+	err := s.permissionStore.VerifyMembershipProject(projectId, requestingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	commentListId, err := s.store.getCommentListId(projectId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.commentService.GetCommentPage(commentListId, filter)
+}
+
+// AddComment adds a new comment to the project. The comment may mention members of the project, other mentions are
+// returned as warnings of the comment. Read-only users of the project are not allowed to write comments.
+func (s *Service) AddComment(projectId string, draftDto *comment.DraftDto, authorId string) (*comment.Comment, error) {
+	commentListId, err := s.store.getCommentListId(projectId)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.store.getMembers(projectId)
+	if err != nil {
+		return nil, err
+	}
+
+	readOnlyUsers, err := s.store.getReadOnlyUsers(projectId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.commentService.AddComment(commentListId, draftDto, authorId, members, readOnlyUsers)
+}
+
+// RevealHiddenComments adds the content of the hidden comments of the project and its tasks, which is needed for a
+// complete export. Only the owner is allowed to do this.
+func (s *Service) RevealHiddenComments(project *Project, requestingUserId string) error {
+	err := s.permissionStore.VerifyOwnership(project.Id, requestingUserId)
+	if err != nil {
+		return err
+	}
+
+	err = s.commentService.RevealHidden(project.Comments)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range project.Tasks {
+		err = s.commentService.RevealHidden(t.Comments)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ImportComments adds the given comment threads with their original authors and dates to the project. This is meant to
+// restore an exported project and doesn't check any permissions.
+func (s *Service) ImportComments(projectId string, threads []comment.Comment) error {
+	commentListId, err := s.store.getCommentListId(projectId)
+	if err != nil {
+		return err
+	}
+
+	return s.commentService.ImportThreads(commentListId, threads)
+}
+
+// UpdateComment replaces the text of the comment and returns the project the comment belongs to as well as the updated
+// comment. Only the author of the comment is allowed to do this and only when the author isn't in read-only mode.
+func (s *Service) UpdateComment(commentId string, draftDto *comment.DraftDto, requestingUserId string) (*Project, *comment.Comment, error) {
+	err := s.permissionStore.VerifyCommentAuthor(commentId, requestingUserId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	project, err := s.store.getProjectOfComment(commentId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = verifyNotReadOnly(project, requestingUserId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Otherwise the author could move the hidden content into the edit history
+	hidden, err := s.commentService.IsHidden(commentId)
+	if err != nil {
+		return nil, nil, err
+	}
+	if hidden {
+		return nil, nil, errors.New(fmt.Sprintf("Comment %s is hidden and can't be edited", commentId))
+	}
+
+	updatedComment, err := s.commentService.UpdateComment(commentId, draftDto, project.Users)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	project, err = s.getProjectOfComment(commentId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return project, updatedComment, nil
+}
+
+// DeleteComment removes the comment and its edit history and returns the project the comment belonged to. The author
+// of the comment and the owner of the project are allowed to do this.
+func (s *Service) DeleteComment(commentId string, requestingUserId string) (*Project, error) {
+	project, err := s.getProjectOfComment(commentId)
+	if err != nil {
+		return nil, err
+	}
+
+	if project.Owner != requestingUserId {
+		err = s.permissionStore.VerifyCommentAuthor(commentId, requestingUserId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.commentService.DeleteComment(commentId)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err = s.store.getProject(project.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.addTasksAndMetadata(project)
+	if err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// SetCommentIssueStatus marks the comment as open or resolved issue or as normal comment and returns the project the
+// comment belongs to. The author of the comment, the user assigned to the task of the comment and the owner of the
+// project are allowed to do this.
+func (s *Service) SetCommentIssueStatus(commentId string, status comment.IssueStatus, requestingUserId string) (*Project, error) {
+	err := s.permissionStore.VerifyCanChangeIssueStatus(commentId, requestingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.commentService.SetIssueStatus(commentId, status)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getProjectOfComment(commentId)
+}
+
+// GetCommentHistory returns the previous versions of the comment, the oldest first. Only members of the project the
+// comment belongs to are allowed to see them. The history of hidden comments is only available to the owner.
+func (s *Service) GetCommentHistory(commentId string, requestingUserId string) ([]comment.Edit, error) {
+	project, err := s.store.getProjectOfComment(commentId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.permissionStore.VerifyMembershipProject(project.Id, requestingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.verifyCanSeeContent(project, commentId, requestingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.commentService.GetEdits(commentId)
+}
+
+func (s *Service) getProjectOfComment(commentId string) (*Project, error) {
+	project, err := s.store.getProjectOfComment(commentId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.addTasksAndMetadata(project)
+	if err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// AddCommentAttachment stores the file as attachment of the comment and returns the project the comment belongs to.
+// Only the author of the comment is allowed to do this and only when the author isn't in read-only mode.
+func (s *Service) AddCommentAttachment(commentId string, fileName string, content []byte, requestingUserId string) (*Project, error) {
+	err := s.permissionStore.VerifyCommentAuthor(commentId, requestingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := s.store.getProjectOfComment(commentId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = verifyNotReadOnly(project, requestingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.commentService.AddAttachment(commentId, fileName, content)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getProjectOfComment(commentId)
+}
+
+// GetCommentAttachment returns the attachment and its content. Only members of the project the comment belongs to are
+// allowed to see it. Attachments of hidden comments are only available to the owner.
+func (s *Service) GetCommentAttachment(attachmentId string, requestingUserId string) (*comment.Attachment, []byte, error) {
+	attachment, err := s.commentService.GetAttachmentMetadata(attachmentId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	project, err := s.store.getProjectOfComment(attachment.CommentId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = s.permissionStore.VerifyMembershipProject(project.Id, requestingUserId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = s.verifyCanSeeContent(project, attachment.CommentId, requestingUserId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return s.commentService.GetAttachment(attachmentId)
+}
+
+// ReportComment stores the report of the requesting user about an inappropriate comment. All members of the project
+// the comment belongs to are allowed to report comments.
+func (s *Service) ReportComment(commentId string, reportDto *comment.ReportDto, requestingUserId string) error {
+	project, err := s.store.getProjectOfComment(commentId)
+	if err != nil {
+		return err
+	}
+
+	err = s.permissionStore.VerifyMembershipProject(project.Id, requestingUserId)
+	if err != nil {
+		return err
+	}
+
+	return s.commentService.ReportComment(commentId, reportDto, requestingUserId)
+}
+
+// GetCommentReports returns the reports of all comments on the project and its tasks, the oldest first. Only the owner
+// of the project is allowed to see them.
+func (s *Service) GetCommentReports(projectId string, requestingUserId string) ([]comment.Report, error) {
+	err := s.permissionStore.VerifyOwnership(projectId, requestingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	commentListIds, err := s.store.getCommentListIds(projectId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.commentService.GetReports(commentListIds)
+}
+
+// DismissCommentReports removes all reports of the comment. Only the owner of the project the comment belongs to is
+// allowed to do this.
+func (s *Service) DismissCommentReports(commentId string, requestingUserId string) error {
+	project, err := s.store.getProjectOfComment(commentId)
+	if err != nil {
+		return err
+	}
+
+	err = s.permissionStore.VerifyOwnership(project.Id, requestingUserId)
+	if err != nil {
+		return err
+	}
+
+	return s.commentService.DismissReports(commentId)
+}
+
+// SetCommentHidden hides the comment or shows it again and returns the project the comment belongs to. Only the owner
+// of the project is allowed to do this.
+func (s *Service) SetCommentHidden(commentId string, hidden bool, requestingUserId string) (*Project, error) {
+	project, err := s.store.getProjectOfComment(commentId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.permissionStore.VerifyOwnership(project.Id, requestingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.commentService.SetHidden(commentId, hidden)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getProjectOfComment(commentId)
+}
+
+// AddReadOnlyUser puts the member into read-only mode, which means the user can't write comments in this project
+// anymore. Only the owner is allowed to do this and the owner can't be put into read-only mode.
+func (s *Service) AddReadOnlyUser(projectId string, userId string, requestingUserId string) (*Project, error) {
+	err := s.permissionStore.VerifyOwnership(projectId, requestingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if userId == requestingUserId {
+		return nil, errors.New("the owner can not be put into read-only mode")
+	}
+
+	err = s.permissionStore.VerifyMembershipProject(projectId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	readOnlyUsers, err := s.store.getReadOnlyUsers(projectId)
+	if err != nil {
+		return nil, err
+	}
+	if containsUser(readOnlyUsers, userId) {
+		return nil, errors.New(fmt.Sprintf("user %s is already in read-only mode in project %s", userId, projectId))
+	}
+
+	project, err := s.store.addReadOnlyUser(projectId, userId)
+	if err != nil {
+		return nil, err
+	}
+	s.Log("Put user %s into read-only mode in project %s", userId, projectId)
+
+	err = s.addTasksAndMetadata(project)
+	if err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// RemoveReadOnlyUser allows the user to write comments in this project again. Only the owner is allowed to do this.
+func (s *Service) RemoveReadOnlyUser(projectId string, userId string, requestingUserId string) (*Project, error) {
+	err := s.permissionStore.VerifyOwnership(projectId, requestingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := s.store.removeReadOnlyUser(projectId, userId)
+	if err != nil {
+		return nil, err
+	}
+	s.Log("Removed user %s from read-only mode in project %s", userId, projectId)
+
+	err = s.addTasksAndMetadata(project)
+	if err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// verifyNotReadOnly returns an error when the user is in read-only mode in the project and therefore must not write or
+// change comments.
+func verifyNotReadOnly(project *Project, userId string) error {
+	if containsUser(project.ReadOnlyUsers, userId) {
+		return errors.New(fmt.Sprintf("User %s is in read-only mode in project %s", userId, project.Id))
+	}
+
+	return nil
+}
+
+// verifyCanSeeContent returns an error when the comment is hidden and the user isn't the owner of the project. The
+// content of hidden comments (incl. their history and attachments) is only available to the owner.
+func (s *Service) verifyCanSeeContent(project *Project, commentId string, userId string) error {
+	if project.Owner == userId {
+		return nil
+	}
+
+	hidden, err := s.commentService.IsHidden(commentId)
+	if err != nil {
+		return err
+	}
+	if hidden {
+		return errors.New(fmt.Sprintf("Comment %s is hidden", commentId))
+	}
+
+	return nil
 }
@@ -6,12 +6,15 @@ import (
 	"github.com/hauke96/sigolo"
 	_ "github.com/lib/pq" // Make driver "postgres" usable
 	"github.com/pkg/errors"
+	"net/url"
+	"os"
 	"stm/comment"
 	"stm/config"
 	"stm/permission"
 	"stm/task"
 	"stm/test"
 	"stm/util"
+	"strings"
 	"testing"
 	"time"
 )
@@ -511,6 +514,100 @@ func TestRemoveDifferentUserNotAllowed(t *testing.T) {
 	})
 }
 
+func TestAddAndRemoveTeam(t *testing.T) {
+	h.Run(t, func() error {
+		p, err := s.AddTeam("2", "1", "Maria")
+		if err != nil {
+			return err
+		}
+
+		if len(p.Teams) != 1 || p.Teams[0] != "1" {
+			return errors.New(fmt.Sprintf("Project should contain team 1: %v", p.Teams))
+		}
+		// Maria and Anna are direct members as well but should only appear once
+		if len(p.Users) != 7 || p.Users[6] != "Otto" {
+			return errors.New(fmt.Sprintf("Otto should be the only new member: %v", p.Users))
+		}
+
+		_, err = taskService.AssignUser("4", "Otto")
+		if err != nil {
+			return err
+		}
+
+		p, err = s.RemoveTeam("2", "1", "Maria")
+		if err != nil {
+			return err
+		}
+
+		if len(p.Teams) != 0 || len(p.Users) != 6 {
+			return errors.New(fmt.Sprintf("Team members should not be members anymore: %v", p.Users))
+		}
+		for _, task := range p.Tasks {
+			if task.AssignedUser == "Otto" {
+				return errors.New(fmt.Sprintf("Task '%s' still has former member Otto assigned", task.Id))
+			}
+		}
+
+		// Team has already been removed
+		_, err = s.RemoveTeam("2", "1", "Maria")
+		if err == nil {
+			return errors.New("Removing a team which isn't part of the project should not work")
+		}
+
+		return nil
+	})
+}
+
+func TestAddTeamNotAllowed(t *testing.T) {
+	h.Run(t, func() error {
+		// Anna is member of the team but not owner of the project
+		_, err := s.AddTeam("2", "1", "Anna")
+		if err == nil {
+			return errors.New("Non-owner should not be able to add a team")
+		}
+
+		// Peter is the owner of the project but not a member of the team
+		_, err = s.AddTeam("1", "1", "Peter")
+		if err == nil {
+			return errors.New("Owner should not be able to add a team he is not a member of")
+		}
+
+		return nil
+	})
+}
+
+func TestRemoveTeamMemberNotAllowed(t *testing.T) {
+	h.Run(t, func() error {
+		_, err := s.AddTeam("2", "1", "Maria")
+		if err != nil {
+			return err
+		}
+
+		// Otto is only a member through the team
+		_, err = s.RemoveUser("2", "Maria", "Otto")
+		if err == nil {
+			return errors.New("Removing a team member from the project should not work")
+		}
+
+		// Anna is a direct member and a team member
+		_, err = s.RemoveUser("2", "Maria", "Anna")
+		if err == nil {
+			return errors.New("Removing a direct member who is also a team member should not work")
+		}
+
+		// Nothing has been changed by the failed attempts
+		p, err := s.GetProject("2", "Maria")
+		if err != nil {
+			return err
+		}
+		if !containsUser(p.DirectUsers, "Anna") || containsUser(p.DirectUsers, "Otto") || !containsUser(p.Users, "Otto") {
+			return errors.New(fmt.Sprintf("Anna should still be a direct member and Otto a team member: %v, %v", p.DirectUsers, p.Users))
+		}
+
+		return nil
+	})
+}
+
 func TestDeleteProject(t *testing.T) {
 	h.Run(t, func() error {
 		id := "1" // owned by "Peter"
@@ -564,7 +661,7 @@ func TestUpdate(t *testing.T) {
 		newName := "flubby dubby"
 		newDescription := "flubby dubby\n foo bar"
 		newJosmDataSource := Overpass
-		project, err := s.Update("1", newName, newDescription, newJosmDataSource, "Peter")
+		project, err := s.Update("1", newName, newDescription, newJosmDataSource, nil, "Peter")
 		if err != nil {
 			return errors.New(fmt.Sprintf("Error updating project wasn't expected: %s", err))
 		}
@@ -586,7 +683,7 @@ func TestUpdate(t *testing.T) {
 
 		// With newline
 		newNewlineName := "foo\nbar\nwhatever"
-		project, err = s.Update("1", newNewlineName, newDescription, newJosmDataSource, "Peter")
+		project, err = s.Update("1", newNewlineName, newDescription, newJosmDataSource, nil, "Peter")
 		if err != nil {
 			return errors.New(fmt.Sprintf("Error updating name wasn't expected: %s", err))
 		}
@@ -603,14 +700,62 @@ func TestUpdate(t *testing.T) {
 			return errors.New(fmt.Sprintf("New JOSM data source doesn't match with expected one: %s != %s", oldProject.JosmDataSource, newJosmDataSource))
 		}
 
+		// With changeset settings
+		changeset := &ChangesetDto{ChangesetComment: " Mapping roads ", ChangesetSource: "survey", ChangesetHashtags: []string{"#roads", " #stm "}}
+		project, err = s.Update("1", newName, newDescription, OSM, changeset, "Peter")
+		if err != nil {
+			return errors.New(fmt.Sprintf("Error updating changeset settings wasn't expected: %s", err))
+		}
+		if project.ChangesetComment != "Mapping roads" || project.ChangesetSource != "survey" || len(project.ChangesetHashtags) != 2 || project.ChangesetHashtags[1] != "#stm" {
+			return errors.New(fmt.Sprintf("Changeset settings not matching: %#v", project))
+		}
+
+		// Changeset settings are kept when not given
+		project, err = s.Update("1", newName, newDescription, OSM, nil, "Peter")
+		if err != nil {
+			return errors.New(fmt.Sprintf("Error updating project wasn't expected: %s", err))
+		}
+		if project.ChangesetComment != "Mapping roads" || len(project.ChangesetHashtags) != 2 {
+			return errors.New(fmt.Sprintf("Changeset settings should be kept: %#v", project))
+		}
+
+		// Invalid hashtag
+		_, err = s.Update("1", newName, newDescription, OSM, &ChangesetDto{ChangesetHashtags: []string{"roads"}}, "Peter")
+		if err == nil {
+			return errors.New("Updating project with invalid hashtag should not be possible")
+		}
+
+		// Data source "CUSTOM" without download URL
+		_, err = s.Update("1", newName, newDescription, Custom, nil, "Peter")
+		if err == nil {
+			return errors.New("Data source CUSTOM without download URL should not be possible")
+		}
+
+		// The stored Overpass query doesn't fit to any other data source
+		_, err = s.UpdateJosmConfig("1", &JosmConfigDto{JosmDataSource: Overpass, OverpassQuery: "nwr[building]({{bbox}});out meta;"}, "Peter")
+		if err != nil {
+			return err
+		}
+		_, err = s.Update("1", newName, newDescription, OSM, nil, "Peter")
+		if err == nil {
+			return errors.New("Data source OSM with stored Overpass query should not be possible")
+		}
+		project, err = s.Update("1", newName, newDescription, Overpass, nil, "Peter")
+		if err != nil {
+			return errors.New(fmt.Sprintf("Keeping data source with stored Overpass query should be possible: %s", err))
+		}
+		if project.JosmDataSource != Overpass || project.OverpassQuery == "" {
+			return errors.New(fmt.Sprintf("JOSM configuration should be kept: %#v", project))
+		}
+
 		// With non-owner (Maria)
-		_, err = s.Update("1", "skfgkf", "sadkfzh", OSM, "Maria")
+		_, err = s.Update("1", "skfgkf", "sadkfzh", Overpass, nil, "Maria")
 		if err == nil {
 			return errors.New("Updating name should not be possible for non-owner user Maria")
 		}
 
 		// Empty name
-		_, err = s.Update("1", "  ", "adsfkjg", OSM, "Peter")
+		_, err = s.Update("1", "  ", "adsfkjg", Overpass, nil, "Peter")
 		if err == nil {
 			return errors.New("Updating name should not be possible with empty name")
 		}
@@ -619,15 +764,1548 @@ func TestUpdate(t *testing.T) {
 		config.Conf.MaxDescriptionLength = 10 // lower the border for test purposes
 		newDescription = "This is some too long description"
 
-		_, err = s.Update("1", "name", newDescription, OSM, "Peter")
+		_, err = s.Update("1", "name", newDescription, Overpass, nil, "Peter")
 		if err == nil {
 			return errors.New(fmt.Sprintf("Updating project description should not work. Allowed description length %d but was %d", config.Conf.MaxDescriptionLength, len(newDescription)))
 		}
 
+		// The length is counted in characters and not in bytes
+		newDescription = "**äöüäöü**"
+		project, err = s.Update("1", "name", newDescription, Overpass, nil, "Peter")
+		if err != nil {
+			return errors.New(fmt.Sprintf("Description with %d characters should be allowed: %s", config.Conf.MaxDescriptionLength, err))
+		}
+		if project.DescriptionHtml != "<p><strong>äöüäöü</strong></p>" {
+			return errors.New(fmt.Sprintf("Wrong HTML of description: %s", project.DescriptionHtml))
+		}
+
+		return nil
+	})
+}
+
+func TestUpdateVisibility(t *testing.T) {
+	h.Run(t, func() error {
+		visibility := &VisibilityDto{
+			IsPublic:              true,
+			PublicUsersVisible:    false,
+			PublicCommentsVisible: true,
+		}
+
+		project, err := s.UpdateVisibility("1", visibility, "Peter")
+		if err != nil {
+			return err
+		}
+
+		if !project.IsPublic || project.PublicUsersVisible || !project.PublicCommentsVisible {
+			return errors.New(fmt.Sprintf("Visibility not set correctly: %t, %t, %t", project.IsPublic, project.PublicUsersVisible, project.PublicCommentsVisible))
+		}
+		if project.TotalProcessPoints != 10 || project.DoneProcessPoints != 0 {
+			return errors.New("Process points on project not set correctly")
+		}
+
+		// Non-owner
+		_, err = s.UpdateVisibility("1", visibility, "Maria")
+		if err == nil {
+			return errors.New("Updating visibility should not be possible for non-owner user Maria")
+		}
+
+		return nil
+	})
+}
+
+func TestGetPublicProject(t *testing.T) {
+	h.Run(t, func() error {
+		// Not public yet
+		_, err := s.GetPublicProject("2")
+		if err == nil {
+			return errors.New("Getting public view of private project should not work")
+		}
+
+		_, err = s.UpdateVisibility("2", &VisibilityDto{IsPublic: true}, "Maria")
+		if err != nil {
+			return err
+		}
+
+		publicProject, err := s.GetPublicProject("2")
+		if err != nil {
+			return err
+		}
+
+		if publicProject.Name != "Project 2" {
+			return errors.New("Project name not matching")
+		}
+		if publicProject.TotalProcessPoints != 308 || publicProject.DoneProcessPoints != 154 {
+			return errors.New("Process points on project not set correctly")
+		}
+		if publicProject.TaskCount != 5 || publicProject.DoneTaskCount != 1 || publicProject.UserCount != 6 {
+			return errors.New(fmt.Sprintf("Aggregated numbers not matching: %d, %d, %d", publicProject.TaskCount, publicProject.DoneTaskCount, publicProject.UserCount))
+		}
+		if publicProject.Owner != "" || publicProject.Users != nil || publicProject.Comments != nil {
+			return errors.New("Users and comments should not be visible")
+		}
+		for _, t := range publicProject.Tasks {
+			if t.AssignedUser != "" || t.Comments != nil {
+				return errors.New(fmt.Sprintf("Users and comments of task %s should not be visible", t.Id))
+			}
+		}
+
+		// With users and comments
+		_, err = s.UpdateVisibility("2", &VisibilityDto{IsPublic: true, PublicUsersVisible: true, PublicCommentsVisible: true}, "Maria")
+		if err != nil {
+			return err
+		}
+
+		publicProject, err = s.GetPublicProject("2")
+		if err != nil {
+			return err
+		}
+
+		if publicProject.Owner != "Maria" || len(publicProject.Users) != 6 || publicProject.Comments == nil {
+			return errors.New("Users and comments should be visible")
+		}
+		if publicProject.Tasks[1].AssignedUser != "Maria" {
+			return errors.New(fmt.Sprintf("Assigned user of task 3 should be visible but was '%s'", publicProject.Tasks[1].AssignedUser))
+		}
+
+		// Not existing project
+		_, err = s.GetPublicProject("2284527")
+		if err == nil {
+			return errors.New("Getting public view of not existing project should not work")
+		}
+
+		return nil
+	})
+}
+
+func TestGetProjectSummaries(t *testing.T) {
+	h.Run(t, func() error {
+		page, err := s.GetProjectSummaries("Maria", &SummaryFilterDto{Page: 1, PageSize: DefaultSummaryPageSize, SortBy: SortByName})
+		if err != nil {
+			return err
+		}
+
+		if page.TotalCount != 2 || len(page.Summaries) != 2 {
+			return errors.New(fmt.Sprintf("Expected 2 summaries but got %d (total %d)", len(page.Summaries), page.TotalCount))
+		}
+
+		summary := page.Summaries[1]
+		if summary.Id != "2" {
+			return errors.New(fmt.Sprintf("Second summary should be project 2 but was %s", summary.Id))
+		}
+		if summary.TaskCount != 5 || summary.DoneTaskCount != 1 {
+			return errors.New(fmt.Sprintf("Task counts not matching: %#v", summary))
+		}
+		if summary.TotalProcessPoints != 308 || summary.DoneProcessPoints != 154 {
+			return errors.New(fmt.Sprintf("Process points not matching: %#v", summary))
+		}
+		if summary.UserCount != 6 {
+			return errors.New(fmt.Sprintf("User count should be 6 but was %d", summary.UserCount))
+		}
+
+		// Pagination
+		page, err = s.GetProjectSummaries("Maria", &SummaryFilterDto{Page: 2, PageSize: 1, SortBy: SortByName})
+		if err != nil {
+			return err
+		}
+		if page.TotalCount != 2 || len(page.Summaries) != 1 || page.Summaries[0].Id != "2" {
+			return errors.New(fmt.Sprintf("Second page not matching: %#v", page))
+		}
+
+		// Search
+		page, err = s.GetProjectSummaries("Maria", &SummaryFilterDto{Page: 1, PageSize: DefaultSummaryPageSize, SortBy: SortByName, Search: "2"})
+		if err != nil {
+			return err
+		}
+		if page.TotalCount != 1 || page.Summaries[0].Id != "2" {
+			return errors.New(fmt.Sprintf("Search result not matching: %#v", page))
+		}
+
+		// Sort by progress
+		page, err = s.GetProjectSummaries("Maria", &SummaryFilterDto{Page: 1, PageSize: DefaultSummaryPageSize, SortBy: SortByProgress, Descending: true})
+		if err != nil {
+			return err
+		}
+		if page.Summaries[0].Id != "2" {
+			return errors.New(fmt.Sprintf("Project 2 should have the most progress but first summary was %s", page.Summaries[0].Id))
+		}
+
+		return nil
+	})
+}
+
+func TestGetProjectSummariesWithInvalidFilter(t *testing.T) {
+	h.Run(t, func() error {
+		_, err := s.GetProjectSummaries("Maria", &SummaryFilterDto{Page: 1, PageSize: DefaultSummaryPageSize, SortBy: "foo"})
+		if err == nil {
+			return errors.New("Unknown sort field should not be allowed")
+		}
+
+		_, err = s.GetProjectSummaries("Maria", &SummaryFilterDto{Page: 0, PageSize: DefaultSummaryPageSize, SortBy: SortByName})
+		if err == nil {
+			return errors.New("Page 0 should not be allowed")
+		}
+
+		_, err = s.GetProjectSummaries("Maria", &SummaryFilterDto{Page: 1, PageSize: MaxSummaryPageSize + 1, SortBy: SortByName})
+		if err == nil {
+			return errors.New("Too large page size should not be allowed")
+		}
+
+		return nil
+	})
+}
+
+func TestGetStatistics(t *testing.T) {
+	h.Run(t, func() error {
+		statistics, err := s.GetStatistics("2", "Maria")
+		if err != nil {
+			return err
+		}
+
+		if statistics.TaskCount != 5 || statistics.FinishedTaskCount != 1 || statistics.AssignedTaskCount != 2 || statistics.UnassignedTaskCount != 2 {
+			return errors.New(fmt.Sprintf("Task counts not matching: %#v", statistics))
+		}
+		if statistics.TotalProcessPoints != 308 || statistics.DoneProcessPoints != 154 {
+			return errors.New(fmt.Sprintf("Process points not matching: %#v", statistics))
+		}
+		if statistics.MappedArea <= 0 {
+			return errors.New(fmt.Sprintf("Mapped area should be positive but was %f", statistics.MappedArea))
+		}
+
+		expectedFirstActivity := time.Date(2021, 2, 14, 8, 0, 0, 0, time.UTC)
+		if statistics.FirstActivity == nil || !statistics.FirstActivity.Equal(expectedFirstActivity) {
+			return errors.New(fmt.Sprintf("First activity should be %s but was %s", expectedFirstActivity, statistics.FirstActivity))
+		}
+		expectedLastActivity := time.Date(2021, 2, 17, 9, 30, 0, 0, time.UTC)
+		if statistics.LastActivity == nil || !statistics.LastActivity.Equal(expectedLastActivity) {
+			return errors.New(fmt.Sprintf("Last activity should be %s but was %s", expectedLastActivity, statistics.LastActivity))
+		}
+
+		if len(statistics.Members) != 6 {
+			return errors.New(fmt.Sprintf("Expected statistics for 6 members but got %d", len(statistics.Members)))
+		}
+		maria := statistics.Members[0]
+		if maria.UserId != "Maria" || maria.ProcessPointsSet != 50 || maria.FinishedTaskCount != 0 || maria.CommentCount != 1 {
+			return errors.New(fmt.Sprintf("Statistics of Maria not matching: %#v", maria))
+		}
+		john := statistics.Members[1]
+		if john.UserId != "John" || john.ProcessPointsSet != 100 || john.FinishedTaskCount != 1 || john.CommentCount != 0 {
+			return errors.New(fmt.Sprintf("Statistics of John not matching: %#v", john))
+		}
+		anna := statistics.Members[2]
+		if anna.UserId != "Anna" || anna.ProcessPointsSet != 0 || anna.FinishedTaskCount != 0 || anna.CommentCount != 0 {
+			return errors.New(fmt.Sprintf("Statistics of Anna not matching: %#v", anna))
+		}
+
+		// Setting process points is counted as contribution
+		_, err = taskService.SetProcessPoints("3", 100, "Maria")
+		if err != nil {
+			return err
+		}
+
+		statistics, err = s.GetStatistics("2", "Maria")
+		if err != nil {
+			return err
+		}
+		maria = statistics.Members[0]
+		if maria.ProcessPointsSet != 100 || maria.FinishedTaskCount != 1 {
+			return errors.New(fmt.Sprintf("Statistics of Maria not updated: %#v", maria))
+		}
+		if statistics.FinishedTaskCount != 2 || statistics.AssignedTaskCount != 1 {
+			return errors.New(fmt.Sprintf("Task counts not updated: %#v", statistics))
+		}
+
+		// Resetting and finishing the task again neither reduces the points nor counts the task twice
+		_, err = taskService.SetProcessPoints("3", 0, "Maria")
+		if err != nil {
+			return err
+		}
+		_, err = taskService.SetProcessPoints("3", 100, "Maria")
+		if err != nil {
+			return err
+		}
+
+		statistics, err = s.GetStatistics("2", "Maria")
+		if err != nil {
+			return err
+		}
+		maria = statistics.Members[0]
+		if maria.ProcessPointsSet != 200 || maria.FinishedTaskCount != 1 {
+			return errors.New(fmt.Sprintf("Statistics of Maria after reset not matching: %#v", maria))
+		}
+
+		// Non-member
+		_, err = s.GetStatistics("2", "Peter")
+		if err == nil {
+			return errors.New("Non-member should not be able to get statistics")
+		}
+
 		return nil
 	})
 }
 
+func TestGetProgress(t *testing.T) {
+	h.Run(t, func() error {
+		from := time.Date(2021, 2, 14, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2021, 2, 17, 0, 0, 0, 0, time.UTC)
+
+		progress, err := s.GetProgress("2", &ProgressFilterDto{From: &from, To: &to, Interval: IntervalDay}, "Maria")
+		if err != nil {
+			return err
+		}
+
+		expectedDonePoints := []int{0, 100, 150, 154}
+		if len(progress.Points) != len(expectedDonePoints) {
+			return errors.New(fmt.Sprintf("Expected %d points but got %d", len(expectedDonePoints), len(progress.Points)))
+		}
+		for i, p := range progress.Points {
+			expectedDate := from.Add(time.Duration(i) * 24 * time.Hour)
+			if !p.Date.Equal(expectedDate) || p.DoneProcessPoints != expectedDonePoints[i] || p.TotalProcessPoints != 308 {
+				return errors.New(fmt.Sprintf("Point %d should be at %s with %d points but was %#v", i, expectedDate, expectedDonePoints[i], p))
+			}
+		}
+
+		// All changes are long ago, so there's no velocity
+		if progress.Velocity != 0 || progress.EstimatedCompletionDate != nil {
+			return errors.New(fmt.Sprintf("There should be no velocity and estimation: %#v", progress))
+		}
+
+		// Recent change
+		_, err = taskService.SetProcessPoints("3", 100, "Maria")
+		if err != nil {
+			return err
+		}
+
+		progress, err = s.GetProgress("2", &ProgressFilterDto{Interval: IntervalWeek}, "Maria")
+		if err != nil {
+			return err
+		}
+
+		lastPoint := progress.Points[len(progress.Points)-1]
+		if lastPoint.DoneProcessPoints != 204 {
+			return errors.New(fmt.Sprintf("Latest point should have 204 points but had %d", lastPoint.DoneProcessPoints))
+		}
+		if progress.Velocity <= 0 || progress.EstimatedCompletionDate == nil || !progress.EstimatedCompletionDate.After(time.Now()) {
+			return errors.New(fmt.Sprintf("There should be a velocity and estimation in the future: %#v", progress))
+		}
+
+		return nil
+	})
+}
+
+func TestCountProgressPoints(t *testing.T) {
+	from := time.Date(2021, 2, 14, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		to       time.Time
+		expected int64
+	}{
+		{from.Add(time.Hour), 2},
+		{from.Add(90 * time.Minute), 3},
+		{from.Add(24 * time.Hour), 25},
+	}
+
+	for i, c := range cases {
+		count := countProgressPoints(from, c.to, time.Hour)
+		if count != c.expected {
+			t.Errorf("Case %d: expected %d points but got %d", i, c.expected, count)
+		}
+	}
+}
+
+func TestGetProgressWithInvalidFilter(t *testing.T) {
+	h.Run(t, func() error {
+		_, err := s.GetProgress("2", &ProgressFilterDto{Interval: "month"}, "Maria")
+		if err == nil {
+			return errors.New("Unknown interval should not be allowed")
+		}
+
+		from := time.Date(2021, 2, 14, 0, 0, 0, 0, time.UTC)
+		to := from.Add(-time.Hour)
+		_, err = s.GetProgress("2", &ProgressFilterDto{From: &from, To: &to, Interval: IntervalDay}, "Maria")
+		if err == nil {
+			return errors.New("Start date after end date should not be allowed")
+		}
+
+		to = from.Add(MaxProgressPoints * time.Hour)
+		_, err = s.GetProgress("2", &ProgressFilterDto{From: &from, To: &to, Interval: IntervalHour}, "Maria")
+		if err == nil {
+			return errors.New("Too many points should not be allowed")
+		}
+
+		// Exactly the maximum number of points is allowed
+		to = from.Add((MaxProgressPoints - 1) * time.Hour)
+		progress, err := s.GetProgress("2", &ProgressFilterDto{From: &from, To: &to, Interval: IntervalHour}, "Maria")
+		if err != nil {
+			return err
+		}
+		if len(progress.Points) != MaxProgressPoints {
+			return errors.New(fmt.Sprintf("Expected %d points but got %d", MaxProgressPoints, len(progress.Points)))
+		}
+
+		// The default start date of long-running projects is clamped to the latest points
+		progress, err = s.GetProgress("2", &ProgressFilterDto{Interval: IntervalHour}, "Maria")
+		if err != nil {
+			return err
+		}
+		if len(progress.Points) != MaxProgressPoints {
+			return errors.New(fmt.Sprintf("Default time series should be clamped to %d points but got %d", MaxProgressPoints, len(progress.Points)))
+		}
+
+		_, err = s.GetProgress("2", &ProgressFilterDto{Interval: IntervalDay}, "Peter")
+		if err == nil {
+			return errors.New("Non-member should not be able to get progress")
+		}
+
+		return nil
+	})
+}
+
+func TestUpdateSchedule(t *testing.T) {
+	h.Run(t, func() error {
+		startDate := time.Now().Add(-time.Hour).UTC().Round(time.Second)
+		dueDate := time.Now().Add(time.Hour).UTC().Round(time.Second)
+
+		project, err := s.UpdateSchedule("2", &startDate, &dueDate, "Maria")
+		if err != nil {
+			return err
+		}
+
+		if project.StartDate == nil || !project.StartDate.Equal(startDate) || project.DueDate == nil || !project.DueDate.Equal(dueDate) {
+			return errors.New(fmt.Sprintf("Dates not set correctly: %s, %s", project.StartDate, project.DueDate))
+		}
+		// Half of the time passed and half of the process points are done
+		if project.IsOverdue {
+			return errors.New("Project should not be overdue")
+		}
+
+		// Remove dates again
+		project, err = s.UpdateSchedule("2", nil, nil, "Maria")
+		if err != nil {
+			return err
+		}
+		if project.StartDate != nil || project.DueDate != nil || project.IsOverdue || project.IsAtRisk {
+			return errors.New(fmt.Sprintf("Dates and flags should be unset: %#v", project))
+		}
+
+		// Start after due date
+		_, err = s.UpdateSchedule("2", &dueDate, &startDate, "Maria")
+		if err == nil {
+			return errors.New("Start date after due date should not be allowed")
+		}
+
+		// Non-owner
+		_, err = s.UpdateSchedule("2", &startDate, &dueDate, "John")
+		if err == nil {
+			return errors.New("Non-owner should not be able to update the schedule")
+		}
+
+		return nil
+	})
+}
+
+func TestMarkDueDatePassed(t *testing.T) {
+	h.Run(t, func() error {
+		dueDate := time.Now().Add(-time.Minute).UTC()
+		_, err := s.UpdateSchedule("2", nil, &dueDate, "Maria")
+		if err != nil {
+			return err
+		}
+
+		projects, err := s.MarkDueDatePassed()
+		if err != nil {
+			return err
+		}
+		if len(projects) != 1 || projects[0].Id != "2" || !projects[0].IsOverdue {
+			return errors.New(fmt.Sprintf("Project 2 should be overdue: %#v", projects))
+		}
+
+		// Only reported once
+		projects, err = s.MarkDueDatePassed()
+		if err != nil {
+			return err
+		}
+		if len(projects) != 0 {
+			return errors.New(fmt.Sprintf("Passed due date should only be reported once but got %d projects", len(projects)))
+		}
+
+		// Setting the same due date again (e.g. when only the start date changes) doesn't report it again
+		startDate := dueDate.Add(-time.Hour)
+		_, err = s.UpdateSchedule("2", &startDate, &dueDate, "Maria")
+		if err != nil {
+			return err
+		}
+		projects, err = s.MarkDueDatePassed()
+		if err != nil {
+			return err
+		}
+		if len(projects) != 0 {
+			return errors.New(fmt.Sprintf("Unchanged due date should not be reported again but got %d projects", len(projects)))
+		}
+
+		// Setting a new due date reports it again
+		newDueDate := dueDate.Add(-time.Minute)
+		_, err = s.UpdateSchedule("2", nil, &newDueDate, "Maria")
+		if err != nil {
+			return err
+		}
+		projects, err = s.MarkDueDatePassed()
+		if err != nil {
+			return err
+		}
+		if len(projects) != 1 {
+			return errors.New(fmt.Sprintf("New due date should be reported again but got %d projects", len(projects)))
+		}
+
+		return nil
+	})
+}
+
+func TestUpdateJosmConfig(t *testing.T) {
+	h.Run(t, func() error {
+		josmConfig := &JosmConfigDto{
+			JosmDataSource: Overpass,
+			OverpassQuery:  "nwr[building]({{bbox}});out meta;",
+			Imagery: []ImageryLayer{
+				{Name: "Aerial", Url: "https://tiles.example.com/{zoom}/{x}/{y}.png", Type: ImageryTms, Attribution: "Example"},
+			},
+		}
+
+		p, err := s.UpdateJosmConfig("1", josmConfig, "Peter")
+		if err != nil {
+			return err
+		}
+
+		if p.JosmDataSource != Overpass || p.OverpassQuery != josmConfig.OverpassQuery || p.JosmDownloadUrl != "" {
+			return errors.New(fmt.Sprintf("JOSM data source not updated: %#v", p))
+		}
+		if len(p.Imagery) != 1 || p.Imagery[0] != josmConfig.Imagery[0] {
+			return errors.New(fmt.Sprintf("Imagery not updated: %#v", p.Imagery))
+		}
+
+		// Read again to make sure the imagery has been stored
+		p, err = s.GetProject("1", "Maria")
+		if err != nil {
+			return err
+		}
+		if len(p.Imagery) != 1 || p.Imagery[0].Name != "Aerial" {
+			return errors.New(fmt.Sprintf("Imagery not stored: %#v", p.Imagery))
+		}
+
+		// Only the owner can change the configuration
+		_, err = s.UpdateJosmConfig("1", &JosmConfigDto{JosmDataSource: OSM}, "Maria")
+		if err == nil {
+			return errors.New("Non-owner should not be able to update the JOSM configuration")
+		}
+
+		return nil
+	})
+}
+
+func TestValidateJosmConfig(t *testing.T) {
+	tmsLayer := ImageryLayer{Name: "Aerial", Url: "https://tiles.example.com/{zoom}/{x}/{y}.png", Type: ImageryTms}
+
+	validConfigs := []*JosmConfigDto{
+		{JosmDataSource: OSM},
+		{JosmDataSource: Overpass},
+		{JosmDataSource: Overpass, OverpassQuery: "way[highway]({{bbox}});out meta;"},
+		{JosmDataSource: Overpass, OverpassQuery: "({{bbox}});" + strings.Repeat("ä", MaxOverpassQueryLength-len("({{bbox}});"))},
+		{JosmDataSource: Custom, JosmDownloadUrl: "https://example.com/data.osm"},
+		{JosmDataSource: OSM, Imagery: []ImageryLayer{tmsLayer, {Name: "WMS", Url: "http://wms.example.com/?SERVICE=WMS", Type: ImageryWms}}},
+	}
+	for i, c := range validConfigs {
+		err := validateJosmConfig(c)
+		if err != nil {
+			t.Errorf("Valid config %d should be accepted: %s", i, err.Error())
+		}
+	}
+
+	invalidConfigs := []*JosmConfigDto{
+		{JosmDataSource: "FOO"},
+		{JosmDataSource: Overpass, OverpassQuery: "way[highway];out meta;"},
+		{JosmDataSource: OSM, OverpassQuery: "way[highway]({{bbox}});out meta;"},
+		{JosmDataSource: Overpass, OverpassQuery: "({{bbox}});" + strings.Repeat("a", MaxOverpassQueryLength)},
+		{JosmDataSource: Custom},
+		{JosmDataSource: Custom, JosmDownloadUrl: "ftp://example.com/data.osm"},
+		{JosmDataSource: OSM, JosmDownloadUrl: "https://example.com/data.osm"},
+		{JosmDataSource: OSM, Imagery: []ImageryLayer{{Name: " ", Url: tmsLayer.Url, Type: ImageryTms}}},
+		{JosmDataSource: OSM, Imagery: []ImageryLayer{{Name: "Aerial", Url: tmsLayer.Url, Type: "WMTS"}}},
+		{JosmDataSource: OSM, Imagery: []ImageryLayer{{Name: "Aerial", Url: "tiles/{zoom}/{x}/{y}.png", Type: ImageryTms}}},
+		{JosmDataSource: OSM, Imagery: make([]ImageryLayer, MaxImageryLayers+1)},
+	}
+	for i, c := range invalidConfigs {
+		err := validateJosmConfig(c)
+		if err == nil {
+			t.Errorf("Invalid config %d should not be accepted", i)
+		}
+	}
+}
+
+func TestGetEditorLinks(t *testing.T) {
+	h.Run(t, func() error {
+		links, err := s.GetEditorLinks("3", "John")
+		if err != nil {
+			return err
+		}
+
+		if links.TaskId != "3" || !strings.Contains(links.JosmData, "/load_and_zoom?") || !strings.Contains(links.Id, "comment=%23stm%20%23stm-project-2") {
+			return errors.New(fmt.Sprintf("Links not matching: %#v", links))
+		}
+
+		// Not a member of project 2
+		_, err = s.GetEditorLinks("3", "Peter")
+		if err == nil {
+			return errors.New("Non-member should not get editor links")
+		}
+
+		return nil
+	})
+}
+
+func TestValidateChangesetSettings(t *testing.T) {
+	changeset := &ChangesetDto{ChangesetComment: "  Buildings ", ChangesetHashtags: []string{" #a", "#b "}}
+	err := validateChangesetSettings(changeset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changeset.ChangesetComment != "Buildings" || changeset.ChangesetHashtags[0] != "#a" || changeset.ChangesetHashtags[1] != "#b" {
+		t.Errorf("Values should be trimmed: %#v", changeset)
+	}
+
+	// Characters and not bytes are counted
+	changeset = &ChangesetDto{ChangesetComment: strings.Repeat("ä", MaxChangesetTagLength)}
+	err = validateChangesetSettings(changeset)
+	if err != nil {
+		t.Errorf("Comment with %d non-ASCII characters should be valid: %s", MaxChangesetTagLength, err)
+	}
+
+	changeset = &ChangesetDto{}
+	err = validateChangesetSettings(changeset)
+	if err != nil || changeset.ChangesetHashtags == nil {
+		t.Errorf("Empty settings should be valid and hashtags should not be nil: %#v", changeset)
+	}
+
+	invalidChangesets := []*ChangesetDto{
+		{ChangesetComment: strings.Repeat("a", MaxChangesetTagLength+1)},
+		{ChangesetSource: strings.Repeat("a", MaxChangesetTagLength+1)},
+		{ChangesetSource: strings.Repeat("ä", MaxChangesetTagLength+1)},
+		{ChangesetHashtags: []string{"stm"}},
+		{ChangesetHashtags: []string{"#"}},
+		{ChangesetHashtags: []string{"#stm project"}},
+		{ChangesetHashtags: []string{"#stm;#foo"}},
+		{ChangesetHashtags: []string{"#" + strings.Repeat("a", 200), "#" + strings.Repeat("b", 100)}},
+	}
+	for i, c := range invalidChangesets {
+		if validateChangesetSettings(c) == nil {
+			t.Errorf("Invalid changeset settings %d should not be accepted", i)
+		}
+	}
+}
+
+func TestGetEditorLinksOfProject(t *testing.T) {
+	tsk := &task.Task{
+		Id:       "7",
+		Name:     "Harbour",
+		Geometry: `{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[9.9,53.5],[10.1,53.5],[10.1,53.6],[9.9,53.5]]]},"properties":null}`,
+	}
+	project := &Project{
+		Id:             "42",
+		JosmDataSource: OSM,
+		Imagery:        []ImageryLayer{{Name: "Aerial", Url: "https://tiles.example.com/{zoom}/{x}/{y}.png", Type: ImageryTms}},
+	}
+
+	links, err := getEditorLinks(project, tsk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(links.JosmTask, JosmRemoteControlUrl+"/load_data?new_layer=true&layer_name=task+Harbour&upload_policy=never&data=%3Cosm") {
+		t.Errorf("Unexpected task URL: %s", links.JosmTask)
+	}
+	expectedDataUrl := JosmRemoteControlUrl + "/load_and_zoom?new_layer=true&left=9.900000&right=10.100000&top=53.600000&bottom=53.500000&changeset_comment=%23stm+%23stm-project-42+&changeset_hashtags=%23stm%3B%23stm-project-42"
+	if links.JosmData != expectedDataUrl {
+		t.Errorf("Unexpected data URL: %s", links.JosmData)
+	}
+	if len(links.JosmImagery) != 1 || links.JosmImagery[0] != JosmRemoteControlUrl+"/imagery?title=Aerial&type=tms&url=https%3A%2F%2Ftiles.example.com%2F%7Bzoom%7D%2F%7Bx%7D%2F%7By%7D.png" {
+		t.Errorf("Unexpected imagery URLs: %v", links.JosmImagery)
+	}
+	if !strings.HasPrefix(links.Id, IdEditorUrl+"#map=") || !strings.HasSuffix(links.Id, "/10.000000&comment=%23stm%20%23stm-project-42%20&hashtags=%23stm%2C%23stm-project-42") {
+		t.Errorf("Unexpected iD URL: %s", links.Id)
+	}
+
+	// Changeset settings of the project replace the defaults
+	project.ChangesetComment = "Mapping buildings"
+	project.ChangesetSource = "Bing"
+	project.ChangesetHashtags = []string{"#buildings", "#hamburg"}
+	links, err = getEditorLinks(project, tsk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(links.JosmData, "&changeset_comment=Mapping+buildings&changeset_hashtags=%23buildings%3B%23hamburg&changeset_source=Bing") {
+		t.Errorf("Unexpected data URL: %s", links.JosmData)
+	}
+	if !strings.HasSuffix(links.Id, "&comment=Mapping%20buildings&hashtags=%23buildings%2C%23hamburg&source=Bing") {
+		t.Errorf("Unexpected iD URL: %s", links.Id)
+	}
+
+	// Separators of the iD URL hash are escaped
+	project.ChangesetComment = "Roads & paths = 100%"
+	project.ChangesetSource = "Bing+Survey"
+	links, err = getEditorLinks(project, tsk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(links.Id, "&comment=Roads%20%26%20paths%20%3D%20100%25&hashtags=%23buildings%2C%23hamburg&source=Bing%2BSurvey") {
+		t.Errorf("Unexpected iD URL: %s", links.Id)
+	}
+
+	// Custom Overpass query with bounding box in Overpass order
+	project.JosmDataSource = Overpass
+	project.OverpassQuery = "way[highway]({{bbox}});out meta;"
+	links, err = getEditorLinks(project, tsk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataUrl, err := url.QueryUnescape(strings.TrimPrefix(links.JosmData, JosmRemoteControlUrl+"/import?new_layer=true&url="))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dataUrl != OverpassApiUrl+"?data="+url.QueryEscape("way[highway](53.500000,9.900000,53.600000,10.100000);out meta;") {
+		t.Errorf("Unexpected Overpass URL: %s", dataUrl)
+	}
+
+	// Unsupported geometry
+	tsk.Geometry = `{"type":"Feature","geometry":{"type":"Point","coordinates":[9.9,53.5]},"properties":null}`
+	_, err = getEditorLinks(project, tsk)
+	if err == nil {
+		t.Errorf("Points should not be supported")
+	}
+}
+
+func TestUpdateAssignmentPolicy(t *testing.T) {
+	h.Run(t, func() error {
+		p, err := s.UpdateAssignmentPolicy("2", &AssignmentDto{Policy: AssignmentAboveMemberCount, Threshold: 10, MaxAssignedTasks: 2}, "Maria")
+		if err != nil {
+			return err
+		}
+
+		if p.AssignmentPolicy != AssignmentAboveMemberCount || p.AssignmentThreshold != 10 || p.MaxAssignedTasks != 2 {
+			return errors.New(fmt.Sprintf("Assignment policy not updated: %#v", p))
+		}
+		if p.NeedsAssignment {
+			return errors.New("Project with six members and threshold 10 should not need assignments")
+		}
+
+		// Only the owner can change the policy
+		_, err = s.UpdateAssignmentPolicy("2", &AssignmentDto{Policy: AssignmentNever}, "John")
+		if err == nil {
+			return errors.New("Non-owner should not be able to update the assignment policy")
+		}
+
+		// Invalid values
+		_, err = s.UpdateAssignmentPolicy("2", &AssignmentDto{Policy: "SOMETIMES"}, "Maria")
+		if err == nil {
+			return errors.New("Unknown policy should not be accepted")
+		}
+		_, err = s.UpdateAssignmentPolicy("2", &AssignmentDto{Policy: AssignmentAlways, MaxAssignedTasks: -1}, "Maria")
+		if err == nil {
+			return errors.New("Negative limit should not be accepted")
+		}
+
+		return nil
+	})
+}
+
+func TestUpdateComment(t *testing.T) {
+	h.Run(t, func() error {
+		// Comment 3 is written by Maria on task 3 of project 2
+		p, _, err := s.UpdateComment("3", &comment.DraftDto{Text: "Done here"}, "Maria")
+		if err != nil {
+			return err
+		}
+
+		if p.Id != "2" {
+			return errors.New(fmt.Sprintf("Wrong project %s returned", p.Id))
+		}
+		c := findComment(p, "3")
+		if c == nil || c.Text != "Done here" || c.LastEditDate == nil {
+			return errors.New(fmt.Sprintf("Comment not updated: %#v", c))
+		}
+
+		edits, err := s.GetCommentHistory("3", "John")
+		if err != nil {
+			return err
+		}
+		if len(edits) != 1 || edits[0].Text != "Almost done here" || edits[0].EditDate == nil {
+			return errors.New(fmt.Sprintf("Wrong edit history: %#v", edits))
+		}
+
+		// Only the author can edit the comment, not even the owner
+		_, _, err = s.UpdateComment("1", &comment.DraftDto{Text: "foo"}, "Maria")
+		if err == nil {
+			return errors.New("Non-author should not be able to update comment")
+		}
+
+		// Too long text
+		_, _, err = s.UpdateComment("3", &comment.DraftDto{Text: strings.Repeat("a", config.Conf.MaxCommentLength+1)}, "Maria")
+		if err == nil {
+			return errors.New("Too long comment should not be accepted")
+		}
+
+		// The length is counted in characters and the text is rendered to HTML
+		p, _, err = s.UpdateComment("3", &comment.DraftDto{Text: "<b>" + strings.Repeat("ä", config.Conf.MaxCommentLength-3)}, "Maria")
+		if err != nil {
+			return err
+		}
+		c = findComment(p, "3")
+		if !strings.HasPrefix(c.Html, "<p>&lt;b&gt;ä") {
+			return errors.New(fmt.Sprintf("Wrong HTML of comment: %s", c.Html))
+		}
+
+		// History only visible to members
+		_, err = s.GetCommentHistory("3", "Peter")
+		if err == nil {
+			return errors.New("Non-member should not be able to see the edit history")
+		}
+
+		return nil
+	})
+}
+
+func TestAddCommentReply(t *testing.T) {
+	h.Run(t, func() error {
+		// Comment 3 is written by Maria on task 3 of project 2
+		_, err := taskService.AddComment("3", &comment.DraftDto{Text: "Great!", ParentId: "3"}, "John")
+		if err != nil {
+			return err
+		}
+
+		p, err := s.GetProject("2", "John")
+		if err != nil {
+			return err
+		}
+
+		c := findComment(p, "3")
+		if c == nil || len(c.Replies) != 1 || c.Replies[0].Text != "Great!" || c.Replies[0].ParentId != "3" {
+			return errors.New(fmt.Sprintf("Reply not added to comment 3: %#v", c))
+		}
+		for _, t := range p.Tasks {
+			if t.Id == "3" && len(t.Comments) != 1 {
+				return errors.New(fmt.Sprintf("Reply should not be a top-level comment of task 3: %#v", t.Comments))
+			}
+		}
+
+		// Parent comment in a different comment list
+		_, err = taskService.AddComment("2", &comment.DraftDto{Text: "foo", ParentId: "3"}, "John")
+		if err == nil {
+			return errors.New("Reply to comment of other task should not be possible")
+		}
+		_, err = s.AddComment("2", &comment.DraftDto{Text: "foo", ParentId: "3"}, "John")
+		if err == nil {
+			return errors.New("Reply on project to comment of task should not be possible")
+		}
+
+		// Not existing parent comment
+		_, err = taskService.AddComment("3", &comment.DraftDto{Text: "foo", ParentId: "1234"}, "John")
+		if err == nil {
+			return errors.New("Reply to not existing comment should not be possible")
+		}
+
+		return nil
+	})
+}
+
+func TestAddCommentWithMentions(t *testing.T) {
+	h.Run(t, func() error {
+		c, err := s.AddComment("2", &comment.DraftDto{Text: "@John @Anna please fix the roads, thanks @John"}, "Maria")
+		if err != nil {
+			return err
+		}
+		if len(c.Mentions) != 2 || c.Mentions[0] != "John" || c.Mentions[1] != "Anna" {
+			return errors.New(fmt.Sprintf("Wrong mentions: %v", c.Mentions))
+		}
+
+		c, err = taskService.AddComment("3", &comment.DraftDto{Text: "@Donny can you help?"}, "Maria")
+		if err != nil {
+			return err
+		}
+		if len(c.Mentions) != 1 || c.Mentions[0] != "Donny" {
+			return errors.New(fmt.Sprintf("Wrong mentions: %v", c.Mentions))
+		}
+
+		// Peter is not a member of project 2, so the mention is ignored with a warning
+		c, err = s.AddComment("2", &comment.DraftDto{Text: "@Peter please fix the roads"}, "Maria")
+		if err != nil {
+			return err
+		}
+		if len(c.Mentions) != 0 || len(c.Warnings) != 1 {
+			return errors.New(fmt.Sprintf("Mention of non-member should be ignored with warning: %#v", c))
+		}
+		c, err = taskService.AddComment("3", &comment.DraftDto{Text: "@Peter @all please fix the roads"}, "Maria")
+		if err != nil {
+			return err
+		}
+		if len(c.Mentions) != 0 || len(c.Warnings) != 2 {
+			return errors.New(fmt.Sprintf("Mentions of non-members should be ignored with warnings: %#v", c))
+		}
+
+		// Editing a comment updates the mentions
+		p, _, err := s.UpdateComment("3", &comment.DraftDto{Text: "@Clara almost done here"}, "Maria")
+		if err != nil {
+			return err
+		}
+		edited := findComment(p, "3")
+		if len(edited.Mentions) != 1 || edited.Mentions[0] != "Clara" {
+			return errors.New(fmt.Sprintf("Wrong mentions after edit: %v", edited.Mentions))
+		}
+
+		return nil
+	})
+}
+
+func TestGetComments(t *testing.T) {
+	h.Run(t, func() error {
+		// Comments 1 and 2 are on task 1 of project 1
+		page, err := taskService.GetComments("1", &comment.PageFilterDto{PageSize: 1}, "Maria")
+		if err != nil {
+			return err
+		}
+		if len(page.Comments) != 1 || page.Comments[0].Id != "1" || page.NextCursor != "1" {
+			return errors.New(fmt.Sprintf("Wrong first page: %#v", page))
+		}
+
+		page, err = taskService.GetComments("1", &comment.PageFilterDto{PageSize: 1, Cursor: page.NextCursor}, "Maria")
+		if err != nil {
+			return err
+		}
+		if len(page.Comments) != 1 || page.Comments[0].Id != "2" || page.NextCursor != "" {
+			return errors.New(fmt.Sprintf("Wrong second page: %#v", page))
+		}
+
+		since := time.Date(2021, 2, 13, 0, 0, 0, 0, time.UTC)
+		page, err = taskService.GetComments("1", &comment.PageFilterDto{PageSize: comment.DefaultPageSize, Since: &since}, "Maria")
+		if err != nil {
+			return err
+		}
+		if len(page.Comments) != 1 || page.Comments[0].Id != "1" {
+			return errors.New(fmt.Sprintf("Only comment 1 should be newer than %s: %#v", since, page))
+		}
+
+		// Replies are part of the thread and an edited reply makes the thread appear in incremental fetches
+		_, err = s.AddComment("2", &comment.DraftDto{Text: "First"}, "Maria")
+		if err != nil {
+			return err
+		}
+		_, err = s.AddComment("2", &comment.DraftDto{Text: "Second"}, "Maria")
+		if err != nil {
+			return err
+		}
+		_, err = s.AddComment("2", &comment.DraftDto{Text: "Reply", ParentId: "4"}, "John")
+		if err != nil {
+			return err
+		}
+
+		page, err = s.GetComments("2", &comment.PageFilterDto{PageSize: 1}, "John")
+		if err != nil {
+			return err
+		}
+		if len(page.Comments) != 1 || page.Comments[0].Id != "4" || len(page.Comments[0].Replies) != 1 || page.NextCursor != "4" {
+			return errors.New(fmt.Sprintf("Wrong page of project comments: %#v", page))
+		}
+
+		since = time.Now().UTC()
+		_, _, err = s.UpdateComment("6", &comment.DraftDto{Text: "Edited reply"}, "John")
+		if err != nil {
+			return err
+		}
+		page, err = s.GetComments("2", &comment.PageFilterDto{PageSize: comment.DefaultPageSize, Since: &since}, "John")
+		if err != nil {
+			return err
+		}
+		if len(page.Comments) != 1 || page.Comments[0].Id != "4" || page.Comments[0].Replies[0].Text != "Edited reply" {
+			return errors.New(fmt.Sprintf("Only thread 4 should be changed: %#v", page))
+		}
+
+		// Invalid requests
+		_, err = taskService.GetComments("1", &comment.PageFilterDto{PageSize: comment.DefaultPageSize}, "John")
+		if err == nil {
+			return errors.New("Non-member should not be able to get comments")
+		}
+		_, err = s.GetComments("2", &comment.PageFilterDto{PageSize: comment.MaxPageSize + 1}, "John")
+		if err == nil {
+			return errors.New("Too large page size should not be accepted")
+		}
+		_, err = s.GetComments("2", &comment.PageFilterDto{PageSize: comment.DefaultPageSize, Cursor: "foo"}, "John")
+		if err == nil {
+			return errors.New("Invalid cursor should not be accepted")
+		}
+
+		return nil
+	})
+}
+
+func TestGetProjectWithLatestComments(t *testing.T) {
+	h.Run(t, func() error {
+		_, err := s.SetCommentIssueStatus("3", comment.IssueOpen, "Maria")
+		if err != nil {
+			return err
+		}
+
+		p, err := s.GetProjectWithLatestComments("2", "Maria", 0)
+		if err != nil {
+			return err
+		}
+		for _, t := range p.Tasks {
+			if len(t.Comments) != 0 {
+				return errors.New(fmt.Sprintf("Task %s should have no comments but has %d", t.Id, len(t.Comments)))
+			}
+		}
+
+		// The open issue on task 3 is counted even though the comment itself isn't loaded
+		if p.OpenIssueCount != 1 {
+			return errors.New(fmt.Sprintf("Project should have one open issue but has %d", p.OpenIssueCount))
+		}
+		for _, t := range p.Tasks {
+			if t.Id == "3" && t.OpenIssueCount != 1 || t.Id != "3" && t.OpenIssueCount != 0 {
+				return errors.New(fmt.Sprintf("Wrong number of open issues of task %s: %d", t.Id, t.OpenIssueCount))
+			}
+		}
+
+		p, err = s.GetProjectWithLatestComments("2", "Maria", 1)
+		if err != nil {
+			return err
+		}
+		if findComment(p, "3") == nil {
+			return errors.New("Comment 3 should be the latest thread of task 3")
+		}
+
+		// John is not a member of project 1
+		_, err = s.GetProjectWithLatestComments("1", "John", 1)
+		if err == nil {
+			return errors.New("Non-member should not be able to get the project")
+		}
+
+		return nil
+	})
+}
+
+func TestSetCommentIssueStatus(t *testing.T) {
+	h.Run(t, func() error {
+		// Comment 3 is written by Maria on task 3 of project 2, Maria is also assigned to task 3 and owner of project 2
+		p, err := s.SetCommentIssueStatus("3", comment.IssueOpen, "Maria")
+		if err != nil {
+			return err
+		}
+		if findComment(p, "3").IssueStatus != comment.IssueOpen || p.OpenIssueCount != 1 {
+			return errors.New(fmt.Sprintf("Comment 3 should be an open issue: %#v", findComment(p, "3")))
+		}
+		for _, t := range p.Tasks {
+			if t.Id == "3" && t.OpenIssueCount != 1 || t.Id != "3" && t.OpenIssueCount != 0 {
+				return errors.New(fmt.Sprintf("Wrong number of open issues of task %s: %d", t.Id, t.OpenIssueCount))
+			}
+		}
+
+		// John is neither author, assignee nor owner
+		_, err = s.SetCommentIssueStatus("3", comment.IssueResolved, "John")
+		if err == nil {
+			return errors.New("John should not be able to resolve the issue")
+		}
+
+		// Comment 2 is written by Maria on task 1 of project 1, which is assigned to and owned by Peter
+		p, err = s.SetCommentIssueStatus("2", comment.IssueResolved, "Peter")
+		if err != nil {
+			return err
+		}
+		if findComment(p, "2").IssueStatus != comment.IssueResolved || p.OpenIssueCount != 0 {
+			return errors.New(fmt.Sprintf("Comment 2 should be a resolved issue: %#v", findComment(p, "2")))
+		}
+
+		// Invalid status
+		_, err = s.SetCommentIssueStatus("3", "DONE", "Maria")
+		if err == nil {
+			return errors.New("Unknown status should not be accepted")
+		}
+
+		return nil
+	})
+}
+
+func TestCommentAttachments(t *testing.T) {
+	h.Run(t, func() error {
+		config.Conf.AttachmentDirectory = t.TempDir()
+		content := []byte(`{"type": "Point", "coordinates": [9.9, 53.5]}`)
+
+		// Comment 2 is written by Maria on task 1 of project 1, which is owned by Peter
+		_, err := s.AddCommentAttachment("2", "point.geojson", content, "Peter")
+		if err == nil {
+			return errors.New("Only the author should be able to add attachments")
+		}
+
+		p, err := s.AddCommentAttachment("2", "../../point.geojson", content, "Maria")
+		if err != nil {
+			return err
+		}
+		attachments := findComment(p, "2").Attachments
+		if len(attachments) != 1 || attachments[0].FileName != "point.geojson" || attachments[0].ContentType != comment.GeoJsonContentType || attachments[0].Size != len(content) {
+			return errors.New(fmt.Sprintf("Unexpected attachments of comment 2: %#v", attachments))
+		}
+
+		// Peter is member of project 1
+		attachment, storedContent, err := s.GetCommentAttachment(attachments[0].Id, "Peter")
+		if err != nil {
+			return err
+		}
+		if attachment.FileName != "point.geojson" || string(storedContent) != string(content) {
+			return errors.New(fmt.Sprintf("Unexpected attachment %#v with content '%s'", attachment, storedContent))
+		}
+
+		// John is not a member of project 1
+		_, _, err = s.GetCommentAttachment(attachments[0].Id, "John")
+		if err == nil {
+			return errors.New("Non-member should not be able to get attachment")
+		}
+
+		// Files must not be too large
+		_, err = s.AddCommentAttachment("2", "large.geojson", make([]byte, config.Conf.MaxAttachmentSize+1), "Maria")
+		if err == nil {
+			return errors.New("Too large attachment should not be accepted")
+		}
+
+		// Deleting the comment removes its attachments
+		_, err = s.DeleteComment("2", "Maria")
+		if err != nil {
+			return err
+		}
+		_, _, err = s.GetCommentAttachment(attachments[0].Id, "Peter")
+		if err == nil {
+			return errors.New("Attachment of deleted comment should not exist anymore")
+		}
+
+		return nil
+	})
+}
+
+func TestCommentModeration(t *testing.T) {
+	h.Run(t, func() error {
+		// Comment 3 is written by Maria on task 3 of project 2, which is owned by Maria
+		err := s.ReportComment("3", &comment.ReportDto{Reason: "Spam"}, "John")
+		if err != nil {
+			return err
+		}
+		err = s.ReportComment("3", &comment.ReportDto{Reason: "Advertisement"}, "John")
+		if err != nil {
+			return err
+		}
+
+		// Peter is not a member of project 2
+		err = s.ReportComment("3", &comment.ReportDto{Reason: "Spam"}, "Peter")
+		if err == nil {
+			return errors.New("Non-member should not be able to report comment")
+		}
+
+		_, err = s.GetCommentReports("2", "John")
+		if err == nil {
+			return errors.New("Non-owner should not be able to get reports")
+		}
+
+		reports, err := s.GetCommentReports("2", "Maria")
+		if err != nil {
+			return err
+		}
+		if len(reports) != 1 || reports[0].CommentId != "3" || reports[0].ReporterId != "John" || reports[0].Reason != "Advertisement" {
+			return errors.New(fmt.Sprintf("Unexpected reports: %#v", reports))
+		}
+
+		// Hidden comments have no content anymore but the report still contains it
+		_, err = s.SetCommentHidden("3", true, "John")
+		if err == nil {
+			return errors.New("Non-owner should not be able to hide comment")
+		}
+		p, err := s.SetCommentHidden("3", true, "Maria")
+		if err != nil {
+			return err
+		}
+		hiddenComment := findComment(p, "3")
+		if !hiddenComment.Hidden || hiddenComment.Text != "" || hiddenComment.Html != "" {
+			return errors.New(fmt.Sprintf("Comment 3 should be hidden: %#v", hiddenComment))
+		}
+		reports, err = s.GetCommentReports("2", "Maria")
+		if err != nil {
+			return err
+		}
+		if len(reports) != 1 || !reports[0].CommentHidden || reports[0].CommentText == "" {
+			return errors.New(fmt.Sprintf("Unexpected reports after hiding: %#v", reports))
+		}
+
+		err = s.DismissCommentReports("3", "Maria")
+		if err != nil {
+			return err
+		}
+		reports, err = s.GetCommentReports("2", "Maria")
+		if err != nil {
+			return err
+		}
+		if len(reports) != 0 {
+			return errors.New(fmt.Sprintf("Reports should have been dismissed: %#v", reports))
+		}
+
+		// The content of hidden comments is only available to the owner
+		johnsComment, err := s.AddComment("2", &comment.DraftDto{Text: "Buy cheap stuff"}, "John")
+		if err != nil {
+			return err
+		}
+		_, _, err = s.UpdateComment(johnsComment.Id, &comment.DraftDto{Text: "Buy even cheaper stuff"}, "John")
+		if err != nil {
+			return err
+		}
+		config.Conf.AttachmentDirectory = t.TempDir()
+		p, err = s.AddCommentAttachment(johnsComment.Id, "spam.geojson", []byte(`{"type": "Point", "coordinates": [0, 0]}`), "John")
+		if err != nil {
+			return err
+		}
+		attachmentId := findComment(p, johnsComment.Id).Attachments[0].Id
+
+		_, err = s.SetCommentHidden(johnsComment.Id, true, "Maria")
+		if err != nil {
+			return err
+		}
+
+		_, _, err = s.UpdateComment(johnsComment.Id, &comment.DraftDto{Text: "More spam"}, "John")
+		if err == nil {
+			return errors.New("Hidden comment should not be editable")
+		}
+		_, err = s.GetCommentHistory(johnsComment.Id, "Anna")
+		if err == nil {
+			return errors.New("History of hidden comment should not be visible to members")
+		}
+		_, _, err = s.GetCommentAttachment(attachmentId, "Anna")
+		if err == nil {
+			return errors.New("Attachment of hidden comment should not be visible to members")
+		}
+		edits, err := s.GetCommentHistory(johnsComment.Id, "Maria")
+		if err != nil {
+			return err
+		}
+		if len(edits) != 1 {
+			return errors.New(fmt.Sprintf("Owner should see history of hidden comment: %#v", edits))
+		}
+		_, _, err = s.GetCommentAttachment(attachmentId, "Maria")
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+func TestReadOnlyUsers(t *testing.T) {
+	h.Run(t, func() error {
+		johnsComment, err := s.AddComment("2", &comment.DraftDto{Text: "Nice project"}, "John")
+		if err != nil {
+			return err
+		}
+
+		_, err = s.AddReadOnlyUser("2", "John", "Anna")
+		if err == nil {
+			return errors.New("Non-owner should not be able to add read-only user")
+		}
+		_, err = s.AddReadOnlyUser("2", "Maria", "Maria")
+		if err == nil {
+			return errors.New("Owner should not be able to put themselves into read-only mode")
+		}
+		_, err = s.AddReadOnlyUser("2", "Peter", "Maria")
+		if err == nil {
+			return errors.New("Non-member should not be put into read-only mode")
+		}
+
+		p, err := s.AddReadOnlyUser("2", "John", "Maria")
+		if err != nil {
+			return err
+		}
+		if len(p.ReadOnlyUsers) != 1 || p.ReadOnlyUsers[0] != "John" {
+			return errors.New(fmt.Sprintf("John should be read-only user: %v", p.ReadOnlyUsers))
+		}
+
+		_, err = s.AddReadOnlyUser("2", "John", "Maria")
+		if err == nil {
+			return errors.New("User should not be put into read-only mode twice")
+		}
+
+		// John can neither comment on the project nor on its tasks
+		_, err = s.AddComment("2", &comment.DraftDto{Text: "Buy cheap stuff"}, "John")
+		if err == nil {
+			return errors.New("Read-only user should not be able to comment on project")
+		}
+		_, err = taskService.AddComment("3", &comment.DraftDto{Text: "Buy cheap stuff"}, "John")
+		if err == nil {
+			return errors.New("Read-only user should not be able to comment on task")
+		}
+
+		// Existing comments can't be changed either
+		_, _, err = s.UpdateComment(johnsComment.Id, &comment.DraftDto{Text: "Buy cheap stuff"}, "John")
+		if err == nil {
+			return errors.New("Read-only user should not be able to edit comment")
+		}
+		config.Conf.AttachmentDirectory = t.TempDir()
+		_, err = s.AddCommentAttachment(johnsComment.Id, "spam.geojson", []byte(`{"type": "Point", "coordinates": [0, 0]}`), "John")
+		if err == nil {
+			return errors.New("Read-only user should not be able to add attachment")
+		}
+
+		// Other members still can
+		_, err = s.AddComment("2", &comment.DraftDto{Text: "Looks good"}, "Anna")
+		if err != nil {
+			return err
+		}
+
+		p, err = s.RemoveReadOnlyUser("2", "John", "Maria")
+		if err != nil {
+			return err
+		}
+		if len(p.ReadOnlyUsers) != 0 {
+			return errors.New(fmt.Sprintf("There should be no read-only users: %v", p.ReadOnlyUsers))
+		}
+		_, err = s.AddComment("2", &comment.DraftDto{Text: "Sorry for the spam"}, "John")
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+func TestDeleteComment(t *testing.T) {
+	h.Run(t, func() error {
+		// Comments 1 (Peter) and 2 (Maria) are written on task 1 of project 1, which is owned by Peter
+		_, err := s.DeleteComment("1", "Maria")
+		if err == nil {
+			return errors.New("Non-author and non-owner should not be able to delete comment")
+		}
+
+		// The owner can delete every comment
+		p, err := s.DeleteComment("2", "Peter")
+		if err != nil {
+			return err
+		}
+		if p.Id != "1" || findComment(p, "2") != nil || findComment(p, "1") == nil {
+			return errors.New("Comment 2 should have been deleted")
+		}
+
+		// The author can delete own comments
+		_, _, err = s.UpdateComment("3", &comment.DraftDto{Text: "Done here"}, "Maria")
+		if err != nil {
+			return err
+		}
+		p, err = s.DeleteComment("3", "Maria")
+		if err != nil {
+			return err
+		}
+		if p.Id != "2" || findComment(p, "3") != nil {
+			return errors.New("Comment 3 should have been deleted")
+		}
+
+		_, err = s.GetCommentHistory("3", "Maria")
+		if err == nil {
+			return errors.New("History of deleted comment should not exist anymore")
+		}
+
+		return nil
+	})
+}
+
+func TestNeedsAssignment(t *testing.T) {
+	singleUser := []string{"Peter"}
+	twoUsers := []string{"Peter", "Maria"}
+
+	cases := []struct {
+		project  *Project
+		expected bool
+	}{
+		{&Project{Users: singleUser, AssignmentPolicy: AssignmentAboveMemberCount, AssignmentThreshold: 1}, false},
+		{&Project{Users: twoUsers, AssignmentPolicy: AssignmentAboveMemberCount, AssignmentThreshold: 1}, true},
+		{&Project{Users: twoUsers, AssignmentPolicy: AssignmentAboveMemberCount, AssignmentThreshold: 2}, false},
+		{&Project{Users: singleUser, AssignmentPolicy: AssignmentAlways}, true},
+		{&Project{Users: twoUsers, AssignmentPolicy: AssignmentNever}, false},
+	}
+
+	for i, c := range cases {
+		if needsAssignment(c.project) != c.expected {
+			t.Errorf("Case %d: expected needsAssignment to be %t", i, c.expected)
+		}
+	}
+}
+
+func TestAddScheduleFlags(t *testing.T) {
+	now := time.Date(2021, 3, 10, 0, 0, 0, 0, time.UTC)
+	startDate := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	dueDate := time.Date(2021, 3, 21, 0, 0, 0, 0, time.UTC)
+	pastDueDate := time.Date(2021, 3, 5, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name              string
+		project           *Project
+		expectedIsOverdue bool
+		expectedIsAtRisk  bool
+	}{
+		{"no due date", &Project{TotalProcessPoints: 10, DoneProcessPoints: 0}, false, false},
+		{"on track", &Project{StartDate: &startDate, DueDate: &dueDate, TotalProcessPoints: 10, DoneProcessPoints: 5}, false, false},
+		{"at risk", &Project{StartDate: &startDate, DueDate: &dueDate, TotalProcessPoints: 10, DoneProcessPoints: 4}, false, true},
+		{"at risk with creation date", &Project{CreationDate: &startDate, DueDate: &dueDate, TotalProcessPoints: 10, DoneProcessPoints: 1}, false, true},
+		{"overdue", &Project{StartDate: &startDate, DueDate: &pastDueDate, TotalProcessPoints: 10, DoneProcessPoints: 9}, true, false},
+		{"finished", &Project{StartDate: &startDate, DueDate: &pastDueDate, TotalProcessPoints: 10, DoneProcessPoints: 10}, false, false},
+		{"not started", &Project{StartDate: &dueDate, DueDate: &dueDate, TotalProcessPoints: 10, DoneProcessPoints: 0}, false, false},
+	}
+
+	for _, test := range tests {
+		addScheduleFlags(test.project, now)
+
+		if test.project.IsOverdue != test.expectedIsOverdue || test.project.IsAtRisk != test.expectedIsAtRisk {
+			t.Errorf("%s: Expected overdue=%t and at risk=%t but got overdue=%t and at risk=%t", test.name, test.expectedIsOverdue, test.expectedIsAtRisk, test.project.IsOverdue, test.project.IsAtRisk)
+		}
+	}
+}
+
+// BenchmarkGetProjects ensures that the number of queries needed to load projects doesn't grow with the number of
+// projects, tasks and comments (no N+1 queries).
+func BenchmarkGetProjects(b *testing.B) {
+	user := "Bob"
+	expectedQueryCount := -1
+
+	for _, n := range []int{1, 10, 50} {
+		b.Run(fmt.Sprintf("%d projects with %d tasks", n, n), func(b *testing.B) {
+			h.Run(b, func() error {
+				err := addBenchmarkProjects(user, n)
+				if err != nil {
+					return err
+				}
+
+				b.ResetTimer()
+
+				queryCounter := countStoreQueries()
+				defer queryCounter.stop()
+
+				queryCount := 0
+				for i := 0; i < b.N; i++ {
+					queryCountBefore := queryCounter.count
+
+					projects, err := s.GetProjects(user)
+					if err != nil {
+						return err
+					}
+					if len(projects) != n {
+						return errors.New(fmt.Sprintf("Expected %d projects but got %d", n, len(projects)))
+					}
+
+					queryCount = queryCounter.count - queryCountBefore
+				}
+
+				b.ReportMetric(float64(queryCount), "queries/op")
+
+				if expectedQueryCount == -1 {
+					expectedQueryCount = queryCount
+				} else if queryCount != expectedQueryCount {
+					return errors.New(fmt.Sprintf("Loading %d projects took %d queries but should take %d", n, queryCount, expectedQueryCount))
+				}
+
+				return nil
+			})
+		})
+	}
+}
+
+type storeQueryCounter struct {
+	count           int
+	formatFunctions func(*os.File, string, string, int, string, string)
+}
+
+// countStoreQueries counts the queries of all stores until stop() is called. Each store logs its queries via
+// LogQuery right before executing them, which writes a debug message with the store.go file as caller.
+func countStoreQueries() *storeQueryCounter {
+	counter := &storeQueryCounter{
+		formatFunctions: sigolo.FormatFunctions[sigolo.LOG_DEBUG],
+	}
+
+	sigolo.FormatFunctions[sigolo.LOG_DEBUG] = func(writer *os.File, time, level string, maxLength int, caller, message string) {
+		if strings.HasPrefix(caller, "store.go:") {
+			counter.count++
+		}
+		counter.formatFunctions(writer, time, level, maxLength, caller, message)
+	}
+
+	return counter
+}
+
+func (c *storeQueryCounter) stop() {
+	sigolo.FormatFunctions[sigolo.LOG_DEBUG] = c.formatFunctions
+}
+
+// addBenchmarkProjects adds the given number of projects, each with the given number of tasks and one comment on each
+// project and task.
+func addBenchmarkProjects(user string, count int) error {
+	taskDrafts := make([]task.DraftDto, count)
+	for i := range taskDrafts {
+		taskDrafts[i] = task.DraftDto{
+			MaxProcessPoints: 10,
+			Geometry:         "{\"type\":\"Feature\",\"geometry\":{\"type\":\"Polygon\",\"coordinates\":[[[0,0],[1,0]]]},\"properties\":null}",
+		}
+	}
+
+	for i := 0; i < count; i++ {
+		draft := &DraftDto{
+			Name:  fmt.Sprintf("Benchmark project %d", i),
+			Users: []string{user, "Alice"},
+			Owner: user,
+		}
+
+		p, err := s.AddProjectWithTasks(draft, taskDrafts)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.AddComment(p.Id, &comment.DraftDto{Text: "Some comment"}, user)
+		if err != nil {
+			return err
+		}
+
+		_, err = taskService.AddComment(p.Tasks[0].Id, &comment.DraftDto{Text: "Some comment"}, user)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func findComment(p *Project, commentId string) *comment.Comment {
+	c := findCommentInThreads(p.Comments, commentId)
+	if c != nil {
+		return c
+	}
+
+	for _, t := range p.Tasks {
+		c = findCommentInThreads(t.Comments, commentId)
+		if c != nil {
+			return c
+		}
+	}
+
+	return nil
+}
+
+func findCommentInThreads(comments []comment.Comment, commentId string) *comment.Comment {
+	for i, c := range comments {
+		if c.Id == commentId {
+			return &comments[i]
+		}
+
+		reply := findCommentInThreads(c.Replies, commentId)
+		if reply != nil {
+			return reply
+		}
+	}
+
+	return nil
+}
+
 func contains(projectIdToFind string, projectsToCheck []*Project) bool {
 	for _, p := range projectsToCheck {
 		if p.Id == projectIdToFind {
@@ -2,6 +2,7 @@ package project
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"github.com/lib/pq"
 	"github.com/pkg/errors"
@@ -9,20 +10,42 @@ import (
 	"stm/task"
 	"stm/util"
 	"strconv"
+	"strings"
 	"time"
 )
 
 // Helper struct to read raw data from database. The "Project" struct has higher-level structure (e.g. arrays), which we
 // don't have in the database columns.
 type projectRow struct {
-	id             int
-	name           string
-	users          []string
-	owner          string
-	description    string
-	creationDate   *time.Time
-	commentListId  string
-	josmDataSource JosmDataSource
+	id                    int
+	name                  string
+	users                 []string
+	owner                 string
+	description           string
+	creationDate          *time.Time
+	commentListId         string
+	josmDataSource        JosmDataSource
+	isPublic              bool
+	publicUsersVisible    bool
+	publicCommentsVisible bool
+	startDate             *time.Time
+	dueDate               *time.Time
+	assignmentPolicy      AssignmentPolicy
+	assignmentThreshold   int
+	maxAssignedTasks      int
+	overpassQuery         string
+	josmDownloadUrl       string
+	imagery               []byte
+	changesetComment      string
+	changesetSource       string
+	changesetHashtags     []string
+	readOnlyUsers         []string
+}
+
+// Change of process points of one task. The delta is negative when points have been removed.
+type processPointChange struct {
+	date  time.Time
+	delta int
 }
 
 type store struct {
@@ -33,6 +56,22 @@ type store struct {
 	commentStore *comment.Store
 }
 
+var (
+	summarySortExpressions = map[SummarySortField]string{
+		SortByCreationDate: "p.creation_date",
+		SortByName:         "LOWER(p.name)",
+		SortByProgress:     "COALESCE(SUM(t.process_points)::FLOAT / NULLIF(SUM(t.max_process_points), 0), 0)",
+	}
+
+	processPointChangeTable = "process_point_changes"
+	commentTable            = "comments"
+	projectMemberTable      = "project_members"
+	projectTeamTable        = "project_teams"
+	teamTable               = "teams"
+
+	returnValues = "id, name, owner, description, users, creation_date, comment_list_id, josm_data_source, is_public, public_users_visible, public_comments_visible, start_date, due_date, assignment_policy, assignment_member_threshold, max_assigned_tasks, overpass_query, josm_download_url, imagery, changeset_comment, changeset_source, changeset_hashtags, read_only_users"
+)
+
 func getStore(tx *sql.Tx, logger *util.Logger, taskStore *task.Store, commentStore *comment.Store) *store {
 	return &store{
 		Logger:       logger,
@@ -44,58 +83,201 @@ func getStore(tx *sql.Tx, logger *util.Logger, taskStore *task.Store, commentSto
 }
 
 func (s *store) getAllProjectsOfUser(userId string) ([]*Project, error) {
-	query := fmt.Sprintf("SELECT * FROM %s WHERE $1 = ANY(users)", s.table)
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id IN (SELECT project_id FROM %s WHERE user_id = $1)", returnValues, s.table, projectMemberTable)
+	return s.execQueryForProjects(query, userId)
+}
 
-	s.LogQuery(query, userId)
+// getProjectSummariesOfUser returns one page of summaries of the projects the user is a member of. All numbers are
+// aggregated by the database so that no tasks or comments have to be loaded.
+func (s *store) getProjectSummariesOfUser(userId string, filter *SummaryFilterDto) ([]*Summary, int, error) {
+	searchPattern := "%" + escapeLikePattern(filter.Search) + "%"
 
-	rows, err := s.tx.Query(query, userId)
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE id IN (SELECT project_id FROM %s WHERE user_id = $1) AND name ILIKE $2;", s.table, projectMemberTable)
+	s.LogQuery(countQuery, userId, searchPattern)
+
+	var totalCount int
+	err := s.tx.QueryRow(countQuery, userId, searchPattern).Scan(&totalCount)
 	if err != nil {
-		return nil, errors.Wrap(err, "error executing query")
+		return nil, 0, errors.Wrap(err, "error counting project summaries")
 	}
 
-	projects := make([]*Project, 0)
-	projectRows := make([]*projectRow, 0)
+	sortExpression, ok := summarySortExpressions[filter.SortBy]
+	if !ok {
+		return nil, 0, errors.New(fmt.Sprintf("unknown sort field '%s'", filter.SortBy))
+	}
+	sortDirection := "ASC"
+	if filter.Descending {
+		sortDirection = "DESC"
+	}
+
+	rawQueryString := `
+SELECT p.id, p.name, p.description, p.owner, p.creation_date, p.josm_data_source, (SELECT COUNT(*) FROM %s m WHERE m.project_id = p.id),
+	COUNT(t.id), COUNT(t.id) FILTER (WHERE t.process_points = t.max_process_points),
+	COALESCE(SUM(t.max_process_points), 0), COALESCE(SUM(t.process_points), 0)
+FROM %s p LEFT JOIN %s t ON t.project_id = p.id
+WHERE p.id IN (SELECT project_id FROM %s WHERE user_id = $1) AND p.name ILIKE $2
+GROUP BY p.id
+ORDER BY %s %s, p.id %s
+LIMIT $3 OFFSET $4;`
+
+	// The sort expression and direction come from fixed values above and never from the user, so no SQL injection possible
+	query := fmt.Sprintf(rawQueryString, projectMemberTable, s.table, s.taskStore.Table, projectMemberTable, sortExpression, sortDirection, sortDirection)
+	offset := (filter.Page - 1) * filter.PageSize
+	s.LogQuery(query, userId, searchPattern, filter.PageSize, offset)
+
+	rows, err := s.tx.Query(query, userId, searchPattern, filter.PageSize, offset)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "error executing query")
+	}
+	defer rows.Close()
+
+	summaries := make([]*Summary, 0)
 	for rows.Next() {
-		project, projectRow, err := s.rowToProject(rows)
+		var id int
+		var creationDate *time.Time
+		summary := &Summary{}
+
+		err = rows.Scan(&id, &summary.Name, &summary.Description, &summary.Owner, &creationDate, &summary.JosmDataSource, &summary.UserCount,
+			&summary.TaskCount, &summary.DoneTaskCount, &summary.TotalProcessPoints, &summary.DoneProcessPoints)
 		if err != nil {
-			return nil, errors.Wrap(err, "error converting row into project")
+			return nil, 0, errors.Wrap(err, "could not scan rows")
 		}
 
-		projects = append(projects, project)
-		projectRows = append(projectRows, projectRow)
+		summary.Id = strconv.Itoa(id)
+		if creationDate != nil {
+			t := creationDate.UTC()
+			summary.CreationDate = &t
+		}
+
+		summaries = append(summaries, summary)
 	}
 
-	err = rows.Close()
+	return summaries, totalCount, nil
+}
+
+// getMemberStatistics returns the contributions of each user who changed process points or wrote comments in the given
+// project as well as the dates of the first and last such activity. The process points only sum up the increases, so
+// resetting a task doesn't reduce the contribution. A task that has been finished several times by the same user (e.g.
+// after a reset) counts as one finished task.
+func (s *store) getMemberStatistics(projectId string) (map[string]*MemberStatistics, *time.Time, *time.Time, error) {
+	rawQueryString := `
+SELECT user_id, SUM(points_set), SUM(finished_tasks), SUM(comments), MIN(first_activity), MAX(last_activity)
+FROM (
+	SELECT c.user_id, SUM(GREATEST(c.new_points - c.old_points, 0)) AS points_set,
+		COUNT(DISTINCT c.task_id) FILTER (WHERE c.new_points = t.max_process_points AND c.old_points < t.max_process_points) AS finished_tasks,
+		0 AS comments, MIN(c.creation_date) AS first_activity, MAX(c.creation_date) AS last_activity
+	FROM %s c JOIN %s t ON t.id = c.task_id
+	WHERE t.project_id = $1
+	GROUP BY c.user_id
+UNION ALL
+	SELECT author_id, 0, 0, COUNT(*), MIN(creation_date), MAX(creation_date)
+	FROM %s
+	WHERE comment_list_id IN (SELECT comment_list_id FROM %s WHERE id = $1 UNION SELECT comment_list_id FROM %s WHERE project_id = $1)
+	GROUP BY author_id
+) AS activities
+GROUP BY user_id
+ORDER BY user_id;`
+
+	query := fmt.Sprintf(rawQueryString, processPointChangeTable, s.taskStore.Table, commentTable, s.table, s.taskStore.Table)
+	s.LogQuery(query, projectId)
+
+	rows, err := s.tx.Query(query, projectId)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, errors.Wrap(err, "error executing query")
 	}
+	defer rows.Close()
 
-	// Add task-IDs and comments to projects
-	for i, project := range projects {
-		err = s.addTasksToProject(project)
+	memberStatistics := make(map[string]*MemberStatistics)
+	var firstActivity, lastActivity *time.Time
+	for rows.Next() {
+		var first, last time.Time
+		statistics := &MemberStatistics{}
+
+		err = rows.Scan(&statistics.UserId, &statistics.ProcessPointsSet, &statistics.FinishedTaskCount, &statistics.CommentCount, &first, &last)
 		if err != nil {
-			return nil, err
+			return nil, nil, nil, errors.Wrap(err, "could not scan rows")
 		}
 
-		err = s.addCommentsToProject(project, projectRows[i])
+		memberStatistics[statistics.UserId] = statistics
+
+		first = first.UTC()
+		last = last.UTC()
+		if firstActivity == nil || first.Before(*firstActivity) {
+			firstActivity = &first
+		}
+		if lastActivity == nil || last.After(*lastActivity) {
+			lastActivity = &last
+		}
+	}
+
+	return memberStatistics, firstActivity, lastActivity, nil
+}
+
+// getProcessPointChanges returns all changes of process points in the given project after the given date, ordered by
+// their date.
+func (s *store) getProcessPointChanges(projectId string, after time.Time) ([]*processPointChange, error) {
+	query := fmt.Sprintf("SELECT c.creation_date, c.new_points - c.old_points FROM %s c JOIN %s t ON t.id = c.task_id WHERE t.project_id = $1 AND c.creation_date > $2 ORDER BY c.creation_date;", processPointChangeTable, s.taskStore.Table)
+	s.LogQuery(query, projectId, after)
+
+	rows, err := s.tx.Query(query, projectId, after)
+	if err != nil {
+		return nil, errors.Wrap(err, "error executing query")
+	}
+	defer rows.Close()
+
+	changes := make([]*processPointChange, 0)
+	for rows.Next() {
+		change := &processPointChange{}
+
+		err = rows.Scan(&change.date, &change.delta)
 		if err != nil {
-			return nil, err
+			return nil, errors.Wrap(err, "could not scan rows")
 		}
+
+		change.date = change.date.UTC()
+		changes = append(changes, change)
 	}
 
-	return projects, nil
+	return changes, nil
 }
 
 func (s *store) getProject(projectId string) (*Project, error) {
-	query := fmt.Sprintf("SELECT * FROM %s WHERE id=$1", s.table)
-	return s.execQuery(query, projectId)
+	return s.getProjectWithLatestComments(projectId, -1)
+}
+
+// getProjectWithLatestComments loads the project but only with the latest "maxComments" comment threads of the project
+// and of each task. A negative number loads all comments.
+func (s *store) getProjectWithLatestComments(projectId string, maxComments int) (*Project, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id=$1", returnValues, s.table)
+	return s.execQueryWithLatestComments(maxComments, query, projectId)
 }
 
 func (s *store) getProjectOfTask(taskId string) (*Project, error) {
-	query := fmt.Sprintf("SELECT p.* FROM %s p, %s t WHERE $1 = t.id AND t.project_id = p.id", s.table, s.taskStore.Table)
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id = (SELECT project_id FROM %s WHERE id = $1)", returnValues, s.table, s.taskStore.Table)
 	return s.execQuery(query, taskId)
 }
 
+// getProjectSettingsOfTask returns the project the task belongs to without loading its tasks, comments and teams.
+// This is enough to read settings like the changeset or JOSM configuration.
+func (s *store) getProjectSettingsOfTask(taskId string) (*Project, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id = (SELECT project_id FROM %s WHERE id = $1)", returnValues, s.table, s.taskStore.Table)
+
+	s.LogQuery(query, taskId)
+	project, _, err := s.execQueryWithoutTasks(query, taskId)
+	return project, err
+}
+
+// getProjectOfComment returns the project the comment belongs to. This is either the project itself or the project
+// of the task the comment was written on.
+func (s *store) getProjectOfComment(commentId string) (*Project, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE id = (
+	SELECT p.id FROM %s p, %s c WHERE c.id = $1 AND p.comment_list_id = c.comment_list_id
+	UNION
+	SELECT t.project_id FROM %s t, %s c WHERE c.id = $1 AND t.comment_list_id = c.comment_list_id
+)`, returnValues, s.table, s.table, commentTable, s.taskStore.Table, commentTable)
+	return s.execQuery(query, commentId)
+}
+
 // addProject adds the given project draft and assigns an ID to the project.
 func (s *store) addProject(draft *DraftDto, creationDate time.Time) (*Project, error) {
 	commentListId, err := s.commentStore.NewCommentList()
@@ -103,8 +285,8 @@ func (s *store) addProject(draft *DraftDto, creationDate time.Time) (*Project, e
 		return nil, err
 	}
 
-	query := fmt.Sprintf("INSERT INTO %s (name, description, users, owner, creation_date, comment_list_id, josm_data_source) VALUES($1, $2, $3, $4, $5, $6, $7) RETURNING *", s.table)
-	params := []interface{}{draft.Name, draft.Description, pq.Array(draft.Users), draft.Owner, creationDate, commentListId, draft.JosmDataSource}
+	query := fmt.Sprintf("INSERT INTO %s (name, description, users, owner, creation_date, comment_list_id, josm_data_source, changeset_comment, changeset_source, changeset_hashtags) VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING %s", s.table, returnValues)
+	params := []interface{}{draft.Name, draft.Description, pq.Array(draft.Users), draft.Owner, creationDate, commentListId, draft.JosmDataSource, draft.ChangesetComment, draft.ChangesetSource, pq.Array(draft.ChangesetHashtags)}
 
 	s.LogQuery(query, params...)
 	project, _, err := s.execQueryWithoutTasks(query, params...)
@@ -112,41 +294,104 @@ func (s *store) addProject(draft *DraftDto, creationDate time.Time) (*Project, e
 		return nil, err
 	}
 
-	// No need to fetch anything, a new project always has an empty comment list
+	// No need to fetch anything, a new project always has an empty comment list and no teams
 	project.Comments = []comment.Comment{}
+	project.Teams = []string{}
 
 	return project, nil
 }
 
+// addUser adds the user as direct member to the project. The loaded "Users" of a project also contain the members of
+// its teams, which therefore must not be written back into the "users" column.
 func (s *store) addUser(projectId string, userIdToAdd string) (*Project, error) {
-	originalProject, err := s.getProject(projectId)
+	query := fmt.Sprintf("UPDATE %s SET users=ARRAY_APPEND(users, $1) WHERE id=$2 RETURNING %s", s.table, returnValues)
+	return s.execQuery(query, userIdToAdd, projectId)
+}
+
+// removeUser removes the user from the direct members of the project. A member of a team of the project stays a member.
+func (s *store) removeUser(projectId string, userIdToRemove string) (*Project, error) {
+	query := fmt.Sprintf("UPDATE %s SET users=ARRAY_REMOVE(users, $1) WHERE id=$2 RETURNING %s", s.table, returnValues)
+	return s.execQuery(query, userIdToRemove, projectId)
+}
+
+func (s *store) addReadOnlyUser(projectId string, userId string) (*Project, error) {
+	query := fmt.Sprintf("UPDATE %s SET read_only_users=ARRAY_APPEND(read_only_users, $1) WHERE id=$2 RETURNING %s", s.table, returnValues)
+	return s.execQuery(query, userId, projectId)
+}
+
+func (s *store) removeReadOnlyUser(projectId string, userId string) (*Project, error) {
+	query := fmt.Sprintf("UPDATE %s SET read_only_users=ARRAY_REMOVE(read_only_users, $1) WHERE id=$2 RETURNING %s", s.table, returnValues)
+	return s.execQuery(query, userId, projectId)
+}
+
+// getReadOnlyUsers returns the members of the project, which are not allowed to write comments.
+func (s *store) getReadOnlyUsers(projectId string) ([]string, error) {
+	query := fmt.Sprintf("SELECT read_only_users FROM %s WHERE id = $1;", s.table)
+	s.LogQuery(query, projectId)
+
+	rows, err := s.tx.Query(query, projectId)
 	if err != nil {
-		s.Err("error getting project with ID '%s'", projectId)
-		return nil, err
+		return nil, errors.Wrapf(err, "error executing query to get read-only users of project %s", projectId)
 	}
+	defer rows.Close()
 
-	newUsers := append(originalProject.Users, userIdToAdd)
+	if !rows.Next() {
+		return nil, errors.New(fmt.Sprintf("project %s does not exist", projectId))
+	}
+
+	readOnlyUsers := make([]string, 0)
+	err = rows.Scan(pq.Array(&readOnlyUsers))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not scan row for read-only users")
+	}
 
-	query := fmt.Sprintf("UPDATE %s SET users=$1 WHERE id=$2 RETURNING *", s.table)
-	return s.execQuery(query, pq.Array(newUsers), projectId)
+	return readOnlyUsers, nil
 }
 
-func (s *store) removeUser(projectId string, userIdToRemove string) (*Project, error) {
-	originalProject, err := s.getProject(projectId)
+// isTeamMember returns true when the user is a member of at least one team of the project.
+func (s *store) isTeamMember(projectId string, userId string) (bool, error) {
+	query := fmt.Sprintf("SELECT pt.team_id FROM %s pt JOIN %s t ON t.id = pt.team_id WHERE pt.project_id = $1 AND $2 = ANY(t.users);", projectTeamTable, teamTable)
+	s.LogQuery(query, projectId, userId)
+
+	rows, err := s.tx.Query(query, projectId, userId)
 	if err != nil {
-		s.Err("error getting project with ID '%s'", projectId)
-		return nil, err
+		return false, errors.Wrapf(err, "error executing query to get teams of user %s in project %s", userId, projectId)
 	}
+	defer rows.Close()
 
-	remainingUsers := make([]string, 0)
-	for _, u := range originalProject.Users {
-		if u != userIdToRemove {
-			remainingUsers = append(remainingUsers, u)
-		}
+	return rows.Next(), nil
+}
+
+func (s *store) addTeam(projectId string, teamId string) (*Project, error) {
+	query := fmt.Sprintf("INSERT INTO %s (project_id, team_id) VALUES($1, $2);", projectTeamTable)
+
+	s.LogQuery(query, projectId, teamId)
+	_, err := s.tx.Exec(query, projectId, teamId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error adding team %s to project %s", teamId, projectId)
+	}
+
+	return s.getProject(projectId)
+}
+
+func (s *store) removeTeam(projectId string, teamId string) (*Project, error) {
+	query := fmt.Sprintf("DELETE FROM %s WHERE project_id=$1 AND team_id=$2;", projectTeamTable)
+
+	s.LogQuery(query, projectId, teamId)
+	result, err := s.tx.Exec(query, projectId, teamId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error removing team %s from project %s", teamId, projectId)
 	}
 
-	query := fmt.Sprintf("UPDATE %s SET users=$1 WHERE id=$2 RETURNING *", s.table)
-	return s.execQuery(query, pq.Array(remainingUsers), projectId)
+	removedRows, err := result.RowsAffected()
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading number of removed teams")
+	}
+	if removedRows == 0 {
+		return nil, errors.New(fmt.Sprintf("team %s is not part of project %s", teamId, projectId))
+	}
+
+	return s.getProject(projectId)
 }
 
 func (s *store) delete(projectId string) error {
@@ -157,10 +402,49 @@ func (s *store) delete(projectId string) error {
 }
 
 func (s *store) update(projectId string, newName string, newDescription string, newJosmDataSource JosmDataSource) (*Project, error) {
-	query := fmt.Sprintf("UPDATE %s SET name=$2, description=$3, josm_data_source=$4 WHERE id=$1 RETURNING *", s.table)
+	query := fmt.Sprintf("UPDATE %s SET name=$2, description=$3, josm_data_source=$4 WHERE id=$1 RETURNING %s", s.table, returnValues)
 	return s.execQuery(query, projectId, newName, newDescription, newJosmDataSource)
 }
 
+func (s *store) updateVisibility(projectId string, visibility *VisibilityDto) (*Project, error) {
+	query := fmt.Sprintf("UPDATE %s SET is_public=$2, public_users_visible=$3, public_comments_visible=$4 WHERE id=$1 RETURNING %s", s.table, returnValues)
+	return s.execQuery(query, projectId, visibility.IsPublic, visibility.PublicUsersVisible, visibility.PublicCommentsVisible)
+}
+
+func (s *store) updateChangesetSettings(projectId string, changeset *ChangesetDto) (*Project, error) {
+	query := fmt.Sprintf("UPDATE %s SET changeset_comment=$2, changeset_source=$3, changeset_hashtags=$4 WHERE id=$1 RETURNING %s", s.table, returnValues)
+	return s.execQuery(query, projectId, changeset.ChangesetComment, changeset.ChangesetSource, pq.Array(changeset.ChangesetHashtags))
+}
+
+func (s *store) updateJosmConfig(projectId string, josmConfig *JosmConfigDto) (*Project, error) {
+	imagery, err := json.Marshal(josmConfig.Imagery)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not serialize imagery layers")
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET josm_data_source=$2, overpass_query=$3, josm_download_url=$4, imagery=$5::JSONB WHERE id=$1 RETURNING %s", s.table, returnValues)
+	return s.execQuery(query, projectId, josmConfig.JosmDataSource, josmConfig.OverpassQuery, josmConfig.JosmDownloadUrl, string(imagery))
+}
+
+func (s *store) updateAssignmentPolicy(projectId string, assignment *AssignmentDto) (*Project, error) {
+	query := fmt.Sprintf("UPDATE %s SET assignment_policy=$2, assignment_member_threshold=$3, max_assigned_tasks=$4 WHERE id=$1 RETURNING %s", s.table, returnValues)
+	return s.execQuery(query, projectId, assignment.Policy, assignment.Threshold, assignment.MaxAssignedTasks)
+}
+
+// updateSchedule sets the start and due date. The notification about a passed due date is sent again for a changed due
+// date, so the flag for it is only reset when the due date actually changes.
+func (s *store) updateSchedule(projectId string, startDate *time.Time, dueDate *time.Time) (*Project, error) {
+	query := fmt.Sprintf("UPDATE %s SET start_date=$2, due_date=$3, due_date_notified=(due_date_notified AND due_date IS NOT DISTINCT FROM $3) WHERE id=$1 RETURNING %s", s.table, returnValues)
+	return s.execQuery(query, projectId, startDate, dueDate)
+}
+
+// markDueDatePassed marks all projects whose due date passed at the given date and returns them. Each project is
+// only returned once per due date.
+func (s *store) markDueDatePassed(now time.Time) ([]*Project, error) {
+	query := fmt.Sprintf("UPDATE %s SET due_date_notified=true WHERE due_date <= $1 AND NOT due_date_notified RETURNING %s", s.table, returnValues)
+	return s.execQueryForProjects(query, now)
+}
+
 func (s *store) getCommentListId(projectId string) (string, error) {
 	query := fmt.Sprintf("SELECT comment_list_id FROM %s WHERE id = $1;", s.table)
 	s.LogQuery(query, projectId)
@@ -184,6 +468,57 @@ func (s *store) getCommentListId(projectId string) (string, error) {
 	return commentListId, nil
 }
 
+// getCommentListIds returns the IDs of the comment list of the project itself and of the comment lists of all its
+// tasks.
+func (s *store) getCommentListIds(projectId string) ([]string, error) {
+	query := fmt.Sprintf("SELECT comment_list_id FROM %s WHERE id = $1 UNION SELECT comment_list_id FROM %s WHERE project_id = $1;", s.table, s.taskStore.Table)
+	s.LogQuery(query, projectId)
+
+	rows, err := s.tx.Query(query, projectId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error executing query to get comment list ids of project %s", projectId)
+	}
+	defer rows.Close()
+
+	commentListIds := make([]string, 0)
+	for rows.Next() {
+		commentListId := ""
+		err = rows.Scan(&commentListId)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not scan row for comment list id")
+		}
+
+		commentListIds = append(commentListIds, commentListId)
+	}
+
+	return commentListIds, nil
+}
+
+// getMembers returns the members of the project including the members of the teams of the project.
+func (s *store) getMembers(projectId string) ([]string, error) {
+	query := fmt.Sprintf("SELECT user_id FROM %s WHERE project_id = $1;", projectMemberTable)
+	s.LogQuery(query, projectId)
+
+	rows, err := s.tx.Query(query, projectId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error executing query to get members of project %s", projectId)
+	}
+	defer rows.Close()
+
+	members := make([]string, 0)
+	for rows.Next() {
+		member := ""
+		err = rows.Scan(&member)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not scan row for project member")
+		}
+
+		members = append(members, member)
+	}
+
+	return members, nil
+}
+
 func (s *store) execQueryWithoutTasks(query string, params ...interface{}) (*Project, *projectRow, error) {
 	rows, err := s.tx.Query(query, params...)
 	if err != nil {
@@ -209,30 +544,76 @@ func (s *store) execQueryWithoutTasks(query string, params ...interface{}) (*Pro
 
 // execQuery executed the given query, turns the result into a Project object and closes the query.
 func (s *store) execQuery(query string, params ...interface{}) (*Project, error) {
+	return s.execQueryWithLatestComments(-1, query, params...)
+}
+
+// execQueryWithLatestComments is like execQuery but only loads the latest "maxComments" comment threads of the project
+// and of each task.
+func (s *store) execQueryWithLatestComments(maxComments int, query string, params ...interface{}) (*Project, error) {
 	s.LogQuery(query, params...)
 
-	project, projectRow, err := s.execQueryWithoutTasks(query, params...)
+	project, row, err := s.execQueryWithoutTasks(query, params...)
 	if err != nil {
 		return nil, err
 	}
 
-	err = s.addTasksToProject(project)
+	err = s.addTasksAndCommentsToProjects([]*Project{project}, []*projectRow{row}, maxComments)
 	if err != nil {
 		return nil, err
 	}
 
-	err = s.addCommentsToProject(project, projectRow)
+	err = s.addTeamsToProjects([]*Project{project})
 	if err != nil {
 		return nil, err
 	}
 
-	return project, err
+	return project, nil
+}
+
+// execQueryForProjects executes the given query, turns all resulting rows into Project objects including their tasks
+// and comments and closes the query.
+func (s *store) execQueryForProjects(query string, params ...interface{}) ([]*Project, error) {
+	s.LogQuery(query, params...)
+
+	rows, err := s.tx.Query(query, params...)
+	if err != nil {
+		return nil, errors.Wrap(err, "error executing query")
+	}
+
+	projects := make([]*Project, 0)
+	projectRows := make([]*projectRow, 0)
+	for rows.Next() {
+		project, projectRow, err := s.rowToProject(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, "error converting row into project")
+		}
+
+		projects = append(projects, project)
+		projectRows = append(projectRows, projectRow)
+	}
+
+	err = rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.addTasksAndCommentsToProjects(projects, projectRows, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.addTeamsToProjects(projects)
+	if err != nil {
+		return nil, err
+	}
+
+	return projects, nil
 }
 
 // rowToProject turns the current row into a Project object. This does not close the row.
 func (s *store) rowToProject(rows *sql.Rows) (*Project, *projectRow, error) {
 	var row projectRow
-	err := rows.Scan(&row.id, &row.name, &row.owner, &row.description, pq.Array(&row.users), &row.creationDate, &row.commentListId, &row.josmDataSource)
+	err := rows.Scan(&row.id, &row.name, &row.owner, &row.description, pq.Array(&row.users), &row.creationDate, &row.commentListId, &row.josmDataSource, &row.isPublic, &row.publicUsersVisible, &row.publicCommentsVisible, &row.startDate, &row.dueDate, &row.assignmentPolicy, &row.assignmentThreshold, &row.maxAssignedTasks, &row.overpassQuery, &row.josmDownloadUrl, &row.imagery, &row.changesetComment, &row.changesetSource, pq.Array(&row.changesetHashtags), pq.Array(&row.readOnlyUsers))
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "could not scan rows")
 	}
@@ -242,9 +623,44 @@ func (s *store) rowToProject(rows *sql.Rows) (*Project, *projectRow, error) {
 	result.Id = strconv.Itoa(row.id)
 	result.Name = row.name
 	result.Users = row.users
+	// The members of the teams are added to "Users" later on
+	result.DirectUsers = append([]string{}, row.users...)
 	result.Owner = row.owner
 	result.Description = row.description
+	result.DescriptionHtml = util.RenderMarkdown(row.description)
 	result.JosmDataSource = row.josmDataSource
+	result.IsPublic = row.isPublic
+	result.PublicUsersVisible = row.publicUsersVisible
+	result.PublicCommentsVisible = row.publicCommentsVisible
+	result.AssignmentPolicy = row.assignmentPolicy
+	result.AssignmentThreshold = row.assignmentThreshold
+	result.MaxAssignedTasks = row.maxAssignedTasks
+	result.OverpassQuery = row.overpassQuery
+	result.JosmDownloadUrl = row.josmDownloadUrl
+	result.ChangesetComment = row.changesetComment
+	result.ChangesetSource = row.changesetSource
+	result.ChangesetHashtags = row.changesetHashtags
+	if result.ChangesetHashtags == nil {
+		result.ChangesetHashtags = []string{}
+	}
+	result.ReadOnlyUsers = row.readOnlyUsers
+	if result.ReadOnlyUsers == nil {
+		result.ReadOnlyUsers = []string{}
+	}
+
+	err = json.Unmarshal(row.imagery, &result.Imagery)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not parse imagery layers")
+	}
+
+	if row.startDate != nil {
+		t := row.startDate.UTC()
+		result.StartDate = &t
+	}
+	if row.dueDate != nil {
+		t := row.dueDate.UTC()
+		result.DueDate = &t
+	}
 
 	if row.creationDate != nil {
 		t := row.creationDate.UTC()
@@ -254,23 +670,110 @@ func (s *store) rowToProject(rows *sql.Rows) (*Project, *projectRow, error) {
 	return &result, &row, nil
 }
 
-func (s *store) addTasksToProject(project *Project) error {
-	tasks, err := s.taskStore.GetAllTasksOfProject(project.Id)
+// addTasksAndCommentsToProjects loads the tasks and comments of all given projects at once so that the number of
+// queries doesn't depend on the number of projects and tasks. The i-th row belongs to the i-th project. Only the latest
+// "maxComments" comment threads of each project and task are loaded, a negative number loads all comments. The open
+// issues are counted on all comments in both cases.
+func (s *store) addTasksAndCommentsToProjects(projects []*Project, projectRows []*projectRow, maxComments int) error {
+	projectIds := make([]string, len(projects))
+	for i, project := range projects {
+		projectIds[i] = project.Id
+	}
+
+	tasksOfProjects, err := s.taskStore.GetAllTasksOfProjects(projectIds, maxComments)
 	if err != nil {
 		return err
 	}
 
-	project.Tasks = tasks
-	s.Log("Added tasks to project %s", project.Id)
+	commentListIds := make([]string, len(projectRows))
+	for i, row := range projectRows {
+		commentListIds[i] = row.commentListId
+	}
+
+	commentsOfLists, err := s.commentStore.GetLatestThreadsOfLists(commentListIds, maxComments)
+	if err != nil {
+		return err
+	}
+
+	var openIssuesOfLists map[string]int
+	if maxComments >= 0 {
+		openIssuesOfLists, err = s.commentStore.CountOpenIssuesOfLists(commentListIds)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, project := range projects {
+		project.Tasks = tasksOfProjects[project.Id]
+		project.Comments = commentsOfLists[projectRows[i].commentListId]
+
+		if openIssuesOfLists != nil {
+			project.OpenIssueCount = openIssuesOfLists[projectRows[i].commentListId]
+		} else {
+			project.OpenIssueCount = comment.CountOpenIssues(project.Comments)
+		}
+		for _, t := range project.Tasks {
+			project.OpenIssueCount += t.OpenIssueCount
+		}
+	}
+	s.Log("Added tasks and comments to %d projects", len(projects))
 
 	return nil
 }
 
-func (s *store) addCommentsToProject(project *Project, projectRow *projectRow) error {
-	comments, err := s.commentStore.GetComments(projectRow.commentListId)
+// addTeamsToProjects sets the IDs of the teams of all given projects and adds the team members to the users of the
+// projects. Users being a direct member and a team member (or a member of several teams) only appear once.
+func (s *store) addTeamsToProjects(projects []*Project) error {
+	projectIds := make([]string, len(projects))
+	projectsById := make(map[string]*Project, len(projects))
+	for i, project := range projects {
+		projectIds[i] = project.Id
+		projectsById[project.Id] = project
+		project.Teams = make([]string, 0)
+	}
+
+	query := fmt.Sprintf("SELECT pt.project_id, pt.team_id, t.users FROM %s pt JOIN %s t ON t.id = pt.team_id WHERE pt.project_id = ANY($1) ORDER BY pt.team_id;", projectTeamTable, teamTable)
+	s.LogQuery(query, pq.Array(projectIds))
+
+	rows, err := s.tx.Query(query, pq.Array(projectIds))
 	if err != nil {
-		return err
+		return errors.Wrap(err, "error executing query to get teams of projects")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var projectId, teamId int
+		var teamUsers []string
+		err = rows.Scan(&projectId, &teamId, pq.Array(&teamUsers))
+		if err != nil {
+			return errors.Wrap(err, "could not scan row for teams of projects")
+		}
+
+		project := projectsById[strconv.Itoa(projectId)]
+		project.Teams = append(project.Teams, strconv.Itoa(teamId))
+		for _, teamUser := range teamUsers {
+			if !containsUser(project.Users, teamUser) {
+				project.Users = append(project.Users, teamUser)
+			}
+		}
 	}
-	project.Comments = comments
+
 	return nil
 }
+
+func containsUser(users []string, user string) bool {
+	for _, u := range users {
+		if u == user {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeLikePattern escapes all characters with a special meaning in LIKE patterns so that the given text is matched
+// literally.
+func escapeLikePattern(text string) string {
+	text = strings.ReplaceAll(text, "\\", "\\\\")
+	text = strings.ReplaceAll(text, "%", "\\%")
+	return strings.ReplaceAll(text, "_", "\\_")
+}
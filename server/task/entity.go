@@ -9,6 +9,7 @@ type Task struct {
 	MaxProcessPoints int    `json:"maxProcessPoints"` // The maximum amount of process points of this task. Is larger than zero.
 	Geometry         string `json:"geometry"`         // A GeoJson feature of the task wit a polygon or multipolygon geometry. Will never be NULL or empty.
 	// TODO Use "Id" as suffix?
-	AssignedUser string            `json:"assignedUser"` // The user-ID of the user who is currently assigned to this task. Will never be NULL but might be empty.
-	Comments     []comment.Comment `json:"comments"`
+	AssignedUser   string            `json:"assignedUser"` // The user-ID of the user who is currently assigned to this task. Will never be NULL but might be empty.
+	Comments       []comment.Comment `json:"comments"`
+	OpenIssueCount int               `json:"openIssueCount"` // Number of comments (incl. replies) of this task which are open issues.
 }
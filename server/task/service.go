@@ -9,6 +9,7 @@ import (
 	"stm/permission"
 	"stm/util"
 	"strings"
+	"time"
 )
 
 type Service struct {
@@ -36,6 +37,17 @@ func (s *Service) GetTask(taskId string) (*Task, error) {
 	return task, err
 }
 
+// GetTaskOfMember gets the task, when the requesting user is a member of the project the task is in. Only the latest
+// "maxComments" comment threads are loaded, a negative number loads all comments.
+func (s *Service) GetTaskOfMember(taskId string, requestingUserId string, maxComments int) (*Task, error) {
+	err := s.permissionStore.VerifyMembershipTask(taskId, requestingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.store.getTaskWithLatestComments(taskId, maxComments)
+}
+
 // Simply gets the tasks for the given project
 func (s *Service) GetTasks(projectId string) ([]*Task, error) {
 	tasks, err := s.store.GetAllTasksOfProject(projectId)
@@ -94,6 +106,11 @@ func (s *Service) AssignUser(taskId, userId string) (*Task, error) {
 		return nil, errors.New(fmt.Sprintf("task %s has already an assigned userId, cannot overwrite", task.Id))
 	}
 
+	err = s.permissionStore.VerifyAssignmentLimit(taskId, userId)
+	if err != nil {
+		return nil, err
+	}
+
 	task, err = s.store.assignUser(taskId, userId)
 	if err != nil {
 		return nil, err
@@ -148,12 +165,21 @@ func (s *Service) SetProcessPoints(taskId string, newPoints int, requestingUserI
 		return nil, errors.New("process points out of range")
 	}
 
+	oldPoints := task.ProcessPoints
+
 	task, err = s.store.setProcessPoints(taskId, newPoints)
 	if err != nil {
 		return nil, err
 	}
 	s.Log("Set process points of task %s to %d", taskId, newPoints)
 
+	if oldPoints != newPoints {
+		err = s.store.addProcessPointChange(taskId, requestingUserId, oldPoints, newPoints, time.Now().UTC())
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return task, nil
 }
 
@@ -176,13 +202,52 @@ func (s *Service) Delete(taskIds []string, requestingUserId string) error {
 	return nil
 }
 
-func (s *Service) AddComment(taskId string, draftDto *comment.DraftDto, authorId string) error {
+// GetComments returns one page of the comment threads of the task. Only members of the project are allowed to see the
+// comments.
+func (s *Service) GetComments(taskId string, filter *comment.PageFilterDto, requestingUserId string) (*comment.Page, error) {
+	err := s.permissionStore.VerifyMembershipTask(taskId, requestingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	commentListId, err := s.store.getCommentListId(taskId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.commentService.GetCommentPage(commentListId, filter)
+}
+
+// AddComment adds a new comment to the task. The comment may mention members of the project of the task, other
+// mentions are returned as warnings of the comment. Read-only users of the project are not allowed to write comments.
+func (s *Service) AddComment(taskId string, draftDto *comment.DraftDto, authorId string) (*comment.Comment, error) {
+	commentListId, err := s.store.getCommentListId(taskId)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.store.getProjectMembers(taskId)
+	if err != nil {
+		return nil, err
+	}
+
+	readOnlyUsers, err := s.store.getProjectReadOnlyUsers(taskId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.commentService.AddComment(commentListId, draftDto, authorId, members, readOnlyUsers)
+}
+
+// ImportComments adds the given comment threads with their original authors and dates to the task. This is meant to
+// restore an exported project and doesn't check any permissions.
+func (s *Service) ImportComments(taskId string, threads []comment.Comment) error {
 	commentListId, err := s.store.getCommentListId(taskId)
 	if err != nil {
 		return err
 	}
 
-	return s.commentService.AddComment(commentListId, draftDto, authorId)
+	return s.commentService.ImportThreads(commentListId, threads)
 }
 
 func toTaskIds(tasks []*Task) []string {
@@ -83,6 +83,43 @@ func TestGetTasksOfUnknownProject(t *testing.T) {
 	})
 }
 
+func TestGetTaskOfMember(t *testing.T) {
+	h.Run(t, func() error {
+		task, err := s.GetTaskOfMember("1", "Maria", -1)
+		if err != nil {
+			return err
+		}
+		if task.Id != "1" || len(task.Comments) != 2 {
+			return errors.New(fmt.Sprintf("Task 1 with its comments expected but got %#v", task))
+		}
+
+		// Only the latest thread
+		task, err = s.GetTaskOfMember("1", "Maria", 1)
+		if err != nil {
+			return err
+		}
+		if len(task.Comments) != 1 || task.Comments[0].Id != "2" {
+			return errors.New(fmt.Sprintf("Only comment 2 expected but got %#v", task.Comments))
+		}
+
+		task, err = s.GetTaskOfMember("1", "Maria", 0)
+		if err != nil {
+			return err
+		}
+		if len(task.Comments) != 0 {
+			return errors.New(fmt.Sprintf("No comments expected but got %#v", task.Comments))
+		}
+
+		// John is not a member of project 1
+		_, err = s.GetTaskOfMember("1", "John", -1)
+		if err == nil {
+			return errors.New("Non-member should not be able to get the task")
+		}
+
+		return nil
+	})
+}
+
 func TestAddTasks(t *testing.T) {
 	h.Run(t, func() error {
 		rawTask := DraftDto{
@@ -113,6 +150,38 @@ func TestAddTasks(t *testing.T) {
 	})
 }
 
+func TestAddTasksWithSeparateCommentLists(t *testing.T) {
+	h.Run(t, func() error {
+		rawTask := DraftDto{
+			MaxProcessPoints: 10,
+			Geometry:         "{\"type\":\"Feature\",\"geometry\":{\"type\":\"Polygon\",\"coordinates\":[[[0,0],[1,0]]]},\"properties\":null}",
+		}
+
+		addedTasks, err := s.AddTasks([]DraftDto{rawTask, rawTask}, "1")
+		if err != nil {
+			return err
+		}
+		if len(addedTasks) != 3 {
+			return errors.New(fmt.Sprintf("Expected 3 tasks but got %d", len(addedTasks)))
+		}
+
+		_, err = s.AddComment(addedTasks[1].Id, &comment.DraftDto{Text: "Some comment"}, "Peter")
+		if err != nil {
+			return err
+		}
+
+		task, err := s.GetTask(addedTasks[2].Id)
+		if err != nil {
+			return err
+		}
+		if len(task.Comments) != 0 {
+			return errors.New(fmt.Sprintf("Comment of one new task should not appear on the other one: %+v", task.Comments))
+		}
+
+		return nil
+	})
+}
+
 func TestAddTasksInvalidProcessPoints(t *testing.T) {
 	h.Run(t, func() error {
 		// Max points = 0 is not allowed
@@ -225,6 +294,33 @@ func TestAssignUserTwice(t *testing.T) {
 	})
 }
 
+func TestAssignUserAboveLimit(t *testing.T) {
+	h.Run(t, func() error {
+		_, err := tx.Exec("UPDATE projects SET max_assigned_tasks = 1 WHERE id = 2;")
+		if err != nil {
+			return err
+		}
+
+		// Maria is already assigned to task 3
+		_, err = s.AssignUser("4", "Maria")
+		if err == nil {
+			return errors.New("Should not be able to assign user above the limit")
+		}
+
+		_, err = s.AssignUser("4", "John")
+		if err != nil {
+			return errors.New(fmt.Sprintf("Error: %s\n", err.Error()))
+		}
+
+		_, err = s.AssignUser("6", "John")
+		if err == nil {
+			return errors.New("Should not be able to assign user to a second task")
+		}
+
+		return nil
+	})
+}
+
 func TestUnassignUser(t *testing.T) {
 	h.Run(t, func() error {
 		s.AssignUser("2", "assigned-user")
@@ -324,3 +420,38 @@ func TestDelete(t *testing.T) {
 		return nil
 	})
 }
+
+func BenchmarkAddTasks(b *testing.B) {
+	rawTask := DraftDto{
+		MaxProcessPoints: 10,
+		Geometry:         "{\"type\":\"Feature\",\"geometry\":{\"type\":\"Polygon\",\"coordinates\":[[[0,0],[1,0]]]},\"properties\":null}",
+	}
+
+	for _, n := range []int{1000, 10000, 50000} {
+		drafts := make([]DraftDto, n)
+		for i := range drafts {
+			drafts[i] = rawTask
+		}
+
+		b.Run(fmt.Sprintf("%d tasks", n), func(b *testing.B) {
+			h.Run(b, func() error {
+				b.ResetTimer()
+
+				for i := 0; i < b.N; i++ {
+					addedTasks, err := s.AddTasks(drafts, "3")
+					if err != nil {
+						return err
+					}
+
+					// Project 3 already has two tasks
+					expectedTaskCount := 2 + (i+1)*n
+					if len(addedTasks) != expectedTaskCount {
+						return errors.New(fmt.Sprintf("Expected %d tasks but got %d", expectedTaskCount, len(addedTasks)))
+					}
+				}
+
+				return nil
+			})
+		})
+	}
+}
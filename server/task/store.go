@@ -9,10 +9,12 @@ import (
 	"stm/comment"
 	"stm/util"
 	"strconv"
+	"time"
 )
 
 type taskRow struct {
 	id               int
+	projectId        int
 	processPoints    int
 	maxProcessPoints int
 	geometry         string
@@ -22,31 +24,62 @@ type taskRow struct {
 
 type Store struct {
 	*util.Logger
-	tx           *sql.Tx
-	Table        string
-	commentStore *comment.Store
+	tx                      *sql.Tx
+	Table                   string
+	processPointChangeTable string
+	commentStore            *comment.Store
 }
 
 var (
-	returnValues = "id, process_points, max_process_points, geometry, assigned_user, comment_list_id"
+	projectMemberTable = "project_members"
+	projectTable       = "projects"
+
+	returnValues = "id, project_id, process_points, max_process_points, geometry, assigned_user, comment_list_id"
 )
 
 func GetStore(tx *sql.Tx, logger *util.Logger, commentStore *comment.Store) *Store {
 	return &Store{
-		Logger:       logger,
-		tx:           tx,
-		Table:        "tasks",
-		commentStore: commentStore,
+		Logger:                  logger,
+		tx:                      tx,
+		Table:                   "tasks",
+		processPointChangeTable: "process_point_changes",
+		commentStore:            commentStore,
 	}
 }
 
 func (s *Store) GetAllTasksOfProject(projectId string) ([]*Task, error) {
-	query := fmt.Sprintf("SELECT id,process_points,max_process_points,geometry,assigned_user,comment_list_id FROM %s WHERE project_id = $1;", s.Table)
-	s.LogQuery(query, projectId)
+	tasksOfProjects, err := s.GetAllTasksOfProjects([]string{projectId}, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := tasksOfProjects[projectId]
+	if len(tasks) == 0 {
+		return nil, errors.New("Tasks do not exist")
+	}
+
+	return tasks, nil
+}
 
-	rows, err := s.tx.Query(query, projectId)
+// GetAllTasksOfProjects loads the tasks of all given projects including their comments. Regardless of the number of
+// projects and tasks, this only needs one query for the tasks and one for the comments. The comments of each task can
+// be limited to the latest "maxComments" threads, a negative number loads all comments.
+func (s *Store) GetAllTasksOfProjects(projectIds []string, maxComments int) (map[string][]*Task, error) {
+	tasksOfProjects := make(map[string][]*Task)
+	for _, projectId := range projectIds {
+		tasksOfProjects[projectId] = make([]*Task, 0)
+	}
+
+	if len(projectIds) == 0 {
+		return tasksOfProjects, nil
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE project_id = ANY($1) ORDER BY id;", returnValues, s.Table)
+	s.LogQuery(query, projectIds)
+
+	rows, err := s.tx.Query(query, pq.Array(projectIds))
 	if err != nil {
-		return nil, errors.Wrapf(err, "error executing query to get tasks for project %s", projectId)
+		return nil, errors.Wrapf(err, "error executing query to get tasks for projects %v", projectIds)
 	}
 
 	// Read all tasks from the returned rows of the query
@@ -67,26 +100,28 @@ func (s *Store) GetAllTasksOfProject(projectId string) ([]*Task, error) {
 		return nil, errors.Wrap(err, "error closing rows")
 	}
 
-	if len(tasks) == 0 {
-		return nil, errors.New("Tasks do not exist")
+	err = s.addCommentsToTasks(tasks, taskRows, maxComments)
+	if err != nil {
+		return nil, err
 	}
 
 	for i, task := range tasks {
-		comments, err := s.commentStore.GetComments(taskRows[i].commentListId)
-		if err != nil {
-			return nil, err
-		}
-		task.Comments = comments
+		projectId := strconv.Itoa(taskRows[i].projectId)
+		tasksOfProjects[projectId] = append(tasksOfProjects[projectId], task)
 	}
 
-	return tasks, nil
+	return tasksOfProjects, nil
 }
 
 func (s *Store) getTask(taskId string) (*Task, error) {
-	query := fmt.Sprintf("SELECT id,process_points,max_process_points,geometry,assigned_user,comment_list_id FROM %s WHERE id = $1;", s.Table)
-	s.LogQuery(query, taskId)
+	return s.getTaskWithLatestComments(taskId, -1)
+}
 
-	task, err := s.execQuery(query, taskId)
+// getTaskWithLatestComments loads the task with the latest "maxComments" comment threads, a negative number loads all
+// comments.
+func (s *Store) getTaskWithLatestComments(taskId string, maxComments int) (*Task, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id = $1;", returnValues, s.Table)
+	task, err := s.execQueryWithLatestComments(maxComments, query, taskId)
 
 	if err != nil {
 		return nil, err
@@ -95,37 +130,36 @@ func (s *Store) getTask(taskId string) (*Task, error) {
 	return task, nil
 }
 
+// addTasks inserts all given tasks with one single query, each task gets its own new comment list. The returned list
+// contains all tasks of the project, not only the new ones.
 func (s *Store) addTasks(newTasks []DraftDto, projectId string) ([]*Task, error) {
-	taskIds := make([]string, 0)
-
-	commentListId, err := s.commentStore.NewCommentList()
+	commentListIds, err := s.commentStore.NewCommentLists(len(newTasks))
 	if err != nil {
 		return nil, err
 	}
 
-	// TODO Do not add one by one but instead build one large query (otherwise it's really slow)
-	for _, t := range newTasks {
-		id, err := s.addTask(&t, projectId, commentListId)
-		if err != nil {
-			s.Err("error adding task: %s", err.Error())
-			return nil, err
-		}
-
-		taskIds = append(taskIds, id)
+	processPoints := make([]int, len(newTasks))
+	maxProcessPoints := make([]int, len(newTasks))
+	geometries := make([]string, len(newTasks))
+	for i, t := range newTasks {
+		processPoints[i] = t.ProcessPoints
+		maxProcessPoints[i] = t.MaxProcessPoints
+		geometries[i] = t.Geometry
 	}
 
-	return s.GetAllTasksOfProject(projectId)
-}
-
-func (s *Store) addTask(task *DraftDto, projectId string, commentListId string) (string, error) {
-	query := fmt.Sprintf("INSERT INTO %s(process_points, max_process_points, geometry, assigned_user, project_id, comment_list_id) VALUES($1, $2, $3, $4, $5, $6) RETURNING %s;", s.Table, returnValues)
-	t, err := s.execQuery(query, task.ProcessPoints, task.MaxProcessPoints, task.Geometry, "", projectId, commentListId)
+	// Passing one array per column keeps the number of query parameters constant, no matter how many tasks are added.
+	query := fmt.Sprintf(`INSERT INTO %s(process_points, max_process_points, geometry, assigned_user, project_id, comment_list_id)
+SELECT process_points, max_process_points, geometry, '', $4::INT, comment_list_id
+FROM UNNEST($1::INT[], $2::INT[], $3::TEXT[], $5::INT[]) AS t(process_points, max_process_points, geometry, comment_list_id);`, s.Table)
+	s.LogQuery(query, processPoints, maxProcessPoints, fmt.Sprintf("<%d geometries>", len(geometries)), projectId, commentListIds)
 
+	_, err = s.tx.Exec(query, pq.Array(processPoints), pq.Array(maxProcessPoints), pq.Array(geometries), projectId, pq.Array(commentListIds))
 	if err != nil {
-		return "", err
+		s.Err("error adding tasks: %s", err.Error())
+		return nil, errors.Wrap(err, "could not insert tasks")
 	}
 
-	return t.Id, nil
+	return s.GetAllTasksOfProject(projectId)
 }
 
 func (s *Store) assignUser(taskId, userId string) (*Task, error) {
@@ -143,6 +177,20 @@ func (s *Store) setProcessPoints(taskId string, newPoints int) (*Task, error) {
 	return s.execQuery(query, newPoints, taskId)
 }
 
+// addProcessPointChange records who changed the process points of a task. These records are the basis for the
+// contribution statistics of projects.
+func (s *Store) addProcessPointChange(taskId string, userId string, oldPoints int, newPoints int, creationDate time.Time) error {
+	query := fmt.Sprintf("INSERT INTO %s (task_id, user_id, old_points, new_points, creation_date) VALUES($1, $2, $3, $4, $5);", s.processPointChangeTable)
+	s.LogQuery(query, taskId, userId, oldPoints, newPoints, creationDate)
+
+	_, err := s.tx.Exec(query, taskId, userId, oldPoints, newPoints, creationDate)
+	if err != nil {
+		return errors.Wrapf(err, "could not record process point change of task %s", taskId)
+	}
+
+	return nil
+}
+
 func (s *Store) delete(taskIds []string) error {
 	query := fmt.Sprintf("DELETE FROM %s WHERE id=ANY($1)", s.Table)
 
@@ -178,8 +226,64 @@ func (s *Store) getCommentListId(taskId string) (string, error) {
 	return commentListId, nil
 }
 
+// getProjectMembers returns the members of the project the task belongs to. This includes the members of the teams of
+// the project.
+func (s *Store) getProjectMembers(taskId string) ([]string, error) {
+	query := fmt.Sprintf("SELECT user_id FROM %s WHERE project_id = (SELECT project_id FROM %s WHERE id = $1);", projectMemberTable, s.Table)
+	s.LogQuery(query, taskId)
+
+	rows, err := s.tx.Query(query, taskId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error executing query to get project members of task %s", taskId)
+	}
+	defer rows.Close()
+
+	members := make([]string, 0)
+	for rows.Next() {
+		member := ""
+		err = rows.Scan(&member)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not scan row for project member")
+		}
+
+		members = append(members, member)
+	}
+
+	return members, nil
+}
+
+// getProjectReadOnlyUsers returns the members of the project the task belongs to, which are not allowed to write
+// comments.
+func (s *Store) getProjectReadOnlyUsers(taskId string) ([]string, error) {
+	query := fmt.Sprintf("SELECT read_only_users FROM %s WHERE id = (SELECT project_id FROM %s WHERE id = $1);", projectTable, s.Table)
+	s.LogQuery(query, taskId)
+
+	rows, err := s.tx.Query(query, taskId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error executing query to get read-only users of project of task %s", taskId)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, errors.New(fmt.Sprintf("project of task %s does not exist", taskId))
+	}
+
+	readOnlyUsers := make([]string, 0)
+	err = rows.Scan(pq.Array(&readOnlyUsers))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not scan row for read-only users")
+	}
+
+	return readOnlyUsers, nil
+}
+
 // execQuery executed the given query, turns the result into a Task object and closes the query.
 func (s *Store) execQuery(query string, params ...interface{}) (*Task, error) {
+	return s.execQueryWithLatestComments(-1, query, params...)
+}
+
+// execQueryWithLatestComments is like execQuery but only loads the latest "maxComments" comment threads of the task.
+func (s *Store) execQueryWithLatestComments(maxComments int, query string, params ...interface{}) (*Task, error) {
 	s.LogQuery(query, params...)
 	rows, err := s.tx.Query(query, params...)
 	if err != nil {
@@ -187,7 +291,7 @@ func (s *Store) execQuery(query string, params ...interface{}) (*Task, error) {
 	}
 
 	rows.Next()
-	task, taskRow, err := s.rowToTask(rows)
+	task, row, err := s.rowToTask(rows)
 
 	err = rows.Close()
 	if err != nil {
@@ -198,19 +302,52 @@ func (s *Store) execQuery(query string, params ...interface{}) (*Task, error) {
 		return nil, errors.New(fmt.Sprintf("Task does not exist"))
 	}
 
-	comments, err := s.commentStore.GetComments(taskRow.commentListId)
+	err = s.addCommentsToTasks([]*Task{task}, []*taskRow{row}, maxComments)
 	if err != nil {
 		return nil, err
 	}
-	task.Comments = comments
 
-	return task, err
+	return task, nil
+}
+
+// addCommentsToTasks loads the comments of all given tasks with one query. The i-th row belongs to the i-th task. When
+// only the latest "maxComments" threads are loaded, the open issues are counted with a second query because they might
+// be in older threads.
+func (s *Store) addCommentsToTasks(tasks []*Task, taskRows []*taskRow, maxComments int) error {
+	commentListIds := make([]string, len(taskRows))
+	for i, row := range taskRows {
+		commentListIds[i] = row.commentListId
+	}
+
+	commentsOfLists, err := s.commentStore.GetLatestThreadsOfLists(commentListIds, maxComments)
+	if err != nil {
+		return err
+	}
+
+	var openIssuesOfLists map[string]int
+	if maxComments >= 0 {
+		openIssuesOfLists, err = s.commentStore.CountOpenIssuesOfLists(commentListIds)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, task := range tasks {
+		task.Comments = commentsOfLists[taskRows[i].commentListId]
+		if openIssuesOfLists != nil {
+			task.OpenIssueCount = openIssuesOfLists[taskRows[i].commentListId]
+		} else {
+			task.OpenIssueCount = comment.CountOpenIssues(task.Comments)
+		}
+	}
+
+	return nil
 }
 
 // rowToTask turns the current row into a Task object. This does not close the row.
 func (s *Store) rowToTask(rows *sql.Rows) (*Task, *taskRow, error) {
 	var task taskRow
-	err := rows.Scan(&task.id, &task.processPoints, &task.maxProcessPoints, &task.geometry, &task.assignedUser, &task.commentListId)
+	err := rows.Scan(&task.id, &task.projectId, &task.processPoints, &task.maxProcessPoints, &task.geometry, &task.assignedUser, &task.commentListId)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "could not scan rows")
 	}
@@ -0,0 +1,6 @@
+package team
+
+type DraftDto struct {
+	Name  string   `json:"name"`  // Name of the team. Must not be NULL or empty.
+	Users []string `json:"users"` // List of user-IDs. The requesting user is always added as member and admin.
+}
@@ -0,0 +1,14 @@
+package team
+
+import "time"
+
+// Team is a named group of users which can be added to projects as a unit. All members of a team are members of the
+// projects the team has been added to.
+type Team struct {
+	Id           string     `json:"id"`           // The ID of the team.
+	Name         string     `json:"name"`         // The name of the team. Will not be NULL or empty.
+	Admins       []string   `json:"admins"`       // Array of user-IDs who are allowed to manage the team. Will not be NULL or empty.
+	Users        []string   `json:"users"`        // Array of user-IDs (=members of this team incl. the admins). Will not be NULL or empty.
+	CreationDate *time.Time `json:"creationDate"` // UTC Date in RFC 3339 format.
+	ProjectIds   []string   `json:"projectIds"`   // IDs of the projects this team has been added to. Will not be NULL but might be empty.
+}
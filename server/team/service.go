@@ -0,0 +1,230 @@
+package team
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/pkg/errors"
+	"stm/permission"
+	"stm/project"
+	"stm/util"
+	"strings"
+	"time"
+)
+
+type Service struct {
+	*util.Logger
+	store           *store
+	permissionStore *permission.Store
+	projectService  *project.Service
+}
+
+func Init(tx *sql.Tx, logger *util.Logger, permissionStore *permission.Store, projectService *project.Service) *Service {
+	return &Service{
+		Logger:          logger,
+		store:           getStore(tx, logger),
+		permissionStore: permissionStore,
+		projectService:  projectService,
+	}
+}
+
+// GetTeams returns all teams the given user is a member of.
+func (s *Service) GetTeams(userId string) ([]*Team, error) {
+	return s.store.getTeamsOfUser(userId)
+}
+
+func (s *Service) GetTeam(teamId string, potentialMemberId string) (*Team, error) {
+	err := s.permissionStore.VerifyMembershipTeam(teamId, potentialMemberId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.store.getTeam(teamId)
+}
+
+// AddTeam creates a new team with the requesting user as its first admin.
+func (s *Service) AddTeam(draft *DraftDto, requestingUserId string) (*Team, error) {
+	if strings.TrimSpace(draft.Name) == "" {
+		return nil, errors.New("Name must be set")
+	}
+
+	users := []string{requestingUserId}
+	for _, u := range draft.Users {
+		if strings.TrimSpace(u) != "" && !contains(users, u) {
+			users = append(users, u)
+		}
+	}
+
+	team, err := s.store.addTeam(draft.Name, []string{requestingUserId}, users, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	s.Log("Added team %s", team.Id)
+
+	return team, nil
+}
+
+// DeleteTeam removes the team from all its projects and deletes it. Former members of these projects get unassigned
+// from their tasks. The updated projects are returned. The requesting user must be an admin of the team.
+func (s *Service) DeleteTeam(teamId string, requestingUserId string) ([]*project.Project, error) {
+	err := s.permissionStore.VerifyTeamAdmin(teamId, requestingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	team, err := s.store.getTeam(teamId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.store.delete(teamId)
+	if err != nil {
+		return nil, err
+	}
+	s.Log("Deleted team %s", teamId)
+
+	updatedProjects := make([]*project.Project, 0)
+	for _, projectId := range team.ProjectIds {
+		updatedProject, err := s.projectService.UnassignFormerMembers(projectId)
+		if err != nil {
+			return nil, err
+		}
+		updatedProjects = append(updatedProjects, updatedProject)
+	}
+
+	return updatedProjects, nil
+}
+
+// AddUser adds the user to the members of the team and therefore to all projects of the team. The requesting user must
+// be an admin of the team. The projects of the team, which now contain the new member, are returned as well.
+func (s *Service) AddUser(teamId string, userId string, requestingUserId string) (*Team, []*project.Project, error) {
+	err := s.permissionStore.VerifyTeamAdmin(teamId, requestingUserId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	team, err := s.store.getTeam(teamId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if contains(team.Users, userId) {
+		return nil, nil, errors.New(fmt.Sprintf("user %s is already a member of team %s", userId, teamId))
+	}
+
+	team, err = s.store.updateMembers(teamId, team.Admins, append(team.Users, userId))
+	if err != nil {
+		return nil, nil, err
+	}
+	s.Log("Added user %s to team %s", userId, teamId)
+
+	updatedProjects := make([]*project.Project, 0)
+	for _, projectId := range team.ProjectIds {
+		updatedProject, err := s.projectService.GetProject(projectId, userId)
+		if err != nil {
+			return nil, nil, err
+		}
+		updatedProjects = append(updatedProjects, updatedProject)
+	}
+
+	return team, updatedProjects, nil
+}
+
+// RemoveUser removes the user from the team. Admins can remove every member and every member can leave the team, but
+// the last admin can't be removed. The user gets unassigned from all tasks of the projects of the team he/she isn't a
+// member of anymore. These projects are returned as well.
+func (s *Service) RemoveUser(teamId string, userId string, requestingUserId string) (*Team, []*project.Project, error) {
+	if userId != requestingUserId {
+		err := s.permissionStore.VerifyTeamAdmin(teamId, requestingUserId)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	err := s.permissionStore.VerifyMembershipTeam(teamId, userId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	team, err := s.store.getTeam(teamId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	remainingAdmins := remove(team.Admins, userId)
+	if len(remainingAdmins) == 0 {
+		return nil, nil, errors.New(fmt.Sprintf("the last admin cannot be removed from team %s", teamId))
+	}
+
+	team, err = s.store.updateMembers(teamId, remainingAdmins, remove(team.Users, userId))
+	if err != nil {
+		return nil, nil, err
+	}
+	s.Log("Removed user %s from team %s", userId, teamId)
+
+	// Like when removing a user from a project: The user must not stay assigned to tasks of projects he/she left
+	leftProjects := make([]*project.Project, 0)
+	for _, projectId := range team.ProjectIds {
+		err = s.permissionStore.VerifyMembershipProject(projectId, userId)
+		if err == nil {
+			// Still a member, e.g. as direct member or through a different team
+			continue
+		}
+
+		updatedProject, err := s.projectService.UnassignFormerMembers(projectId)
+		if err != nil {
+			return nil, nil, err
+		}
+		leftProjects = append(leftProjects, updatedProject)
+	}
+
+	return team, leftProjects, nil
+}
+
+// AddAdmin makes the member of the team an admin. The requesting user must be an admin of the team.
+func (s *Service) AddAdmin(teamId string, userId string, requestingUserId string) (*Team, error) {
+	err := s.permissionStore.VerifyTeamAdmin(teamId, requestingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.permissionStore.VerifyMembershipTeam(teamId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	team, err := s.store.getTeam(teamId)
+	if err != nil {
+		return nil, err
+	}
+
+	if contains(team.Admins, userId) {
+		return nil, errors.New(fmt.Sprintf("user %s is already an admin of team %s", userId, teamId))
+	}
+
+	team, err = s.store.updateMembers(teamId, append(team.Admins, userId), team.Users)
+	if err != nil {
+		return nil, err
+	}
+	s.Log("Made user %s an admin of team %s", userId, teamId)
+
+	return team, nil
+}
+
+func contains(users []string, user string) bool {
+	for _, u := range users {
+		if u == user {
+			return true
+		}
+	}
+	return false
+}
+
+func remove(users []string, user string) []string {
+	remainingUsers := make([]string, 0)
+	for _, u := range users {
+		if u != user {
+			remainingUsers = append(remainingUsers, u)
+		}
+	}
+	return remainingUsers
+}
@@ -0,0 +1,271 @@
+package team
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/hauke96/sigolo"
+	"github.com/pkg/errors"
+	"stm/comment"
+	"stm/config"
+	"stm/permission"
+	"stm/project"
+	"stm/task"
+	"stm/test"
+	"stm/util"
+	"testing"
+
+	_ "github.com/lib/pq" // Make driver "postgres" usable
+)
+
+var (
+	tx             *sql.Tx
+	s              *Service
+	projectService *project.Service
+	taskService    *task.Service
+	h              *test.Helper
+)
+
+func TestMain(m *testing.M) {
+	h = test.NewTestHelper(setup)
+	m.Run()
+}
+
+func setup() {
+	sigolo.LogLevel = sigolo.LOG_DEBUG
+	config.LoadConfig("../test/test-config.json")
+	h.InitWithDummyData(config.Conf.DbUsername, config.Conf.DbPassword, config.Conf.DbDatabase)
+	tx = h.NewTransaction()
+
+	logger := util.NewLogger()
+
+	permissionStore := permission.Init(tx, logger)
+	commentStore := comment.GetStore(tx, logger)
+	commentService := comment.Init(logger, commentStore)
+	taskService = task.Init(tx, logger, permissionStore, commentService, commentStore)
+	projectService = project.Init(tx, logger, taskService, permissionStore, commentService, commentStore)
+	s = Init(tx, logger, permissionStore, projectService)
+}
+
+func TestGetTeams(t *testing.T) {
+	h.Run(t, func() error {
+		teams, err := s.GetTeams("Otto")
+		if err != nil {
+			return err
+		}
+		if len(teams) != 1 || teams[0].Id != "1" {
+			return errors.New(fmt.Sprintf("Otto should only be member of team 1: %#v", teams))
+		}
+
+		teams, err = s.GetTeams("Peter")
+		if err != nil {
+			return err
+		}
+		if len(teams) != 0 {
+			return errors.New(fmt.Sprintf("Peter should not be member of any team: %#v", teams))
+		}
+
+		return nil
+	})
+}
+
+func TestGetTeam(t *testing.T) {
+	h.Run(t, func() error {
+		team, err := s.GetTeam("1", "Otto")
+		if err != nil {
+			return err
+		}
+		if team.Name != "Team 1" || len(team.Admins) != 1 || team.Admins[0] != "Anna" || len(team.Users) != 3 || len(team.ProjectIds) != 0 {
+			return errors.New(fmt.Sprintf("Team not matching: %#v", team))
+		}
+
+		_, err = s.GetTeam("1", "Peter")
+		if err == nil {
+			return errors.New("Non-member should not be able to get the team")
+		}
+
+		return nil
+	})
+}
+
+func TestAddTeam(t *testing.T) {
+	h.Run(t, func() error {
+		team, err := s.AddTeam(&DraftDto{Name: "New team", Users: []string{"John", "Peter", ""}}, "Peter")
+		if err != nil {
+			return err
+		}
+
+		if team.Id != "2" || team.Name != "New team" || team.CreationDate == nil {
+			return errors.New(fmt.Sprintf("Team not matching: %#v", team))
+		}
+		if len(team.Admins) != 1 || team.Admins[0] != "Peter" {
+			return errors.New(fmt.Sprintf("Requesting user should be the only admin: %v", team.Admins))
+		}
+		if len(team.Users) != 2 || team.Users[0] != "Peter" || team.Users[1] != "John" {
+			return errors.New(fmt.Sprintf("Users not matching: %v", team.Users))
+		}
+
+		_, err = s.AddTeam(&DraftDto{Name: " "}, "Peter")
+		if err == nil {
+			return errors.New("Team without name should not be possible")
+		}
+
+		return nil
+	})
+}
+
+func TestAddUser(t *testing.T) {
+	h.Run(t, func() error {
+		_, err := projectService.AddTeam("2", "1", "Maria")
+		if err != nil {
+			return err
+		}
+
+		team, updatedProjects, err := s.AddUser("1", "Peter", "Anna")
+		if err != nil {
+			return err
+		}
+		if len(team.Users) != 4 || team.Users[3] != "Peter" {
+			return errors.New(fmt.Sprintf("Peter should be a member now: %v", team.Users))
+		}
+		if len(updatedProjects) != 1 || updatedProjects[0].Id != "2" || !contains(updatedProjects[0].Users, "Peter") {
+			return errors.New(fmt.Sprintf("Project 2 with Peter as member should be returned: %#v", updatedProjects))
+		}
+
+		_, _, err = s.AddUser("1", "Peter", "Anna")
+		if err == nil {
+			return errors.New("Adding a member twice should not work")
+		}
+
+		// Only admins are allowed to add users
+		_, _, err = s.AddUser("1", "John", "Otto")
+		if err == nil {
+			return errors.New("Non-admin should not be able to add users")
+		}
+
+		return nil
+	})
+}
+
+func TestRemoveUserUnassignsHim(t *testing.T) {
+	h.Run(t, func() error {
+		_, err := projectService.AddTeam("2", "1", "Maria")
+		if err != nil {
+			return err
+		}
+
+		_, err = taskService.AssignUser("4", "Otto")
+		if err != nil {
+			return err
+		}
+
+		team, leftProjects, err := s.RemoveUser("1", "Otto", "Anna")
+		if err != nil {
+			return err
+		}
+
+		if len(team.Users) != 2 {
+			return errors.New(fmt.Sprintf("Otto should not be a member anymore: %v", team.Users))
+		}
+		if len(leftProjects) != 1 || leftProjects[0].Id != "2" {
+			return errors.New(fmt.Sprintf("Otto should have left project 2: %#v", leftProjects))
+		}
+		for _, task := range leftProjects[0].Tasks {
+			if task.AssignedUser == "Otto" {
+				return errors.New(fmt.Sprintf("Task '%s' still has former member Otto assigned", task.Id))
+			}
+		}
+
+		// Maria leaves the team but stays direct member of project 2
+		_, leftProjects, err = s.RemoveUser("1", "Maria", "Maria")
+		if err != nil {
+			return err
+		}
+		if len(leftProjects) != 0 {
+			return errors.New(fmt.Sprintf("Maria is still a member of project 2: %#v", leftProjects))
+		}
+
+		return nil
+	})
+}
+
+func TestRemoveUserNotAllowed(t *testing.T) {
+	h.Run(t, func() error {
+		// Only admins can remove other users
+		_, _, err := s.RemoveUser("1", "Maria", "Otto")
+		if err == nil {
+			return errors.New("Non-admin should not be able to remove other users")
+		}
+
+		// The last admin can't be removed
+		_, _, err = s.RemoveUser("1", "Anna", "Anna")
+		if err == nil {
+			return errors.New("Removing the last admin should not work")
+		}
+
+		// Not a member
+		_, _, err = s.RemoveUser("1", "Peter", "Anna")
+		if err == nil {
+			return errors.New("Removing a non-member should not work")
+		}
+
+		return nil
+	})
+}
+
+func TestAddAdmin(t *testing.T) {
+	h.Run(t, func() error {
+		team, err := s.AddAdmin("1", "Otto", "Anna")
+		if err != nil {
+			return err
+		}
+		if len(team.Admins) != 2 || team.Admins[1] != "Otto" {
+			return errors.New(fmt.Sprintf("Otto should be an admin now: %v", team.Admins))
+		}
+
+		// Now Anna is not the last admin anymore
+		team, _, err = s.RemoveUser("1", "Anna", "Anna")
+		if err != nil {
+			return err
+		}
+		if len(team.Admins) != 1 || team.Admins[0] != "Otto" {
+			return errors.New(fmt.Sprintf("Otto should be the only admin: %v", team.Admins))
+		}
+
+		// Only members can become admins
+		_, err = s.AddAdmin("1", "Peter", "Otto")
+		if err == nil {
+			return errors.New("Non-member should not be able to become admin")
+		}
+
+		return nil
+	})
+}
+
+func TestDeleteTeam(t *testing.T) {
+	h.Run(t, func() error {
+		_, err := projectService.AddTeam("2", "1", "Maria")
+		if err != nil {
+			return err
+		}
+
+		_, err = s.DeleteTeam("1", "Otto")
+		if err == nil {
+			return errors.New("Non-admin should not be able to delete the team")
+		}
+
+		updatedProjects, err := s.DeleteTeam("1", "Anna")
+		if err != nil {
+			return err
+		}
+		if len(updatedProjects) != 1 || len(updatedProjects[0].Teams) != 0 || len(updatedProjects[0].Users) != 6 {
+			return errors.New(fmt.Sprintf("Project 2 should not contain the team anymore: %#v", updatedProjects))
+		}
+
+		_, err = s.GetTeam("1", "Anna")
+		if err == nil {
+			return errors.New("Team should not exist anymore")
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,166 @@
+package team
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"stm/util"
+	"strconv"
+	"time"
+)
+
+type store struct {
+	*util.Logger
+	tx               *sql.Tx
+	table            string
+	projectTeamTable string
+}
+
+var (
+	returnValues = "id, name, admins, users, creation_date"
+)
+
+func getStore(tx *sql.Tx, logger *util.Logger) *store {
+	return &store{
+		Logger:           logger,
+		tx:               tx,
+		table:            "teams",
+		projectTeamTable: "project_teams",
+	}
+}
+
+func (s *store) getTeamsOfUser(userId string) ([]*Team, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE $1 = ANY(users) ORDER BY id;", returnValues, s.table)
+	return s.execQuery(query, userId)
+}
+
+func (s *store) getTeam(teamId string) (*Team, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id = $1;", returnValues, s.table)
+	return s.execQuerySingle(query, teamId)
+}
+
+func (s *store) addTeam(name string, admins []string, users []string, creationDate time.Time) (*Team, error) {
+	query := fmt.Sprintf("INSERT INTO %s (name, admins, users, creation_date) VALUES($1, $2, $3, $4) RETURNING %s;", s.table, returnValues)
+	return s.execQuerySingle(query, name, pq.Array(admins), pq.Array(users), creationDate)
+}
+
+func (s *store) updateMembers(teamId string, admins []string, users []string) (*Team, error) {
+	query := fmt.Sprintf("UPDATE %s SET admins=$2, users=$3 WHERE id=$1 RETURNING %s;", s.table, returnValues)
+	return s.execQuerySingle(query, teamId, pq.Array(admins), pq.Array(users))
+}
+
+func (s *store) delete(teamId string) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id=$1;", s.table)
+	s.LogQuery(query, teamId)
+
+	_, err := s.tx.Exec(query, teamId)
+	return err
+}
+
+// execQuerySingle executes the given query and returns the only resulting team.
+func (s *store) execQuerySingle(query string, params ...interface{}) (*Team, error) {
+	teams, err := s.execQuery(query, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(teams) != 1 {
+		return nil, errors.New("Team does not exist")
+	}
+
+	return teams[0], nil
+}
+
+// execQuery executes the given query, turns the result into Team objects including their project IDs and closes the
+// query.
+func (s *store) execQuery(query string, params ...interface{}) ([]*Team, error) {
+	s.LogQuery(query, params...)
+
+	rows, err := s.tx.Query(query, params...)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not run query")
+	}
+
+	teams := make([]*Team, 0)
+	for rows.Next() {
+		team, err := rowToTeam(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, "error converting row into team")
+		}
+
+		teams = append(teams, team)
+	}
+
+	err = rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.addProjectsToTeams(teams)
+	if err != nil {
+		return nil, err
+	}
+
+	return teams, nil
+}
+
+// addProjectsToTeams adds the IDs of the projects to the teams. This needs one query regardless of the number of teams.
+func (s *store) addProjectsToTeams(teams []*Team) error {
+	if len(teams) == 0 {
+		return nil
+	}
+
+	teamIds := make([]string, len(teams))
+	teamsById := make(map[string]*Team)
+	for i, team := range teams {
+		teamIds[i] = team.Id
+		teamsById[team.Id] = team
+	}
+
+	query := fmt.Sprintf("SELECT team_id, project_id FROM %s WHERE team_id = ANY($1) ORDER BY project_id;", s.projectTeamTable)
+	s.LogQuery(query, teamIds)
+
+	rows, err := s.tx.Query(query, pq.Array(teamIds))
+	if err != nil {
+		return errors.Wrap(err, "error executing query")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var teamId, projectId int
+
+		err = rows.Scan(&teamId, &projectId)
+		if err != nil {
+			return errors.Wrap(err, "could not scan rows")
+		}
+
+		team := teamsById[strconv.Itoa(teamId)]
+		team.ProjectIds = append(team.ProjectIds, strconv.Itoa(projectId))
+	}
+
+	return nil
+}
+
+// rowToTeam turns the current row into a Team object without projects. This does not close the row.
+func rowToTeam(rows *sql.Rows) (*Team, error) {
+	var id int
+	var creationDate *time.Time
+	result := Team{
+		ProjectIds: make([]string, 0),
+	}
+
+	err := rows.Scan(&id, &result.Name, pq.Array(&result.Admins), pq.Array(&result.Users), &creationDate)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not scan rows")
+	}
+
+	result.Id = strconv.Itoa(id)
+
+	if creationDate != nil {
+		t := creationDate.UTC()
+		result.CreationDate = &t
+	}
+
+	return &result, nil
+}
@@ -1,8 +1,16 @@
 -- 
 -- Reset database
 -- 
+DELETE FROM project_invitations;
+DELETE FROM project_teams;
+DELETE FROM teams;
+DELETE FROM process_point_changes;
 DELETE FROM projects;
+DELETE FROM campaigns;
 DELETE FROM tasks;
+DELETE FROM comment_reports;
+DELETE FROM comment_attachments;
+DELETE FROM comment_edits;
 DELETE FROM comments;
 DELETE FROM comment_lists;
 DELETE FROM db_versions WHERE version='test';
@@ -37,6 +45,26 @@ INSERT INTO tasks(id, project_id, process_points, max_process_points, geometry,
 INSERT INTO tasks(id, project_id, process_points, max_process_points, geometry, assigned_user, comment_list_id) VALUES (4, 2, 0, 100, '{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[9.951631591968885,53.563785517845105],[9.935667083912245,53.55022340710764],[10.00639157121693,53.53675896834966],[10.013773010425917,53.570921724776724],[9.951631591968885,53.563785517845105]]]},"properties":null}', '', 6);
 INSERT INTO tasks(id, project_id, process_points, max_process_points, geometry, assigned_user, comment_list_id) VALUES (6, 2, 1, 4, '{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[9.951631591968885,53.563785517845105],[9.935667083912245,53.55022340710764],[10.00639157121693,53.53675896834966],[10.013773010425917,53.570921724776724],[9.951631591968885,53.563785517845105]]]},"properties":null}', '', 7);
 INSERT INTO tasks(id, project_id, process_points, max_process_points, geometry, assigned_user, comment_list_id) VALUES (7, 2, 3, 4, '{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[9.951631591968885,53.563785517845105],[9.935667083912245,53.55022340710764],[10.00639157121693,53.53675896834966],[10.013773010425917,53.570921724776724],[9.951631591968885,53.563785517845105]]]},"properties":null}', 'Donny', 8);
+--
+-- Team 1 (not added to any project)
+--
+INSERT INTO teams(id, name, admins, users, creation_date) VALUES (1, 'Team 1', '{Anna}', '{Anna,Otto,Maria}', '2021-02-11 10:00:00.000000');
+
+--
+-- Campaign 1 (contains project 2)
+--
+INSERT INTO campaigns(id, name, description, owner, users, creation_date) VALUES (1, 'Campaign 1', 'Some activation', 'Maria', '{Maria,John}', '2021-02-10 10:00:00.000000');
+UPDATE projects SET campaign_id = 1 WHERE id = 2;
+
+INSERT INTO comments(id, comment_list_id, text, author_id, creation_date) VALUES (3, 5, 'Almost done here', 'Maria', '2021-02-17 09:30:00.000000');
+INSERT INTO process_point_changes(id, task_id, user_id, old_points, new_points, creation_date) VALUES (1, 2, 'John', 0, 60, '2021-02-14 08:00:00.000000');
+INSERT INTO process_point_changes(id, task_id, user_id, old_points, new_points, creation_date) VALUES (2, 2, 'John', 60, 100, '2021-02-14 10:00:00.000000');
+INSERT INTO process_point_changes(id, task_id, user_id, old_points, new_points, creation_date) VALUES (3, 3, 'Maria', 0, 50, '2021-02-15 12:00:00.000000');
+INSERT INTO process_point_changes(id, task_id, user_id, old_points, new_points, creation_date) VALUES (4, 6, 'Clara', 0, 1, '2021-02-16 10:00:00.000000');
+INSERT INTO process_point_changes(id, task_id, user_id, old_points, new_points, creation_date) VALUES (5, 7, 'Donny', 0, 3, '2021-02-16 18:00:00.000000');
+INSERT INTO project_invitations(id, project_id, code, creation_date, expiration_date, max_uses, uses) VALUES (1, 2, 'valid-code', '2021-02-13 05:16:55.150015', NULL, 0, 3);
+INSERT INTO project_invitations(id, project_id, code, creation_date, expiration_date, max_uses, uses) VALUES (2, 2, 'expired-code', '2021-02-13 05:16:55.150015', '2021-02-14 05:16:55.150015', 0, 0);
+INSERT INTO project_invitations(id, project_id, code, creation_date, expiration_date, max_uses, uses) VALUES (3, 2, 'used-up-code', '2021-02-13 05:16:55.150015', NULL, 2, 2);
 
 --
 -- Project 3
@@ -54,4 +82,8 @@ INSERT INTO tasks(id, project_id, process_points, max_process_points, geometry,
 ALTER SEQUENCE projects_id_seq RESTART WITH 4;
 ALTER SEQUENCE tasks_id_seq RESTART WITH 9;
 ALTER SEQUENCE comment_lists_id_seq RESTART WITH 12;
-ALTER SEQUENCE comments_id_seq RESTART WITH 3;
+ALTER SEQUENCE comments_id_seq RESTART WITH 4;
+ALTER SEQUENCE project_invitations_id_seq RESTART WITH 4;
+ALTER SEQUENCE process_point_changes_id_seq RESTART WITH 6;
+ALTER SEQUENCE campaigns_id_seq RESTART WITH 2;
+ALTER SEQUENCE teams_id_seq RESTART WITH 2;
@@ -56,7 +56,8 @@ func (h *Helper) NewTransaction() *sql.Tx {
 	return h.Tx
 }
 
-func (h *Helper) Run(t *testing.T, testFunc func() error) {
+// Run executes the given test function on fresh dummy data. This also works for benchmarks.
+func (h *Helper) Run(t testing.TB, testFunc func() error) {
 	if h.Setup != nil {
 		h.Setup()
 	}
@@ -0,0 +1,141 @@
+package util
+
+import (
+	geojson "github.com/paulmach/go.geojson"
+	"math"
+)
+
+const (
+	earthRadius = 6378137.0 // Equatorial radius of the WGS84 ellipsoid in meters
+)
+
+// GetArea returns the area of the given polygon or multi-polygon in square meters. Holes are subtracted and all other
+// geometry types have no area. The area is calculated on a sphere, which is precise enough for statistics.
+func GetArea(geometry *geojson.Geometry) float64 {
+	if geometry == nil {
+		return 0
+	}
+
+	switch geometry.Type {
+	case geojson.GeometryPolygon:
+		return getPolygonArea(geometry.Polygon)
+	case geojson.GeometryMultiPolygon:
+		area := 0.0
+		for _, polygon := range geometry.MultiPolygon {
+			area += getPolygonArea(polygon)
+		}
+		return area
+	}
+
+	return 0
+}
+
+// getPolygonArea returns the area of the outer ring (the first one) minus the area of all inner rings.
+func getPolygonArea(rings [][][]float64) float64 {
+	area := 0.0
+	for i, ring := range rings {
+		if i == 0 {
+			area += getRingArea(ring)
+		} else {
+			area -= getRingArea(ring)
+		}
+	}
+	return math.Max(area, 0)
+}
+
+// getRingArea calculates the area of a closed ring of [lon, lat] coordinates on a sphere. See "Some Algorithms for
+// Polygons on a Sphere" by Chamberlain and Duquette (JPL Publication 07-03).
+func getRingArea(ring [][]float64) float64 {
+	if len(ring) < 3 {
+		return 0
+	}
+
+	sum := 0.0
+	for i := range ring {
+		p1 := ring[i]
+		p2 := ring[(i+1)%len(ring)]
+		if len(p1) < 2 || len(p2) < 2 {
+			return 0
+		}
+
+		sum += toRadians(p2[0]-p1[0]) * (2 + math.Sin(toRadians(p1[1])) + math.Sin(toRadians(p2[1])))
+	}
+
+	return math.Abs(sum * earthRadius * earthRadius / 2)
+}
+
+func toRadians(degree float64) float64 {
+	return degree * math.Pi / 180
+}
+
+// BoundingBox is the smallest rectangle containing a geometry in WGS84 coordinates.
+type BoundingBox struct {
+	MinLon float64
+	MinLat float64
+	MaxLon float64
+	MaxLat float64
+}
+
+// GetOuterRing returns the outer ring (the first one) of the given polygon or of the first polygon of the given
+// multi-polygon. All other geometry types have no outer ring and return nil.
+func GetOuterRing(geometry *geojson.Geometry) [][]float64 {
+	if geometry == nil {
+		return nil
+	}
+
+	switch geometry.Type {
+	case geojson.GeometryPolygon:
+		if len(geometry.Polygon) > 0 {
+			return geometry.Polygon[0]
+		}
+	case geojson.GeometryMultiPolygon:
+		if len(geometry.MultiPolygon) > 0 && len(geometry.MultiPolygon[0]) > 0 {
+			return geometry.MultiPolygon[0][0]
+		}
+	}
+
+	return nil
+}
+
+// GetBoundingBox returns the bounding box of all outer rings of the given polygon or multi-polygon. It returns nil for
+// all other geometry types and for empty geometries.
+func GetBoundingBox(geometry *geojson.Geometry) *BoundingBox {
+	if geometry == nil {
+		return nil
+	}
+
+	var polygons [][][][]float64
+	switch geometry.Type {
+	case geojson.GeometryPolygon:
+		polygons = [][][][]float64{geometry.Polygon}
+	case geojson.GeometryMultiPolygon:
+		polygons = geometry.MultiPolygon
+	default:
+		return nil
+	}
+
+	var boundingBox *BoundingBox
+	for _, polygon := range polygons {
+		if len(polygon) == 0 {
+			continue
+		}
+
+		for _, coordinate := range polygon[0] {
+			if len(coordinate) < 2 {
+				continue
+			}
+
+			if boundingBox == nil {
+				boundingBox = &BoundingBox{MinLon: coordinate[0], MinLat: coordinate[1], MaxLon: coordinate[0], MaxLat: coordinate[1]}
+				continue
+			}
+
+			boundingBox.MinLon = math.Min(boundingBox.MinLon, coordinate[0])
+			boundingBox.MinLat = math.Min(boundingBox.MinLat, coordinate[1])
+			boundingBox.MaxLon = math.Max(boundingBox.MaxLon, coordinate[0])
+			boundingBox.MaxLat = math.Max(boundingBox.MaxLat, coordinate[1])
+		}
+	}
+
+	return boundingBox
+}
@@ -0,0 +1,187 @@
+package util
+
+import (
+	"html"
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	allowedUrlSchemes = []string{"http", "https", "mailto"}
+)
+
+// RenderMarkdown turns the given text of the supported Markdown dialect into HTML. The dialect consists of paragraphs
+// (separated by empty lines), line breaks, unordered lists ("- " or "* " at the beginning of a line), **bold**,
+// *italic*, `code`, [links](https://...) and plain http(s) URLs.
+//
+// The result is safe to embed into a website: All text is escaped, only the tags of the dialect are generated and
+// links are only created for http, https and mailto URLs. Links with any other scheme (e.g. "javascript:") are
+// replaced by their label.
+func RenderMarkdown(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	result := &strings.Builder{}
+	var paragraph []string
+	var listItems []string
+
+	flush := func() {
+		if len(paragraph) > 0 {
+			result.WriteString("<p>")
+			for i, line := range paragraph {
+				if i > 0 {
+					result.WriteString("<br>")
+				}
+				result.WriteString(renderInlineMarkdown(line))
+			}
+			result.WriteString("</p>")
+			paragraph = nil
+		}
+
+		if len(listItems) > 0 {
+			result.WriteString("<ul>")
+			for _, item := range listItems {
+				result.WriteString("<li>" + renderInlineMarkdown(item) + "</li>")
+			}
+			result.WriteString("</ul>")
+			listItems = nil
+		}
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmedLine := strings.TrimSpace(line)
+
+		if trimmedLine == "" {
+			flush()
+		} else if strings.HasPrefix(trimmedLine, "- ") || strings.HasPrefix(trimmedLine, "* ") {
+			if len(paragraph) > 0 {
+				flush()
+			}
+			listItems = append(listItems, strings.TrimSpace(trimmedLine[2:]))
+		} else {
+			if len(listItems) > 0 {
+				flush()
+			}
+			paragraph = append(paragraph, trimmedLine)
+		}
+	}
+	flush()
+
+	return result.String()
+}
+
+// renderInlineMarkdown renders the inline elements of one line. Everything that isn't part of an element is escaped.
+func renderInlineMarkdown(text string) string {
+	result := &strings.Builder{}
+
+	for i := 0; i < len(text); {
+		rest := text[i:]
+
+		if strings.HasPrefix(rest, "`") {
+			end := strings.Index(rest[1:], "`")
+			if end > 0 {
+				result.WriteString("<code>" + html.EscapeString(rest[1:end+1]) + "</code>")
+				i += end + 2
+				continue
+			}
+		}
+
+		if strings.HasPrefix(rest, "**") {
+			end := strings.Index(rest[2:], "**")
+			if end > 0 && isEmphasisContent(rest[2:end+2]) {
+				result.WriteString("<strong>" + renderInlineMarkdown(rest[2:end+2]) + "</strong>")
+				i += end + 4
+				continue
+			}
+		}
+
+		if strings.HasPrefix(rest, "*") && !strings.HasPrefix(rest, "**") {
+			end := strings.Index(rest[1:], "*")
+			if end > 0 && isEmphasisContent(rest[1:end+1]) {
+				result.WriteString("<em>" + renderInlineMarkdown(rest[1:end+1]) + "</em>")
+				i += end + 2
+				continue
+			}
+		}
+
+		if strings.HasPrefix(rest, "[") {
+			labelEnd := strings.Index(rest, "](")
+			urlEnd := -1
+			if labelEnd > 0 {
+				urlEnd = strings.Index(rest[labelEnd:], ")")
+			}
+			if urlEnd > 0 {
+				urlEnd += labelEnd
+				label := rest[1:labelEnd]
+				link := strings.TrimSpace(rest[labelEnd+2 : urlEnd])
+				if isSafeUrl(link) {
+					result.WriteString(renderLink(link, html.EscapeString(label)))
+				} else {
+					result.WriteString(html.EscapeString(label))
+				}
+				i += urlEnd + 1
+				continue
+			}
+		}
+
+		if (strings.HasPrefix(rest, "http://") || strings.HasPrefix(rest, "https://")) && isWordStart(text[:i]) {
+			end := strings.IndexFunc(rest, unicode.IsSpace)
+			if end == -1 {
+				end = len(rest)
+			}
+			// Punctuation at the end most likely belongs to the sentence and not to the URL
+			link := strings.TrimRightFunc(rest[:end], func(r rune) bool {
+				return strings.ContainsRune(".,;:!?)'\"", r)
+			})
+			if isSafeUrl(link) {
+				result.WriteString(renderLink(link, html.EscapeString(link)))
+				i += len(link)
+				continue
+			}
+		}
+
+		r, size := utf8.DecodeRuneInString(rest)
+		result.WriteString(html.EscapeString(string(r)))
+		i += size
+	}
+
+	return result.String()
+}
+
+func renderLink(link string, escapedLabel string) string {
+	return `<a href="` + html.EscapeString(link) + `" rel="nofollow noopener noreferrer" target="_blank">` + escapedLabel + `</a>`
+}
+
+// isSafeUrl returns true for absolute URLs with one of the allowed schemes.
+func isSafeUrl(link string) bool {
+	parsedUrl, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+
+	scheme := strings.ToLower(parsedUrl.Scheme)
+	for _, allowedScheme := range allowedUrlSchemes {
+		if scheme == allowedScheme {
+			return parsedUrl.Host != "" || scheme == "mailto"
+		}
+	}
+
+	return false
+}
+
+// isEmphasisContent returns false when the content starts or ends with a whitespace, so that "2 * 3 * 4" stays as it is.
+func isEmphasisContent(content string) bool {
+	first, _ := utf8.DecodeRuneInString(content)
+	last, _ := utf8.DecodeLastRuneInString(content)
+	return !unicode.IsSpace(first) && !unicode.IsSpace(last)
+}
+
+func isWordStart(textBefore string) bool {
+	if textBefore == "" {
+		return true
+	}
+
+	r, _ := utf8.DecodeLastRuneInString(textBefore)
+	return unicode.IsSpace(r) || r == '('
+}
@@ -30,6 +30,15 @@ func GetIntParam(param string, r *http.Request) (int, error) {
 	return strconv.Atoi(valueString)
 }
 
+// GetIntParamOrDefault returns the given fallback value when the parameter is not set. Non-integer values are an error.
+func GetIntParamOrDefault(param string, r *http.Request, fallback int) (int, error) {
+	if strings.TrimSpace(r.FormValue(param)) == "" {
+		return fallback, nil
+	}
+
+	return GetIntParam(param, r)
+}
+
 func ResponseBadRequest(w http.ResponseWriter, logger *Logger, err error) {
 	ErrorResponse(w, logger, err, http.StatusBadRequest)
 }
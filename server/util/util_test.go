@@ -2,6 +2,8 @@ package util
 
 import (
 	"errors"
+	geojson "github.com/paulmach/go.geojson"
+	"math"
 	"net/http"
 	"testing"
 )
@@ -107,6 +109,53 @@ func TestGetIntParam(t *testing.T) {
 	}
 }
 
+func TestGetIntParamOrDefault(t *testing.T) {
+	params := make(map[string][]string)
+	params["foo"] = []string{"123"}
+	params["bar"] = []string{"abc"}
+
+	r := &http.Request{
+		Form: params,
+	}
+
+	// Existing param
+
+	param, err := GetIntParamOrDefault("foo", r, 42)
+	if err != nil {
+		t.Errorf("Getting params should work: %s", err.Error())
+		t.Fail()
+		return
+	}
+	if param != 123 {
+		t.Errorf("Param should have value '123'")
+		t.Fail()
+		return
+	}
+
+	// Not existing param
+
+	param, err = GetIntParamOrDefault("utini", r, 42)
+	if err != nil {
+		t.Errorf("Getting not existing params should work: %s", err.Error())
+		t.Fail()
+		return
+	}
+	if param != 42 {
+		t.Errorf("Param for key 'utini' should be the fallback value '42'")
+		t.Fail()
+		return
+	}
+
+	// Non-integer param
+
+	_, err = GetIntParamOrDefault("bar", r, 42)
+	if err == nil {
+		t.Error("Getting non-integer params should not work")
+		t.Fail()
+		return
+	}
+}
+
 func TestResponseErrors(t *testing.T) {
 	logger := NewLogger()
 
@@ -132,3 +181,85 @@ func TestResponseErrors(t *testing.T) {
 		t.Errorf("response not matching: %#v", w)
 	}
 }
+
+func TestGetArea(t *testing.T) {
+	// Roughly 111km x 111km at the equator
+	square := geojson.NewPolygonGeometry([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}}})
+	area := GetArea(square)
+	if area < 12.3e9 || area > 12.4e9 {
+		t.Errorf("Area of 1°x1° square at equator should be around 12390 km² but was %f m²", area)
+	}
+
+	// Hole of a quarter of the size
+	squareWithHole := geojson.NewPolygonGeometry([][][]float64{
+		{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}},
+		{{0, 0}, {0, 0.5}, {0.5, 0.5}, {0.5, 0}, {0, 0}},
+	})
+	areaWithHole := GetArea(squareWithHole)
+	if math.Abs(areaWithHole-area*0.75) > area*0.01 {
+		t.Errorf("Area with hole should be around %f but was %f", area*0.75, areaWithHole)
+	}
+
+	// Multi-polygon is the sum of its polygons
+	multiPolygon := geojson.NewMultiPolygonGeometry(square.Polygon, square.Polygon)
+	if math.Abs(GetArea(multiPolygon)-2*area) > 1 {
+		t.Errorf("Area of multi-polygon should be %f but was %f", 2*area, GetArea(multiPolygon))
+	}
+
+	// No area
+	if GetArea(geojson.NewPointGeometry([]float64{1, 2})) != 0 || GetArea(nil) != 0 {
+		t.Errorf("Points and nil should have no area")
+	}
+}
+
+func TestGetBoundingBox(t *testing.T) {
+	polygon := geojson.NewPolygonGeometry([][][]float64{{{9.9, 53.5}, {10.1, 53.5}, {10, 53.6}, {9.9, 53.5}}})
+	boundingBox := GetBoundingBox(polygon)
+	if boundingBox == nil || *boundingBox != (BoundingBox{MinLon: 9.9, MinLat: 53.5, MaxLon: 10.1, MaxLat: 53.6}) {
+		t.Errorf("Bounding box of polygon not matching: %#v", boundingBox)
+	}
+
+	// All polygons of a multi-polygon are considered
+	multiPolygon := geojson.NewMultiPolygonGeometry(polygon.Polygon, [][][]float64{{{-1, -2}, {0, -2}, {0, 0}, {-1, -2}}})
+	boundingBox = GetBoundingBox(multiPolygon)
+	if boundingBox == nil || *boundingBox != (BoundingBox{MinLon: -1, MinLat: -2, MaxLon: 10.1, MaxLat: 53.6}) {
+		t.Errorf("Bounding box of multi-polygon not matching: %#v", boundingBox)
+	}
+
+	// Only the first outer ring is the outer ring of a multi-polygon
+	if len(GetOuterRing(multiPolygon)) != 4 || GetOuterRing(multiPolygon)[1][0] != 10.1 {
+		t.Errorf("Outer ring of multi-polygon not matching: %v", GetOuterRing(multiPolygon))
+	}
+
+	if GetBoundingBox(geojson.NewPointGeometry([]float64{1, 2})) != nil || GetBoundingBox(nil) != nil || GetOuterRing(nil) != nil {
+		t.Errorf("Points and nil should have no bounding box and outer ring")
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	cases := []struct {
+		markdown string
+		expected string
+	}{
+		{"", ""},
+		{"Simple text", "<p>Simple text</p>"},
+		{"First line\nsecond line\n\nNew paragraph", "<p>First line<br>second line</p><p>New paragraph</p>"},
+		{"Some **bold**, *italic* and `code **with** <tags>`", "<p>Some <strong>bold</strong>, <em>italic</em> and <code>code **with** &lt;tags&gt;</code></p>"},
+		{"2 * 3 * 4", "<p>2 * 3 * 4</p>"},
+		{"Roads:\n- Main street\n* **Side** street", "<p>Roads:</p><ul><li>Main street</li><li><strong>Side</strong> street</li></ul>"},
+		{"See [the wiki](https://wiki.openstreetmap.org/wiki/Key:highway).", `<p>See <a href="https://wiki.openstreetmap.org/wiki/Key:highway" rel="nofollow noopener noreferrer" target="_blank">the wiki</a>.</p>`},
+		{"Visit https://osm.org/?a=1&b=2.", `<p>Visit <a href="https://osm.org/?a=1&amp;b=2" rel="nofollow noopener noreferrer" target="_blank">https://osm.org/?a=1&amp;b=2</a>.</p>`},
+		{"<script>alert('x')</script>", "<p>&lt;script&gt;alert(&#39;x&#39;)&lt;/script&gt;</p>"},
+		{"[click](javascript:alert(1))", "<p>click)</p>"},
+		{"[click](data:text/html;base64,PHNjcmlwdD4=)", "<p>click</p>"},
+		{`[x](https://example.com/" onclick="alert(1))`, `<p><a href="https://example.com/&#34; onclick=&#34;alert(1" rel="nofollow noopener noreferrer" target="_blank">x</a>)</p>`},
+		{"[<b>label</b>](https://example.com)", `<p><a href="https://example.com" rel="nofollow noopener noreferrer" target="_blank">&lt;b&gt;label&lt;/b&gt;</a></p>`},
+	}
+
+	for i, c := range cases {
+		rendered := RenderMarkdown(c.markdown)
+		if rendered != c.expected {
+			t.Errorf("Case %d: expected\n%s\nbut got\n%s", i, c.expected, rendered)
+		}
+	}
+}
@@ -7,10 +7,12 @@ import (
 )
 
 const (
-	MessageType_ProjectAdded       = "project_added"
-	MessageType_ProjectUpdated     = "project_updated"
-	MessageType_ProjectDeleted     = "project_deleted"
-	MessageType_ProjectUserRemoved = "project_user_removed"
+	MessageType_ProjectAdded         = "project_added"
+	MessageType_ProjectUpdated       = "project_updated"
+	MessageType_ProjectDeleted       = "project_deleted"
+	MessageType_ProjectUserRemoved   = "project_user_removed"
+	MessageType_ProjectDueDatePassed = "project_due_date_passed"
+	MessageType_CommentMention       = "comment_mention"
 )
 
 type Message struct {